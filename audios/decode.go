@@ -5,13 +5,12 @@ import (
 	"io"
 	"io/fs"
 	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/flac"
 	"github.com/gopxl/beep/mp3"
-	"github.com/gopxl/beep/speaker"
 	"github.com/gopxl/beep/vorbis"
-	"github.com/gopxl/beep/wav"
 )
 
 const (
@@ -19,21 +18,38 @@ const (
 	quality           int             = 4
 )
 
-func init() {
-	speaker.Init(defaultSampleRate, defaultSampleRate.N(time.Second/20))
+// Extensions is the list of supported audio file extensions.
+var Extensions = []string{".mp3", ".wav", ".ogg", ".flac", ".opus"}
+
+// IsAudioFile reports whether the file has a supported extension.
+func IsAudioFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
 }
 
 // streamer as StreamerSeekCloser will close it.
+// Extension is case-insensitive.
 func Decode(rc io.ReadCloser, ext string) (beep.StreamSeekCloser, beep.Format, error) {
-	switch ext {
+	switch strings.ToLower(ext) {
 	case ".mp3":
 		return mp3.Decode(rc)
 	case ".wav":
-		return wav.Decode(rc)
+		return decodeWAV(rc)
 	case ".ogg":
 		return vorbis.Decode(rc)
+	case ".flac":
+		return flac.Decode(rc)
+	case ".opus":
+		return decodeOpus(rc)
 	}
-	err := fmt.Errorf("decode %s: %s", ext, "unsupported extension")
+	rc.Close()
+	err := fmt.Errorf("decode %s: unsupported format (supported: %s)",
+		ext, strings.Join(Extensions, ", "))
 	return nil, beep.Format{}, err
 }
 
@@ -46,15 +62,6 @@ func DecodeFromFile(fsys fs.FS, name string) (beep.StreamCloser, beep.Format, er
 	return Decode(f, filepath.Ext(name))
 }
 
-// func isAudioFile(name string) bool {
-// 	ext := filepath.Ext(name)
-// 	switch strings.ToLower(ext) {
-// 	case ".mp3", ".wav", ".ogg":
-// 		return true
-// 	}
-// 	return false
-// }
-
 // // FormatFromFS returns the format of the first audio file in the file system.
 // // It is possible that there is no audio file in file system.
 // func FormatFromFS(fsys fs.FS) (Format, error) {
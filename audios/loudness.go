@@ -0,0 +1,142 @@
+package audios
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// Loudness of music is normalized to TargetLoudness by gain.
+// Gain is limited, since boosting quiet music too much may clip.
+const (
+	TargetLoudness = -12.0 // in LUFS
+	maxGainCut     = -12.0 // in dB
+	maxGainBoost   = 6.0   // in dB
+)
+
+// biquad is a second-order IIR filter.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     [2]float64 // States of each channel.
+}
+
+func (f *biquad) process(c int, x float64) float64 {
+	y := f.b0*x + f.b1*f.x1[c] + f.b2*f.x2[c] - f.a1*f.y1[c] - f.a2*f.y2[c]
+	f.x2[c], f.x1[c] = f.x1[c], x
+	f.y2[c], f.y1[c] = f.y1[c], y
+	return y
+}
+
+// K-weighting consists of a high shelf which models the head,
+// and a high pass which models the ear (RLB weighting).
+// Coefficients are derived for any sample rate, as libebur128 does.
+func newKWeighting(sr beep.SampleRate) [2]biquad {
+	fs := float64(sr)
+
+	// Stage 1: high shelf
+	f0, g, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// Stage 2: high pass
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return [2]biquad{shelf, highPass}
+}
+
+// Loudness returns integrated loudness of the streamer in LUFS,
+// in the way of EBU R128 (ITU-R BS.1770): K-weighted mean square
+// over 400ms blocks with 75% overlap, gated absolutely at -70 LUFS
+// and relatively at -10 LU. It returns -Inf for silence.
+func Loudness(s beep.Streamer, sr beep.SampleRate) float64 {
+	const (
+		blockDuration = 400 * time.Millisecond
+		stepDuration  = 100 * time.Millisecond
+		absoluteGate  = -70.0
+		relativeGate  = -10.0
+	)
+	filters := newKWeighting(sr)
+	stepN := sr.N(stepDuration)
+	stepsPerBlock := int(blockDuration / stepDuration)
+
+	// Mean squares of each step. A block is made of consecutive steps.
+	var steps []float64
+	var sum float64
+	var count int
+	buf := make([][2]float64, 1024)
+	for {
+		n, ok := s.Stream(buf)
+		for _, sample := range buf[:n] {
+			for c, x := range sample {
+				y := filters[1].process(c, filters[0].process(c, x))
+				sum += y * y
+			}
+			count++
+			if count == stepN {
+				steps = append(steps, sum/float64(stepN))
+				sum, count = 0, 0
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+
+	var blocks []float64
+	for i := 0; i+stepsPerBlock <= len(steps); i++ {
+		var z float64
+		for _, v := range steps[i : i+stepsPerBlock] {
+			z += v
+		}
+		blocks = append(blocks, z/float64(stepsPerBlock))
+	}
+
+	gated := func(threshold float64) (float64, int) {
+		var sum float64
+		var count int
+		for _, z := range blocks {
+			if blockLoudness(z) > threshold {
+				sum += z
+				count++
+			}
+		}
+		return sum, count
+	}
+	sum, n := gated(absoluteGate)
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	sum, n = gated(blockLoudness(sum/float64(n)) + relativeGate)
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(sum / float64(n))
+}
+
+func blockLoudness(z float64) float64 { return -0.691 + 10*math.Log10(z) }
+
+// LoudnessGain returns gain in dB which brings loudness to TargetLoudness.
+func LoudnessGain(lufs float64) float64 {
+	if math.IsInf(lufs, 0) || math.IsNaN(lufs) {
+		return 0
+	}
+	return max(maxGainCut, min(maxGainBoost, TargetLoudness-lufs))
+}
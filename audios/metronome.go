@@ -0,0 +1,77 @@
+package audios
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/effects"
+)
+
+// Metronome plays a click track: a short sine burst at each beat.
+// Clicks are generated within the stream, so that the interval
+// of clicks is exact regardless of the game's update rate.
+type Metronome struct {
+	ctrl   *beep.Ctrl
+	volume *effects.Volume
+	out    Output
+}
+
+const (
+	clickDuration  = 30 * time.Millisecond
+	clickFrequency = 1000 // Hz
+	accentScale    = 1.5  // The first beat of each measure is pitched higher.
+)
+
+// NewMetronome returns Metronome which clicks count times.
+// The first click comes after lead time.
+func NewMetronome(lead, interval time.Duration, count, meter int) *Metronome {
+	sr := defaultSampleRate
+	leadN := sr.N(lead)
+	intervalN := sr.N(interval)
+	clickN := sr.N(clickDuration)
+	total := leadN + intervalN*count
+
+	var i int
+	streamer := beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for n = range samples {
+			if i >= total {
+				return n, n > 0
+			}
+			var v float64
+			if j := i - leadN; j >= 0 && j%intervalN < clickN {
+				freq := float64(clickFrequency)
+				if meter > 0 && (j/intervalN)%meter == 0 {
+					freq *= accentScale
+				}
+				k := j % intervalN
+				fade := 1 - float64(k)/float64(clickN) // Linear fade-out.
+				v = 0.5 * fade * math.Sin(2*math.Pi*freq*float64(k)/float64(sr))
+			}
+			samples[n] = [2]float64{v, v}
+			i++
+		}
+		return len(samples), true
+	})
+
+	ctrl := &beep.Ctrl{Streamer: streamer}
+	return &Metronome{
+		ctrl:   ctrl,
+		volume: &effects.Volume{Streamer: ctrl, Base: 2},
+		out:    output,
+	}
+}
+
+func (m Metronome) Play() { m.out.Play(m.volume) }
+
+func (m *Metronome) SetVolume(vol float64) {
+	m.volume.Volume = beepVolume(vol)
+	m.volume.Silent = vol <= 0.001
+}
+
+// Lock is required when modifying beep.Ctrl.
+func (m *Metronome) Close() {
+	m.out.Lock()
+	m.ctrl.Streamer = nil
+	m.out.Unlock()
+}
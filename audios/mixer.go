@@ -0,0 +1,131 @@
+package audios
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// Mixer is an Output which mixes streamers into a WAV file.
+// Unlike speaker, time goes on only by Advance. Hence streamers are
+// played and stopped at exact positions, as if they were on speaker.
+type Mixer struct {
+	mixer beep.Mixer
+	w     *wavWriter
+	pos   int
+	buf   [][2]float64
+}
+
+func NewMixer(w io.WriteSeeker) (*Mixer, error) {
+	ww, err := newWAVWriter(w, defaultSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	return &Mixer{w: ww, buf: make([][2]float64, 512)}, nil
+}
+
+// Play starts streamers at the current position.
+func (m *Mixer) Play(s ...beep.Streamer) { m.mixer.Add(s...) }
+
+// Lock does nothing, since Mixer streams only in Advance.
+func (m *Mixer) Lock()   {}
+func (m *Mixer) Unlock() {}
+func (m *Mixer) Clear()  { m.mixer.Clear() }
+
+func (m *Mixer) Position() time.Duration { return defaultSampleRate.D(m.pos) }
+
+// Advance mixes streamers until the given time.
+func (m *Mixer) Advance(d time.Duration) error {
+	return m.mix(defaultSampleRate.N(d) - m.pos)
+}
+
+func (m *Mixer) mix(n int) error {
+	for n > 0 {
+		buf := m.buf[:min(n, len(m.buf))]
+		m.mixer.Stream(buf) // Mixer streams silence when empty.
+		if err := m.w.write(buf); err != nil {
+			return err
+		}
+		m.pos += len(buf)
+		n -= len(buf)
+	}
+	return nil
+}
+
+// Close mixes the rest of streamers, then completes the file.
+func (m *Mixer) Close() error {
+	for m.mixer.Len() > 0 {
+		if err := m.mix(len(m.buf)); err != nil {
+			return err
+		}
+	}
+	return m.w.close()
+}
+
+// wavWriter writes 16-bit stereo PCM. Sizes in the header are
+// filled when closed, since the length is unknown in advance.
+type wavWriter struct {
+	w    io.WriteSeeker
+	size int // Size of data in bytes.
+	data []byte
+}
+
+func newWAVWriter(w io.WriteSeeker, sr beep.SampleRate) (*wavWriter, error) {
+	const (
+		channels   = 2
+		sampleSize = 2
+	)
+	h := make([]byte, 0, 44)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, 0)
+	h = append(h, "WAVE"...)
+	h = append(h, "fmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, wavFormatPCM)
+	h = binary.LittleEndian.AppendUint16(h, channels)
+	h = binary.LittleEndian.AppendUint32(h, uint32(sr))
+	h = binary.LittleEndian.AppendUint32(h, uint32(int(sr)*channels*sampleSize))
+	h = binary.LittleEndian.AppendUint16(h, channels*sampleSize)
+	h = binary.LittleEndian.AppendUint16(h, sampleSize*8)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, 0)
+	if _, err := w.Write(h); err != nil {
+		return nil, err
+	}
+	return &wavWriter{w: w}, nil
+}
+
+func (ww *wavWriter) write(samples [][2]float64) error {
+	ww.data = ww.data[:0]
+	for _, s := range samples {
+		for _, v := range s {
+			v = max(-1, min(1, v)) // Mixed samples may clip.
+			ww.data = binary.LittleEndian.AppendUint16(ww.data, uint16(int16(v*(1<<15-1))))
+		}
+	}
+	n, err := ww.w.Write(ww.data)
+	ww.size += n
+	return err
+}
+
+func (ww *wavWriter) close() error {
+	if ww.size > 1<<32-1-36 {
+		return errors.New("wav: data too large")
+	}
+	for _, f := range []struct {
+		offset int64
+		value  int
+	}{{4, 36 + ww.size}, {40, ww.size}} {
+		if _, err := ww.w.Seek(f.offset, io.SeekStart); err != nil {
+			return err
+		}
+		if err := binary.Write(ww.w, binary.LittleEndian, uint32(f.value)); err != nil {
+			return err
+		}
+	}
+	_, err := ww.w.Seek(0, io.SeekEnd)
+	return err
+}
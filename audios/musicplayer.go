@@ -4,21 +4,25 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"math"
 	"path/filepath"
 	"time"
 
 	"github.com/gopxl/beep"
 	"github.com/gopxl/beep/effects"
 	"github.com/gopxl/beep/generators"
-	"github.com/gopxl/beep/speaker"
 )
 
 type MusicPlayer struct {
 	seekCloser beep.StreamSeekCloser // for seek and close
 	format     beep.Format           // for duration
+	stretcher  *TimeStretcher        // for pitch-preserving rate; nil when off
 	ctrl       *beep.Ctrl            // for pause
 	streamer   *beep.Resampler       // main streamer
 	volume     *effects.Volume       // for volume
+	rate       float64
+	gain       float64 // in dB, for loudness normalization
+	out        Output
 }
 
 // I guess NewMusicPlayer should return pointer, so that
@@ -28,6 +32,18 @@ func NewMusicPlayer(rc io.ReadCloser, ext string) (*MusicPlayer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("decode %s: %w", ext, err)
 	}
+	return newMusicPlayer(seekCloser, format), nil
+}
+
+// NewSilentMusicPlayer plays silence for the duration, in place of
+// missing music. Its position goes on as music, hence the clock
+// syncs with it as usual.
+func NewSilentMusicPlayer(d time.Duration) *MusicPlayer {
+	format := beep.Format{SampleRate: defaultSampleRate, NumChannels: 2, Precision: 2}
+	return newMusicPlayer(&silence{len: defaultSampleRate.N(d)}, format)
+}
+
+func newMusicPlayer(seekCloser beep.StreamSeekCloser, format beep.Format) *MusicPlayer {
 	// done := make(chan bool)
 	// callback := beep.Callback(func() { done <- true })
 	// ctrl := &beep.Ctrl{Streamer: beep.Seq(seekCloser, callback)}
@@ -40,7 +56,9 @@ func NewMusicPlayer(rc io.ReadCloser, ext string) (*MusicPlayer, error) {
 		ctrl:       ctrl,
 		streamer:   streamer,
 		volume:     volume,
-	}, nil
+		rate:       1,
+		out:        output,
+	}
 }
 
 func NewMusicPlayerFromFile(fsys fs.FS, name string) (*MusicPlayer, error) {
@@ -58,22 +76,50 @@ func (mp MusicPlayer) Play() {
 	if mp.IsEmpty() {
 		return
 	}
-	speaker.Play(mp.volume)
+	mp.out.Play(mp.volume)
 }
 
-func (mp *MusicPlayer) Rewind() {
+// SetOutput should be called before the music is played.
+func (mp *MusicPlayer) SetOutput(o Output) { mp.out = o }
+
+func (mp *MusicPlayer) Rewind() { mp.Seek(0) }
+
+// Lock is required when modifying streamers.
+func (mp *MusicPlayer) Seek(d time.Duration) error {
 	if mp.IsEmpty() {
-		return
+		return nil
+	}
+	mp.out.Lock()
+	defer mp.out.Unlock()
+	pos := mp.format.SampleRate.N(d)
+	pos = max(0, min(pos, mp.seekCloser.Len()))
+	if err := mp.seekCloser.Seek(pos); err != nil {
+		return err
 	}
-	mp.seekCloser.Seek(0)
+	if mp.stretcher != nil {
+		mp.stretcher.Reset(pos)
+	}
+	return nil
 }
 
+// Lock is required when reading position, since output keeps streaming.
+// Position proceeds by the output's buffer size.
+// Time stretcher reads ahead of what it has streamed, hence
+// its own position is used instead of the source's.
 func (mp MusicPlayer) Current() time.Duration {
 	if mp.IsEmpty() {
 		return 0
 	}
+	mp.out.Lock()
+	var pos int
+	if mp.stretcher != nil {
+		pos = mp.stretcher.Position()
+	} else {
+		pos = mp.seekCloser.Position()
+	}
+	mp.out.Unlock()
 	sr := mp.format.SampleRate
-	return sr.D(mp.seekCloser.Position())
+	return sr.D(pos)
 }
 
 func (mp MusicPlayer) Duration() time.Duration {
@@ -84,29 +130,64 @@ func (mp MusicPlayer) Duration() time.Duration {
 	return sr.D(mp.seekCloser.Len())
 }
 
-func (mp MusicPlayer) PlaybackRate() float64 {
-	if mp.IsEmpty() {
-		return 1
+func (mp MusicPlayer) PlaybackRate() float64 { return mp.rate }
+
+// Resampler also converts sample rate of music to the speaker's.
+// Hence, playback rate is multiplied to the base ratio.
+func (mp *MusicPlayer) SetPlaybackRate(rate float64) {
+	if mp.IsEmpty() || rate <= 0 {
+		return
+	}
+	mp.out.Lock()
+	defer mp.out.Unlock()
+	mp.rate = rate
+	base := float64(mp.format.SampleRate) / float64(defaultSampleRate)
+	if mp.stretcher != nil {
+		mp.stretcher.SetRatio(rate)
+		mp.streamer.SetRatio(base)
+	} else {
+		mp.streamer.SetRatio(base * rate)
 	}
-	return mp.streamer.Ratio()
 }
 
-func (mp *MusicPlayer) SetPlaybackRate(rate float64) {
-	if mp.IsEmpty() {
+func (mp MusicPlayer) IsTimeStretched() bool { return mp.stretcher != nil }
+
+// SetTimeStretch sets whether to keep the pitch when playback rate
+// is not 1. Otherwise, pitch goes higher as music plays faster.
+// It is fine to call it while playing.
+func (mp *MusicPlayer) SetTimeStretch(on bool) {
+	if mp.IsEmpty() || on == mp.IsTimeStretched() {
 		return
 	}
-	mp.streamer.SetRatio(rate)
+	mp.out.Lock()
+	if on {
+		pos := mp.seekCloser.Position()
+		mp.stretcher = NewTimeStretcher(mp.seekCloser, mp.format.SampleRate, pos)
+		mp.ctrl.Streamer = mp.stretcher
+	} else {
+		// Stretcher has read ahead; source goes back to where it has streamed.
+		mp.seekCloser.Seek(mp.stretcher.Position())
+		mp.stretcher = nil
+		mp.ctrl.Streamer = mp.seekCloser
+	}
+	mp.out.Unlock()
+	mp.SetPlaybackRate(mp.rate)
 }
 
 // beepVolume converts volume from [0, 1] to [-5, 0].
 // [-5, 0] is log scale.
 func beepVolume(vol float64) float64 { return vol*5 - 5 }
 
+// SetGain sets gain in dB, which is applied along with volume.
+// It is for normalizing loudness of each music.
+func (mp *MusicPlayer) SetGain(db float64) { mp.gain = db }
+
+// Gain in dB goes to base 2 scale: 2^(v) = 10^(db/20).
 func (mp *MusicPlayer) SetVolume(vol float64) {
 	if mp.IsEmpty() {
 		return
 	}
-	mp.volume.Volume = beepVolume(vol)
+	mp.volume.Volume = beepVolume(vol) + mp.gain/(20*math.Log10(2))
 	if vol <= 0.001 { // 0.1%
 		mp.volume.Silent = true
 	} else {
@@ -126,9 +207,9 @@ func (mp *MusicPlayer) Pause() {
 	if mp.IsEmpty() {
 		return
 	}
-	speaker.Lock()
+	mp.out.Lock()
 	mp.ctrl.Paused = true
-	speaker.Unlock()
+	mp.out.Unlock()
 }
 
 // Lock is required when modifying beep.Ctrl.
@@ -136,16 +217,16 @@ func (mp *MusicPlayer) Resume() {
 	if mp.IsEmpty() {
 		return
 	}
-	speaker.Lock()
+	mp.out.Lock()
 	mp.ctrl.Paused = false
-	speaker.Unlock()
+	mp.out.Unlock()
 }
 
 func (mp *MusicPlayer) Close() {
 	if mp.IsEmpty() {
 		return
 	}
-	speaker.Clear()
+	mp.out.Clear()
 	mp.seekCloser.Close()
 }
 
@@ -153,3 +234,32 @@ func NewSilence(duration time.Duration) beep.Streamer {
 	num := defaultSampleRate.N(duration)
 	return generators.Silence(num)
 }
+
+// silence is a seekable version of generators.Silence.
+type silence struct {
+	pos int
+	len int
+}
+
+func (s *silence) Stream(samples [][2]float64) (n int, ok bool) {
+	n = min(len(samples), s.len-s.pos)
+	if n <= 0 {
+		return 0, false
+	}
+	clear(samples[:n])
+	s.pos += n
+	return n, true
+}
+
+func (s *silence) Err() error    { return nil }
+func (s *silence) Len() int      { return s.len }
+func (s *silence) Position() int { return s.pos }
+func (s *silence) Close() error  { return nil }
+
+func (s *silence) Seek(p int) error {
+	if p < 0 || p > s.len {
+		return fmt.Errorf("seek position %d out of range [0, %d]", p, s.len)
+	}
+	s.pos = p
+	return nil
+}
@@ -0,0 +1,143 @@
+package audios
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// NullOutput streams streamers in real time without an audio device,
+// hence positions of players go on as on speaker.
+// It is for tests and servers which have no sound card.
+type NullOutput struct {
+	mu    sync.Mutex
+	mixer beep.Mixer
+	start time.Time
+	pos   int
+	buf   [][2]float64
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	ww      *wavWriter // Mix is written as streamed; nil when not recording.
+	records []Record
+	err     error // The first error in writing.
+}
+
+// Record is a streamer played to a recorder.
+type Record struct {
+	Time     time.Duration // Position of the output when played.
+	Streamer beep.Streamer
+}
+
+// Streaming interval is shorter than speaker's buffer duration,
+// so that positions of players go on more smoothly.
+const nullOutputInterval = 10 * time.Millisecond
+
+func NewNullOutput() *NullOutput { return newNullOutput(nil) }
+
+func newNullOutput(ww *wavWriter) *NullOutput {
+	o := &NullOutput{
+		start: time.Now(),
+		buf:   make([][2]float64, 512),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		ww:    ww,
+	}
+	go o.run()
+	return o
+}
+
+// NewRecorder returns NullOutput which also captures what would
+// have been played, with timestamps. The mix is written to w
+// in WAV as it is streamed; the WAV is completed when closed.
+func NewRecorder(w io.WriteSeeker) (*NullOutput, error) {
+	ww, err := newWAVWriter(w, defaultSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	return newNullOutput(ww), nil
+}
+
+func (o *NullOutput) run() {
+	defer close(o.done)
+	ticker := time.NewTicker(nullOutputInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			o.stream(defaultSampleRate.N(time.Since(o.start)))
+		}
+	}
+}
+
+// stream streams until the position reaches end.
+// Writing stops at the first error, which Close returns.
+func (o *NullOutput) stream(end int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for o.pos < end {
+		buf := o.buf[:min(end-o.pos, len(o.buf))]
+		o.mixer.Stream(buf)
+		if o.ww != nil && o.err == nil {
+			o.err = o.ww.write(buf)
+		}
+		o.pos += len(buf)
+	}
+}
+
+func (o *NullOutput) Play(s ...beep.Streamer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.mixer.Add(s...)
+	if o.ww != nil {
+		t := defaultSampleRate.D(o.pos)
+		for _, s := range s {
+			o.records = append(o.records, Record{Time: t, Streamer: s})
+		}
+	}
+}
+
+func (o *NullOutput) Lock()   { o.mu.Lock() }
+func (o *NullOutput) Unlock() { o.mu.Unlock() }
+
+func (o *NullOutput) Clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.mixer.Clear()
+}
+
+func (o *NullOutput) Position() time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return defaultSampleRate.D(o.pos)
+}
+
+// Records returns streamers played so far, in the order of time.
+func (o *NullOutput) Records() []Record {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Record(nil), o.records...)
+}
+
+// Close stops streaming. Position does not go on after closed.
+// Recorder completes the WAV, and returns the error in writing it.
+// Close can be called multiple times.
+func (o *NullOutput) Close() error {
+	o.once.Do(func() {
+		close(o.stop)
+		<-o.done
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if o.ww != nil && o.err == nil {
+			o.err = o.ww.close()
+		}
+	})
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
@@ -0,0 +1,258 @@
+package audios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep"
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+const (
+	opusSampleRate = 48000
+	// Opus needs some samples to converge after seeking.
+	// 80ms is recommended by RFC 7845.
+	opusPreRoll = opusSampleRate * 80 / 1000
+	// A packet lasts at most 120ms.
+	opusMaxPacketSize = opusSampleRate * 120 / 1000
+)
+
+// opusDecoder decodes Ogg Opus. Only mono and stereo are supported.
+// Opus is always decoded at 48000Hz.
+type opusDecoder struct {
+	r        io.Reader
+	rs       io.ReadSeeker // nil when the reader is not seekable.
+	ogg      *oggreader.OggReader
+	dec      opus.Decoder
+	channels int
+	preSkip  int
+	gain     float64
+
+	buf     []float32
+	held    []byte // A packet read ahead while seeking.
+	pending [][2]float64
+	granule int // Granule position of the next decoded sample.
+	skip    int // Decoded samples before skip are discarded.
+	pos     int
+	len     int // -1 when unknown.
+	err     error
+}
+
+func decodeOpus(r io.Reader) (beep.StreamSeekCloser, beep.Format, error) {
+	d := &opusDecoder{r: r, len: -1}
+	format, err := d.init()
+	if err != nil {
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+		return nil, beep.Format{}, fmt.Errorf("opus: %w", err)
+	}
+	return d, format, nil
+}
+
+func (d *opusDecoder) init() (beep.Format, error) {
+	if rs, ok := d.r.(io.ReadSeeker); ok {
+		if granule, err := lastGranule(rs); err == nil {
+			d.rs = rs
+			d.len = granule // Pre-skip is subtracted after reading header.
+		}
+	}
+
+	ogg, h, err := oggreader.NewWith(d.r)
+	if err != nil {
+		return beep.Format{}, err
+	}
+	if h.ChannelMap != 0 || h.Channels < 1 || h.Channels > 2 {
+		return beep.Format{}, fmt.Errorf("unsupported channel mapping %d with %d channels", h.ChannelMap, h.Channels)
+	}
+	d.channels = int(h.Channels)
+	d.preSkip = int(h.PreSkip)
+	// Output gain is in Q7.8 dB.
+	d.gain = math.Pow(10, float64(int16(h.OutputGain))/256/20)
+	if d.len >= 0 {
+		d.len = max(d.len-d.preSkip, 0)
+	}
+	if d.dec, err = opus.NewDecoderWithOutput(opusSampleRate, d.channels); err != nil {
+		return beep.Format{}, err
+	}
+	d.buf = make([]float32, opusMaxPacketSize*d.channels)
+	d.ogg = ogg
+	if err := d.readTags(); err != nil {
+		return beep.Format{}, err
+	}
+
+	format := beep.Format{
+		SampleRate:  opusSampleRate,
+		NumChannels: d.channels,
+		Precision:   2,
+	}
+	return format, nil
+}
+
+// lastGranule returns the granule position of the last page,
+// which is the total number of samples including pre-skip.
+func lastGranule(rs io.ReadSeeker) (int, error) {
+	const tailSize = 64 * 1024 // A page is smaller than 64KB.
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	offset := max(size-tailSize, 0)
+	if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	tail := make([]byte, size-offset)
+	if _, err := io.ReadFull(rs, tail); err != nil {
+		return 0, err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+14 > len(tail) {
+			continue
+		}
+		// Granule position is -1 when no packet ends in the page.
+		granule := binary.LittleEndian.Uint64(tail[i+6 : i+14])
+		if granule != math.MaxUint64 {
+			return int(granule), nil
+		}
+	}
+	return 0, errors.New("no granule position")
+}
+
+// The comment header follows the identification header.
+func (d *opusDecoder) readTags() error {
+	packet, _, err := d.ogg.ParseNextPacket()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(packet, []byte("OpusTags")) {
+		return errors.New("missing comment header")
+	}
+	return nil
+}
+
+func (d *opusDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) && d.err == nil {
+		if len(d.pending) == 0 {
+			if !d.decodeNext() {
+				break
+			}
+			continue
+		}
+		c := copy(samples[n:], d.pending)
+		d.pending = d.pending[c:]
+		d.pos += c
+		n += c
+	}
+	return n, n > 0
+}
+
+// decodeNext decodes a packet into pending samples.
+// It returns false when the stream has ended.
+func (d *opusDecoder) decodeNext() bool {
+	packet := d.held
+	d.held = nil
+	if packet == nil {
+		var err error
+		packet, _, err = d.ogg.ParseNextPacket()
+		if err != nil {
+			if err != io.EOF {
+				d.err = err
+			}
+			return false
+		}
+	}
+
+	n, err := d.dec.DecodeToFloat32(packet, d.buf)
+	if err != nil {
+		d.err = err
+		return false
+	}
+	for i := 0; i < n; i++ {
+		pos := d.granule - d.preSkip
+		d.granule++
+		if pos < d.skip || d.len >= 0 && pos >= d.len {
+			continue
+		}
+		l := float64(d.buf[i*d.channels]) * d.gain
+		r := l
+		if d.channels == 2 {
+			r = float64(d.buf[i*d.channels+1]) * d.gain
+		}
+		d.pending = append(d.pending, [2]float64{l, r})
+	}
+	if d.len >= 0 && d.granule-d.preSkip >= d.len && len(d.pending) == 0 {
+		return false
+	}
+	return true
+}
+
+func (d *opusDecoder) Err() error { return d.err }
+
+func (d *opusDecoder) Len() int { return max(d.len, 0) }
+
+func (d *opusDecoder) Position() int { return d.pos }
+
+// Seek reads the stream again from the beginning. Pages which end
+// before the position, leaving pre-roll, are skipped without decoding.
+func (d *opusDecoder) Seek(p int) error {
+	if d.rs == nil {
+		return errors.New("opus: seek: resource is not io.Seeker")
+	}
+	if p < 0 || p > d.len {
+		return fmt.Errorf("opus: seek position %d out of range [0, %d]", p, d.len)
+	}
+	if _, err := d.rs.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("opus: seek: %w", err)
+	}
+	ogg, _, err := oggreader.NewWith(d.rs)
+	if err != nil {
+		return fmt.Errorf("opus: seek: %w", err)
+	}
+	d.ogg = ogg
+	if err := d.readTags(); err != nil {
+		return fmt.Errorf("opus: seek: %w", err)
+	}
+	if err := d.dec.Init(opusSampleRate, d.channels); err != nil {
+		return fmt.Errorf("opus: seek: %w", err)
+	}
+
+	d.held = nil
+	d.pending = d.pending[:0]
+	d.granule = 0
+	target := p + d.preSkip - opusPreRoll
+	for target > 0 {
+		packet, h, err := d.ogg.ParseNextPacket()
+		if err != nil {
+			if err != io.EOF {
+				return fmt.Errorf("opus: seek: %w", err)
+			}
+			break
+		}
+		if int(h.GranulePosition) > target {
+			// The packet starts at the end of the previous page.
+			d.held = packet
+			break
+		}
+		d.granule = int(h.GranulePosition)
+	}
+	d.skip = p
+	d.pos = p
+	d.err = nil
+	return nil
+}
+
+func (d *opusDecoder) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package audios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// A packet of only a TOC byte decodes to 20ms of silence:
+// SILK wideband 20ms (configuration 9), a single frame.
+const (
+	testOpusPacket     = 9 << 3
+	testOpusPacketSize = opusSampleRate * 20 / 1000
+)
+
+var oggCRCTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return
+}()
+
+// appendOggPage appends a page of given packets, each shorter than 255 bytes.
+func appendOggPage(b []byte, headerType byte, granule uint64, index uint32, packets ...[]byte) []byte {
+	var page bytes.Buffer
+	page.WriteString("OggS")
+	page.WriteByte(0) // Version
+	page.WriteByte(headerType)
+	binary.Write(&page, binary.LittleEndian, granule)
+	binary.Write(&page, binary.LittleEndian, uint32(1)) // Serial
+	binary.Write(&page, binary.LittleEndian, index)
+	binary.Write(&page, binary.LittleEndian, uint32(0)) // Checksum, set below.
+	page.WriteByte(byte(len(packets)))
+	for _, p := range packets {
+		page.WriteByte(byte(len(p)))
+	}
+	for _, p := range packets {
+		page.Write(p)
+	}
+
+	data := page.Bytes()
+	var crc uint32
+	for _, v := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^v]
+	}
+	binary.LittleEndian.PutUint32(data[22:26], crc)
+	return append(b, data...)
+}
+
+// newTestOpus returns Ogg Opus data of silent packets, a page per
+// 10 packets. End is the granule position of the last page, which
+// trims the last packet when it is less than that of all packets.
+func newTestOpus(channels, mapping byte, preSkip uint16, packets, end int) []byte {
+	head := []byte("OpusHead\x01")
+	head = append(head, channels)
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, 44100) // Original rate; not used.
+	head = binary.LittleEndian.AppendUint16(head, 0)     // Output gain
+	head = append(head, mapping)
+	tags := []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00")
+
+	const bos, eos = 0x02, 0x04
+	b := appendOggPage(nil, bos, 0, 0, head)
+	b = appendOggPage(b, 0, 0, 1, tags)
+	toc := byte(testOpusPacket)
+	if channels == 2 {
+		toc |= 1 << 2
+	}
+	for i := 0; i < packets; i += 10 {
+		n := min(10, packets-i)
+		ps := make([][]byte, n)
+		for j := range ps {
+			ps[j] = []byte{toc}
+		}
+		granule := (i + n) * testOpusPacketSize
+		var headerType byte
+		if i+n == packets {
+			granule = end
+			headerType = eos
+		}
+		b = appendOggPage(b, headerType, uint64(granule), uint32(2+i/10), ps...)
+	}
+	return b
+}
+
+func TestDecodeOpus(t *testing.T) {
+	const (
+		packets = 50
+		preSkip = 312
+		trim    = 100
+	)
+	end := packets*testOpusPacketSize - trim
+	for _, channels := range []byte{1, 2} {
+		data := newTestOpus(channels, 0, preSkip, packets, end)
+		d, format, err := decodeOpus(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d channels: %v", channels, err)
+		}
+		if format.SampleRate != opusSampleRate || format.NumChannels != int(channels) {
+			t.Errorf("%d channels: got format %+v", channels, format)
+		}
+		want := end - preSkip
+		if d.Len() != want {
+			t.Errorf("%d channels: got length %d, want %d", channels, d.Len(), want)
+		}
+		got := streamAll(t, d)
+		if len(got) != want || d.Position() != want {
+			t.Errorf("%d channels: got %d samples at position %d, want %d", channels, len(got), d.Position(), want)
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("%d channels: %v", channels, err)
+		}
+	}
+}
+
+func TestDecodeOpusSeek(t *testing.T) {
+	const packets, preSkip = 50, 312
+	end := packets * testOpusPacketSize
+	data := newTestOpus(2, 0, preSkip, packets, end)
+	d, _, err := decodeOpus(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	length := d.Len()
+	// Positions in the first page, across pages, and at the end.
+	for _, p := range []int{100, length / 2, 0, length - 1, length} {
+		if err := d.Seek(p); err != nil {
+			t.Fatalf("seek %d: %v", p, err)
+		}
+		if d.Position() != p {
+			t.Errorf("seek %d: got position %d", p, d.Position())
+		}
+		if got := streamAll(t, d); len(got) != length-p {
+			t.Errorf("seek %d: got %d samples, want %d", p, len(got), length-p)
+		}
+	}
+	for _, p := range []int{-1, length + 1} {
+		if err := d.Seek(p); err == nil {
+			t.Errorf("seek %d: got no error", p)
+		}
+	}
+}
+
+// Non-seekable reader does not know the length in advance,
+// hence the last packet is not trimmed.
+func TestDecodeOpusNotSeekable(t *testing.T) {
+	const packets, preSkip = 12, 312
+	data := newTestOpus(1, 0, preSkip, packets, packets*testOpusPacketSize-100)
+	d, _, err := decodeOpus(struct{ io.Reader }{bytes.NewReader(data)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 0 {
+		t.Errorf("got length %d, want 0 when unknown", d.Len())
+	}
+	if got := streamAll(t, d); len(got) != packets*testOpusPacketSize-preSkip {
+		t.Errorf("got %d samples, want %d", len(got), packets*testOpusPacketSize-preSkip)
+	}
+	if err := d.Seek(0); err == nil {
+		t.Error("seek at non-seekable reader: got no error")
+	}
+}
+
+func TestDecodeOpusErrors(t *testing.T) {
+	noTags := newTestOpus(1, 0, 0, 1, testOpusPacketSize)
+	// Drop the page of OpusTags, which follows the first page.
+	headLen := 27 + 1 + 19
+	tagsLen := 27 + 1 + 16
+	noTags = append(noTags[:headLen:headLen], noTags[headLen+tagsLen:]...)
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not Ogg", []byte("RIFF\x00\x00\x00\x00WAVE"), "opus:"},
+		{"3 channels", newTestOpus(3, 0, 0, 1, testOpusPacketSize), "unsupported channel"},
+		{"no tags", noTags, "missing comment header"},
+	} {
+		_, _, err := decodeOpus(bytes.NewReader(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package audios
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/speaker"
+)
+
+// Output is where players play streamers to. Speaker is used by default.
+// Other outputs are for running without an audio device.
+type Output interface {
+	Play(s ...beep.Streamer)
+	// Lock is required when modifying streamers being played.
+	Lock()
+	Unlock()
+	Clear()
+}
+
+var output Output = speakerOutput{}
+
+// SetOutput sets the output of players made afterward.
+// It is supposed to be called at startup.
+func SetOutput(o Output) { output = o }
+
+// speakerOutput initializes speaker at the first play,
+// so that no audio device is required unless it plays.
+type speakerOutput struct{}
+
+var initSpeaker sync.Once
+
+func (speakerOutput) Play(s ...beep.Streamer) {
+	initSpeaker.Do(func() {
+		speaker.Init(defaultSampleRate, defaultSampleRate.N(time.Second/20))
+	})
+	speaker.Play(s...)
+}
+
+func (speakerOutput) Lock()   { speaker.Lock() }
+func (speakerOutput) Unlock() { speaker.Unlock() }
+func (speakerOutput) Clear()  { speaker.Clear() }
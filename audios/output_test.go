@@ -0,0 +1,124 @@
+package audios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestWAV returns 16-bit stereo WAV data of a constant level.
+func newTestWAV(d time.Duration) []byte {
+	n := defaultSampleRate.N(d)
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+n*4))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []any{
+		uint32(16), uint16(wavFormatPCM), uint16(2), uint32(defaultSampleRate),
+		uint32(defaultSampleRate * 4), uint16(4), uint16(16),
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(n*4))
+	for i := 0; i < n*2; i++ {
+		binary.Write(&b, binary.LittleEndian, int16(1<<12))
+	}
+	return b.Bytes()
+}
+
+func TestMusicPlayerWithNullOutput(t *testing.T) {
+	o := NewNullOutput()
+	defer o.Close()
+
+	rc := io.NopCloser(bytes.NewReader(newTestWAV(5 * time.Second)))
+	mp, err := NewMusicPlayer(rc, ".wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp.SetOutput(o)
+	mp.SetVolume(1)
+	mp.Play()
+
+	time.Sleep(200 * time.Millisecond)
+	if c := mp.Current(); c <= 0 {
+		t.Fatalf("current: got %v, want > 0 after played", c)
+	}
+
+	mp.Pause()
+	paused := mp.Current()
+	time.Sleep(100 * time.Millisecond)
+	if c := mp.Current(); c != paused {
+		t.Fatalf("current: got %v, want %v while paused", c, paused)
+	}
+
+	mp.Resume()
+	time.Sleep(100 * time.Millisecond)
+	if c := mp.Current(); c <= paused {
+		t.Fatalf("current: got %v, want > %v after resumed", c, paused)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "record.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	o, err := NewRecorder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scale := 1.0
+	sp := NewSoundPlayer(&scale)
+	sp.SetOutput(o)
+	if err := sp.Add(newTestWAV(50*time.Millisecond), "hit.wav"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	sp.Play("hit.wav")
+	time.Sleep(100 * time.Millisecond)
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Closing again is fine.
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rs := o.Records()
+	if len(rs) != 1 {
+		t.Fatalf("records: got %d, want 1", len(rs))
+	}
+	if rs[0].Time <= 0 {
+		t.Fatalf("record time: got %v, want > 0", rs[0].Time)
+	}
+
+	// The mix has been written to the file as streamed.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	d, _, err := decodeWAV(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := streamAll(t, d)
+	if pos := defaultSampleRate.D(len(ss)); pos != o.Position() {
+		t.Fatalf("samples: got %v, want %v as streamed", pos, o.Position())
+	}
+	// Converting time to samples may be off by rounding.
+	// Samples are quantized to 16-bit in the file.
+	start := defaultSampleRate.N(rs[0].Time)
+	if len(ss) <= start+1 || ss[start+1][0] == 0 {
+		t.Fatalf("samples: sound is not recorded at %v", rs[0].Time)
+	}
+	if ss[max(start-1, 0)][0] != 0 {
+		t.Fatalf("samples: sound is recorded before %v", rs[0].Time)
+	}
+}
@@ -0,0 +1,96 @@
+package audios
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"path"
+)
+
+// Policies of picking a sound in a group.
+const (
+	GroupRandom     = "random"      // Random, with no immediate repeats.
+	GroupRoundRobin = "round-robin" // In order of file numbers.
+	GroupVelocity   = "velocity"    // Sounds are layers from soft to loud, picked by volume.
+)
+
+// GroupOptionsFilename is the name of a skin file in a group directory.
+const GroupOptionsFilename = "group.json"
+
+// GroupOptions is set in a skin for each group.
+// Jitters vary each play slightly, so that it does not sound repetitive.
+type GroupOptions struct {
+	Policy       string
+	PitchJitter  float64 // Maximum pitch shift in semitones.
+	VolumeJitter float64 // Maximum volume change in ratio.
+}
+
+type soundGroup struct {
+	GroupOptions
+	last int // Index of the last played sound. Negative when none.
+}
+
+func newSoundGroup() *soundGroup {
+	return &soundGroup{
+		GroupOptions: GroupOptions{Policy: GroupRandom},
+		last:         -1,
+	}
+}
+
+// The returned group is usable with default options even on error.
+func newSoundGroupFromDir(fsys fs.FS, dir string) (*soundGroup, error) {
+	g := newSoundGroup()
+	data, err := fs.ReadFile(fsys, path.Join(dir, GroupOptionsFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return g, nil // Group options are optional.
+	}
+	if err != nil {
+		return g, err
+	}
+	var opts GroupOptions
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return g, err
+	}
+	switch opts.Policy {
+	case GroupRandom, GroupRoundRobin, GroupVelocity:
+	case "":
+		opts.Policy = GroupRandom
+	default:
+		return g, fmt.Errorf("unknown policy %q", opts.Policy)
+	}
+	g.GroupOptions = opts
+	return g, nil
+}
+
+// pick returns the index of the sound to play among n sounds.
+// Volume is in [0, 1], before jitter and volume scale are applied.
+func (g *soundGroup) pick(n int, vol float64) int {
+	var i int
+	switch {
+	case n <= 1:
+	case g.Policy == GroupRoundRobin:
+		i = (g.last + 1) % n
+	case g.Policy == GroupVelocity:
+		i = max(0, min(n-1, int(vol*float64(n))))
+	case g.last < 0 || g.last >= n:
+		i = rand.Intn(n)
+	default:
+		// Skipping the last one gives no immediate repeats.
+		i = rand.Intn(n - 1)
+		if i >= g.last {
+			i++
+		}
+	}
+	g.last = i
+	return i
+}
+
+// jitter returns a pitch ratio and a volume varied randomly.
+func (g soundGroup) jitter(vol float64) (float64, float64) {
+	pitch := math.Pow(2, g.PitchJitter*(2*rand.Float64()-1)/12)
+	vol *= 1 + g.VolumeJitter*(2*rand.Float64()-1)
+	return pitch, max(0, min(1, vol))
+}
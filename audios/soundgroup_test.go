@@ -0,0 +1,102 @@
+package audios
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestSoundGroupPick(t *testing.T) {
+	const n = 5
+	random := newSoundGroup()
+	last := -1
+	for range 100 {
+		i := random.pick(n, 1)
+		if i == last || i < 0 || i >= n {
+			t.Fatalf("random: got %d after %d", i, last)
+		}
+		last = i
+	}
+
+	robin := newSoundGroup()
+	robin.Policy = GroupRoundRobin
+	for j := range 2 * n {
+		if i := robin.pick(n, 1); i != j%n {
+			t.Fatalf("round-robin: got %d, want %d", i, j%n)
+		}
+	}
+
+	velocity := newSoundGroup()
+	velocity.Policy = GroupVelocity
+	for _, tc := range []struct {
+		vol  float64
+		want int
+	}{{0, 0}, {0.19, 0}, {0.5, 2}, {0.99, 4}, {1, 4}} {
+		if i := velocity.pick(n, tc.vol); i != tc.want {
+			t.Fatalf("velocity: got %d at volume %v, want %d", i, tc.vol, tc.want)
+		}
+	}
+}
+
+func TestNewSoundGroupFromDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"plain/1.wav":           {},
+		"robin/group.json":      {Data: []byte(`{"Policy": "round-robin", "PitchJitter": 1}`)},
+		"unknown/group.json":    {Data: []byte(`{"Policy": "shuffle"}`)},
+		"broken/group.json":     {Data: []byte(`{"Policy":`)},
+		"unreadable/group.json": {Mode: fs.ModeDir},
+	}
+	for _, tc := range []struct {
+		dir     string
+		policy  string
+		wantErr bool
+	}{
+		{"plain", GroupRandom, false},
+		{"robin", GroupRoundRobin, false},
+		{"unknown", GroupRandom, true},
+		{"broken", GroupRandom, true},
+		{"unreadable", GroupRandom, true},
+	} {
+		g, err := newSoundGroupFromDir(fsys, tc.dir)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error: %v", tc.dir, err, tc.wantErr)
+		}
+		if g == nil || g.Policy != tc.policy {
+			t.Errorf("%s: got group %+v, want policy %s", tc.dir, g, tc.policy)
+		}
+	}
+}
+
+func TestAddDir(t *testing.T) {
+	wav := newTestWAV(10 * time.Millisecond)
+	fsys := fstest.MapFS{
+		"taps/1.wav":   {Data: wav},
+		"taps/2.wav":   {Data: []byte("broken")},
+		"taps/3.wav":   {Data: wav},
+		"broken/1.wav": {Data: []byte("broken")},
+	}
+	scale := 1.0
+	sp := NewSoundPlayer(&scale)
+
+	// Broken files are reported, while the rest make the group.
+	err := sp.AddDir(fsys, "taps/")
+	if err == nil || !strings.Contains(err.Error(), "taps/2.wav") {
+		t.Errorf("taps: got error %v, want that of taps/2.wav", err)
+	}
+	if !sp.Has("taps/") || len(sp.buffers["taps/"].keys) != 2 {
+		t.Fatalf("taps: got buffer %v, want 2 sounds", sp.buffers["taps/"].keys)
+	}
+	for range 4 {
+		sp.streamer("taps/", 1) // Should not pick the broken one.
+	}
+
+	// Group with no sound is not added.
+	if err := sp.AddDir(fsys, "broken/"); err == nil {
+		t.Error("broken: got no error")
+	}
+	if sp.Has("broken/") {
+		t.Error("broken: group with no sound is added")
+	}
+}
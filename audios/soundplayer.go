@@ -1,33 +1,45 @@
 package audios
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
-	"math/rand"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gopxl/beep"
 	"github.com/gopxl/beep/effects"
-	"github.com/gopxl/beep/speaker"
 	"github.com/hndada/gosu/util"
 )
 
+// SoundPlayer plays many voices at once. A voice can be played on a
+// channel; a new voice on the channel chokes the previous one,
+// as keysounds on the same key do.
 type SoundPlayer struct {
 	buffers          map[string]SoundBuffer
 	bufferNames      []string
+	groups           map[string]*soundGroup // Buffers added by AddDir.
 	soundVolumeScale *float64
 	PlaybackRate     float64
+
+	voices   map[*Voice]struct{}
+	channels map[int]*Voice
+	out      Output
 }
 
 func NewSoundPlayer(scale *float64) SoundPlayer {
-	buffers := map[string]SoundBuffer{"": newSoundBuffer()}
+	buffers := map[string]SoundBuffer{"default": newSoundBuffer()}
 	bufferNames := []string{"default"}
 	return SoundPlayer{
 		buffers:          buffers,
 		bufferNames:      bufferNames,
+		groups:           map[string]*soundGroup{"default": newSoundGroup()},
 		soundVolumeScale: scale,
 		PlaybackRate:     1,
+		voices:           make(map[*Voice]struct{}),
+		channels:         make(map[int]*Voice),
+		out:              output,
 	}
 }
 
@@ -36,31 +48,59 @@ func (sp *SoundPlayer) AddFile(fsys fs.FS, name string) error {
 	if err != nil {
 		return fmt.Errorf("read file %s: %w", name, err)
 	}
+	return sp.Add(data, name)
+}
+
+// Add adds audio data to SoundPlayer with the given name.
+// Extension of the name is used for decoding.
+func (sp *SoundPlayer) Add(data []byte, name string) error {
 	sb := sp.buffers["default"]
-	return sb.add(data, name)
+	err := sb.add(data, name)
+	sp.buffers["default"] = sb
+	return err
 }
 
-// AddDir adds audio files in the directory to SoundPlayer.
+// AddDir adds audio files in the directory to SoundPlayer as a group.
+// Playing the group plays one of them, picked by the group's policy.
+// Name of a directory may end with a slash.
+// Files failed to decode are left out of the group, and their errors
+// are returned. The group is not added when no file is decoded.
 func (sp *SoundPlayer) AddDir(fsys fs.FS, name string) error {
 	base := strings.TrimSuffix(name, filepath.Ext(name))
-	paths := util.BatchElements(fsys, base)
+	dir := strings.TrimSuffix(base, "/")
+	paths := util.BatchElements(fsys, dir)
 	if len(paths) == 0 {
 		return sp.AddFile(fsys, name)
 	}
 
 	sb := newSoundBuffer()
+	var errs []error
 	for _, path := range paths {
 		data, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fmt.Errorf("read file %s: %w", path, err)
 		}
-		_ = sb.add(data, path)
+		if err := sb.add(data, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(sb.keys) == 0 {
+		errs = append(errs, fmt.Errorf("no sound in %s", dir))
+		return errors.Join(errs...)
+	}
+	g, err := newSoundGroupFromDir(fsys, dir)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("read group options %s: %w", dir, err))
 	}
 	sp.buffers[base] = sb
 	sp.bufferNames = append(sp.bufferNames, base)
-	return nil
+	sp.groups[base] = g
+	return errors.Join(errs...)
 }
 
+// SetOutput should be called before any sound is played.
+func (sp *SoundPlayer) SetOutput(o Output) { sp.out = o }
+
 // Count returns the number of kinds of sounds in SoundPlayer.
 func (sp SoundPlayer) Count() int {
 	defBuf := sp.buffers["default"]
@@ -76,25 +116,85 @@ func (sp SoundPlayer) containsName(name string) bool {
 	return false
 }
 
-func (sp SoundPlayer) Play(name string) { sp.PlayWithVolume(name, 1) }
-func (sp SoundPlayer) PlayWithVolume(name string, vol float64) {
+// Has reports whether SoundPlayer has a sound of the name.
+func (sp SoundPlayer) Has(name string) bool {
+	if sp.containsName(name) {
+		return true
+	}
+	_, ok := sp.buffers["default"].starts[name]
+	return ok
+}
+
+func (sp SoundPlayer) Play(name string) *Voice { return sp.PlayWithVolume(name, 1) }
+func (sp SoundPlayer) PlayWithVolume(name string, vol float64) *Voice {
+	return sp.PlayWithDelay(name, vol, 0)
+}
+
+// PlayWithDelay plays the sound after the delay. Scheduling sounds
+// a bit ahead makes them on time regardless of the update rate.
+func (sp SoundPlayer) PlayWithDelay(name string, vol float64, delay time.Duration) *Voice {
+	s := sp.streamer(name, vol)
+	if delay > 0 {
+		s = beep.Seq(NewSilence(delay), s)
+	}
+	v := newVoice(s, sp.out)
+	sp.prune()
+	sp.voices[v] = struct{}{}
+	sp.out.Play(v)
+	return v
+}
+
+// PlayOnChannel chokes the previous voice on the channel.
+func (sp SoundPlayer) PlayOnChannel(ch int, name string, vol float64) *Voice {
+	sp.StopChannel(ch)
+	v := sp.PlayWithVolume(name, vol)
+	sp.channels[ch] = v
+	return v
+}
+
+func (sp SoundPlayer) StopChannel(ch int) {
+	sp.channels[ch].Stop()
+	delete(sp.channels, ch)
+}
+
+func (sp SoundPlayer) StopAll() {
+	for v := range sp.voices {
+		v.Stop()
+	}
+	clear(sp.voices)
+	clear(sp.channels)
+}
+
+// prune forgets finished voices.
+func (sp SoundPlayer) prune() {
+	sp.out.Lock()
+	defer sp.out.Unlock()
+	for v := range sp.voices {
+		if v.done {
+			delete(sp.voices, v)
+		}
+	}
+}
+
+func (sp SoundPlayer) streamer(name string, vol float64) beep.Streamer {
 	var s beep.Streamer
+	pitch := 1.0
 	if sp.containsName(name) {
 		sb := sp.buffers[name]
-		i := rand.Intn(len(sb.keys))
-		key := sb.keys[i]
+		g := sp.groups[name]
+		key := sb.keys[g.pick(len(sb.keys), vol)]
 		s = sb.buffer.Streamer(sb.starts[key], sb.ends[key])
+		pitch, vol = g.jitter(vol)
 	} else {
 		sb := sp.buffers["default"]
 		s = sb.buffer.Streamer(sb.starts[name], sb.ends[name])
 	}
 
-	if sp.PlaybackRate != 1 {
-		s = beep.ResampleRatio(quality, sp.PlaybackRate, s)
+	if ratio := sp.PlaybackRate * pitch; ratio != 1 {
+		s = beep.ResampleRatio(quality, ratio, s)
 	}
 	vol *= *sp.soundVolumeScale
-	volume := &effects.Volume{Streamer: s, Base: 2, Volume: beepVolume(vol)}
-	speaker.Play(volume)
+	return &effects.Volume{Streamer: s, Base: 2, Volume: beepVolume(vol)}
 }
 
 // func NewSoundMap(fsys fs.FS, format beep.Format) SoundMap {
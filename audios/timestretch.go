@@ -0,0 +1,215 @@
+package audios
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// TimeStretcher changes the speed of a streamer while keeping its pitch.
+// It uses WSOLA (Waveform Similarity Overlap-Add): frames are taken from
+// the source at the stretched hop, and each frame is slightly shifted
+// to the position which is the most similar to the natural continuation
+// of the previous frame. Frames are overlap-added with Hann window.
+// It is designed for ratio from 0.5 to 2.0.
+type TimeStretcher struct {
+	s      beep.Streamer
+	ratio  float64
+	frame  int // Frame size. Hop size of output is half of it.
+	search int // Maximum shift of a frame in both direction.
+	window []float64
+
+	in     [][2]float64 // Buffered source samples.
+	inBase int          // Index of in[0], counted from origin.
+	eof    bool
+	next   float64 // Nominal index of the next frame.
+	prev   int     // Actual index of the previous frame. Negative when none.
+	tail   [][2]float64
+	out    [][2]float64
+	done   bool
+
+	origin int     // Source position at the last reset.
+	pos    float64 // Source samples consumed by the emitted output.
+}
+
+const (
+	stretchFrameDuration  = 40 * time.Millisecond
+	stretchSearchDuration = 10 * time.Millisecond
+	stretchReadSize       = 1024
+	// Every few samples are compared when searching for the similar
+	// position. It is accurate enough and saves a lot of computation.
+	stretchCompareStride = 4
+)
+
+// NewTimeStretcher returns TimeStretcher which starts
+// at given source position with ratio 1.
+func NewTimeStretcher(s beep.Streamer, sr beep.SampleRate, origin int) *TimeStretcher {
+	frame := sr.N(stretchFrameDuration) &^ 1 // Frame size should be even.
+	window := make([]float64, frame)
+	for i := range window {
+		// Periodic Hann window sums up to 1 with half overlap.
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frame))
+	}
+	ts := &TimeStretcher{
+		s:      s,
+		ratio:  1,
+		frame:  frame,
+		search: sr.N(stretchSearchDuration),
+		window: window,
+	}
+	ts.Reset(origin)
+	return ts
+}
+
+// Reset should be called after the source has been sought.
+func (ts *TimeStretcher) Reset(origin int) {
+	ts.in = ts.in[:0]
+	ts.inBase = 0
+	ts.eof = false
+	ts.next = 0
+	ts.prev = -1
+	ts.tail = make([][2]float64, ts.frame/2)
+	ts.out = ts.out[:0]
+	ts.done = false
+	ts.origin = origin
+	ts.pos = 0
+}
+
+func (ts TimeStretcher) Ratio() float64 { return ts.ratio }
+
+// Ratio greater than 1 makes the stream faster.
+func (ts *TimeStretcher) SetRatio(ratio float64) {
+	if ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return
+	}
+	ts.ratio = ratio
+}
+
+// Position returns the source position of the next output sample.
+func (ts TimeStretcher) Position() int { return ts.origin + int(ts.pos) }
+
+func (ts *TimeStretcher) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(ts.out) < len(samples) && !ts.done {
+		ts.step()
+	}
+	n = copy(samples, ts.out)
+	ts.out = ts.out[:copy(ts.out, ts.out[n:])]
+	if n == 0 && ts.done {
+		return 0, false
+	}
+	ts.pos += float64(n) * ts.ratio
+	return n, true
+}
+
+func (ts *TimeStretcher) Err() error { return ts.s.Err() }
+
+// fill buffers source samples until index end, or until the source ends.
+func (ts *TimeStretcher) fill(end int) {
+	var buf [stretchReadSize][2]float64
+	for !ts.eof && ts.inBase+len(ts.in) < end {
+		n, ok := ts.s.Stream(buf[:])
+		ts.in = append(ts.in, buf[:n]...)
+		if !ok {
+			ts.eof = true
+		}
+	}
+}
+
+// at returns the source sample at given index. Samples out of buffer are silent.
+func (ts TimeStretcher) at(i int) [2]float64 {
+	i -= ts.inBase
+	if i < 0 || i >= len(ts.in) {
+		return [2]float64{}
+	}
+	return ts.in[i]
+}
+
+func (ts *TimeStretcher) step() {
+	hop := ts.frame / 2
+	p := int(math.Round(ts.next))
+	ts.fill(p + ts.search + ts.frame)
+	if ts.eof && p >= ts.inBase+len(ts.in) {
+		ts.out = append(ts.out, ts.tail...)
+		ts.tail = ts.tail[:0]
+		ts.done = true
+		return
+	}
+
+	var c int
+	if ts.prev < 0 {
+		// The first frame has no previous frame to overlap with.
+		// Tail is set to complement the window, so that the
+		// beginning of the stream does not fade in.
+		c = p
+		for i := range ts.tail {
+			x := ts.at(c + i)
+			w := ts.window[hop+i]
+			ts.tail[i] = [2]float64{x[0] * w, x[1] * w}
+		}
+	} else {
+		c = ts.similar(p)
+	}
+
+	for i := 0; i < hop; i++ {
+		x := ts.at(c + i)
+		w := ts.window[i]
+		ts.out = append(ts.out, [2]float64{
+			ts.tail[i][0] + x[0]*w,
+			ts.tail[i][1] + x[1]*w,
+		})
+	}
+	for i := 0; i < hop; i++ {
+		x := ts.at(c + hop + i)
+		w := ts.window[hop+i]
+		ts.tail[i] = [2]float64{x[0] * w, x[1] * w}
+	}
+
+	ts.prev = c
+	ts.next += ts.ratio * float64(hop)
+	ts.drop(min(int(ts.next)-ts.search, ts.prev+hop))
+}
+
+// similar returns the index near p whose following samples are
+// the most similar to the natural continuation of the previous frame.
+// Similarity is measured by normalized cross-correlation in mono.
+func (ts TimeStretcher) similar(p int) int {
+	hop := ts.frame / 2
+	target := ts.prev + hop
+	best, bestScore := p, math.Inf(-1)
+	for c := max(p-ts.search, ts.inBase, 0); c <= p+ts.search; c++ {
+		var corr, energy float64
+		for i := 0; i < hop; i += stretchCompareStride {
+			x, y := ts.at(c+i), ts.at(target+i)
+			xm, ym := x[0]+x[1], y[0]+y[1]
+			corr += xm * ym
+			energy += xm * xm
+		}
+		score := corr / math.Sqrt(energy+1e-9)
+		// On a tie, the index closer to p wins.
+		const eps = 1e-9
+		if score > bestScore+eps ||
+			score > bestScore-eps && abs(c-p) < abs(best-p) {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// drop discards source samples before index end.
+func (ts *TimeStretcher) drop(end int) {
+	n := end - ts.inBase
+	if n <= 0 {
+		return
+	}
+	n = min(n, len(ts.in))
+	ts.in = ts.in[:copy(ts.in, ts.in[n:])]
+	ts.inBase += n
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
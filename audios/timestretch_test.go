@@ -0,0 +1,112 @@
+package audios
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sineStreamer is a seekable sine wave of fixed length.
+type sineStreamer struct {
+	freq float64
+	len  int
+	pos  int
+}
+
+func (s *sineStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n = range samples {
+		if s.pos >= s.len {
+			return n, n > 0
+		}
+		v := 0.5 * math.Sin(2*math.Pi*s.freq*float64(s.pos)/float64(defaultSampleRate))
+		samples[n] = [2]float64{v, v}
+		s.pos++
+	}
+	return len(samples), true
+}
+
+func (s *sineStreamer) Err() error { return nil }
+
+func (s *sineStreamer) Seek(p int) error {
+	s.pos = p
+	return nil
+}
+
+// frequency estimates the frequency by counting rising zero crossings.
+// Edges fading in and out are left out.
+func frequency(samples [][2]float64) float64 {
+	edge := defaultSampleRate.N(50 * time.Millisecond)
+	samples = samples[edge : len(samples)-edge]
+	var first, last, count int
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1][0] < 0 && samples[i][0] >= 0 {
+			if count == 0 {
+				first = i
+			}
+			last = i
+			count++
+		}
+	}
+	return float64(count-1) * float64(defaultSampleRate) / float64(last-first)
+}
+
+func TestTimeStretcherLength(t *testing.T) {
+	const freq = 440
+	n := defaultSampleRate.N(2 * time.Second)
+	for _, ratio := range []float64{0.5, 0.75, 1, 1.5, 2} {
+		ts := NewTimeStretcher(&sineStreamer{freq: freq, len: n}, defaultSampleRate, 0)
+		ts.SetRatio(ratio)
+		out := streamAll(t, ts)
+
+		// Output lasts as long as the source at the ratio, give or take a frame.
+		want := float64(n) / ratio
+		if d := math.Abs(float64(len(out)) - want); d > float64(ts.frame) {
+			t.Errorf("ratio %v: got %d samples, want %.0f", ratio, len(out), want)
+		}
+		if p := ts.Position(); math.Abs(float64(p-n)) > float64(ts.frame) {
+			t.Errorf("ratio %v: got position %d at the end, want %d", ratio, p, n)
+		}
+
+		// Pitch is kept at any ratio.
+		if f := frequency(out); math.Abs(f-freq) > freq*0.01 {
+			t.Errorf("ratio %v: got frequency %.1fHz, want %dHz", ratio, f, freq)
+		}
+	}
+}
+
+func TestTimeStretcherPosition(t *testing.T) {
+	n := defaultSampleRate.N(2 * time.Second)
+	src := &sineStreamer{freq: 440, len: n}
+	ts := NewTimeStretcher(src, defaultSampleRate, 0)
+	ts.SetRatio(1.5)
+
+	// Position advances by the ratio, not by what the stretcher reads ahead.
+	buf := make([][2]float64, 1000)
+	for range 10 {
+		ts.Stream(buf)
+	}
+	if p, want := ts.Position(), int(10*1000*1.5); p != want {
+		t.Errorf("got position %d, want %d", p, want)
+	}
+
+	// Reset follows seeking the source.
+	const seek = 30000
+	src.Seek(seek)
+	ts.Reset(seek)
+	if p := ts.Position(); p != seek {
+		t.Errorf("got position %d after seek, want %d", p, seek)
+	}
+	out := streamAll(t, ts)
+	want := float64(n-seek) / 1.5
+	if d := math.Abs(float64(len(out)) - want); d > float64(ts.frame) {
+		t.Errorf("got %d samples after seek, want %.0f", len(out), want)
+	}
+
+	// Ratios out of range are ignored.
+	for _, ratio := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		ts.SetRatio(ratio)
+		if ts.Ratio() != 1.5 {
+			t.Errorf("ratio %v: got ratio %v, want unchanged", ratio, ts.Ratio())
+		}
+	}
+}
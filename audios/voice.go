@@ -0,0 +1,46 @@
+package audios
+
+import "github.com/gopxl/beep"
+
+// Voice is a sound being played by SoundPlayer.
+// Unlike fire-and-forget sounds, Voice can be stopped before it ends.
+type Voice struct {
+	streamer beep.Streamer
+	done     bool
+	out      Output
+}
+
+func newVoice(s beep.Streamer, out Output) *Voice { return &Voice{streamer: s, out: out} }
+
+func (v *Voice) Stream(samples [][2]float64) (n int, ok bool) {
+	if v.done {
+		return 0, false
+	}
+	n, ok = v.streamer.Stream(samples)
+	if !ok {
+		v.done = true
+	}
+	return n, ok
+}
+
+func (v *Voice) Err() error { return v.streamer.Err() }
+
+// Lock is required when modifying a streamer being played.
+// It is fine to call Stop at nil or finished Voice.
+func (v *Voice) Stop() {
+	if v == nil {
+		return
+	}
+	v.out.Lock()
+	v.done = true
+	v.out.Unlock()
+}
+
+func (v *Voice) IsDone() bool {
+	if v == nil {
+		return true
+	}
+	v.out.Lock()
+	defer v.out.Unlock()
+	return v.done
+}
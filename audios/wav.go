@@ -0,0 +1,235 @@
+package audios
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep"
+)
+
+// Format codes of WAV. Extensible format has its actual format code
+// at the first two bytes of the sub format GUID.
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatFloat      = 0x0003
+	wavFormatExtensible = 0xFFFE
+)
+
+// Format chunk is 40 bytes at most, even for extensible format.
+const maxWAVFormatSize = 1 << 10
+
+// wavDecoder supports what beep/wav does not: 32-bit integer,
+// 32-bit and 64-bit float samples, and extensible headers.
+// It also supports 8, 16 and 24-bit integer samples as beep/wav does.
+type wavDecoder struct {
+	r          io.Reader
+	rs         io.ReadSeeker // nil when the reader is not seekable.
+	dataStart  int64
+	float      bool
+	channels   int
+	sampleSize int // in bytes
+	blockAlign int
+	len        int
+	pos        int
+	buf        []byte
+	err        error
+}
+
+func decodeWAV(r io.Reader) (beep.StreamSeekCloser, beep.Format, error) {
+	d := &wavDecoder{r: r}
+	format, err := d.readHeader()
+	if err != nil {
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+		return nil, beep.Format{}, fmt.Errorf("wav: %w", err)
+	}
+	return d, format, nil
+}
+
+func (d *wavDecoder) readHeader() (beep.Format, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(d.r, riff[:]); err != nil {
+		return beep.Format{}, err
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return beep.Format{}, errors.New("missing RIFF WAVE header")
+	}
+
+	var (
+		format   beep.Format
+		hasFmt   bool
+		dataSize int64
+	)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(d.r, chunk[:]); err != nil {
+			if err == io.EOF {
+				return beep.Format{}, errors.New("missing data chunk")
+			}
+			return beep.Format{}, err
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		if id == "data" {
+			dataSize = size
+			break
+		}
+
+		padded := size + size%2 // Chunks are padded to even size.
+		if id != "fmt " {
+			// Other chunks, such as LIST, may be large. They are skipped
+			// without being held in memory.
+			if _, err := io.CopyN(io.Discard, d.r, padded); err != nil {
+				return beep.Format{}, err
+			}
+			continue
+		}
+		if size > maxWAVFormatSize {
+			return beep.Format{}, fmt.Errorf("format chunk too large: %d bytes", size)
+		}
+		body := make([]byte, padded)
+		if _, err := io.ReadFull(d.r, body); err != nil {
+			return beep.Format{}, err
+		}
+		var err error
+		if format, err = d.parseFormat(body[:size]); err != nil {
+			return beep.Format{}, err
+		}
+		hasFmt = true
+	}
+	if !hasFmt {
+		return beep.Format{}, errors.New("missing format chunk")
+	}
+
+	if rs, ok := d.r.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			d.rs = rs
+			d.dataStart = start
+		}
+	}
+	// Streamed WAV may not know its data size in advance.
+	if dataSize == 0 || dataSize == math.MaxUint32 {
+		d.len = math.MaxInt
+	} else {
+		d.len = int(dataSize) / d.blockAlign
+	}
+	return format, nil
+}
+
+func (d *wavDecoder) parseFormat(body []byte) (beep.Format, error) {
+	if len(body) < 16 {
+		return beep.Format{}, errors.New("format chunk too short")
+	}
+	code := binary.LittleEndian.Uint16(body[0:2])
+	channels := int(binary.LittleEndian.Uint16(body[2:4]))
+	sampleRate := int(binary.LittleEndian.Uint32(body[4:8]))
+	blockAlign := int(binary.LittleEndian.Uint16(body[12:14]))
+	bits := int(binary.LittleEndian.Uint16(body[14:16]))
+	if code == wavFormatExtensible {
+		if len(body) < 26 {
+			return beep.Format{}, errors.New("extensible format chunk too short")
+		}
+		code = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	switch {
+	case code == wavFormatPCM && (bits == 8 || bits == 16 || bits == 24 || bits == 32):
+	case code == wavFormatFloat && (bits == 32 || bits == 64):
+		d.float = true
+	default:
+		return beep.Format{}, fmt.Errorf("unsupported format code %#x with %d bits per sample", code, bits)
+	}
+	if channels < 1 {
+		return beep.Format{}, errors.New("invalid number of channels")
+	}
+	d.channels = channels
+	d.sampleSize = bits / 8
+	d.blockAlign = max(blockAlign, channels*d.sampleSize)
+	return beep.Format{
+		SampleRate:  beep.SampleRate(sampleRate),
+		NumChannels: channels,
+		Precision:   d.sampleSize,
+	}, nil
+}
+
+func (d *wavDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil || d.pos >= d.len {
+		return 0, false
+	}
+	count := min(len(samples), d.len-d.pos)
+	if size := count * d.blockAlign; len(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	read, err := io.ReadFull(d.r, d.buf[:count*d.blockAlign])
+	n = read / d.blockAlign
+	for i := range samples[:n] {
+		block := d.buf[i*d.blockAlign:]
+		l := d.sample(block)
+		r := l
+		if d.channels >= 2 {
+			r = d.sample(block[d.sampleSize:])
+		}
+		samples[i] = [2]float64{l, r}
+	}
+	d.pos += n
+
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF: // Data chunk may be truncated.
+		d.len = d.pos
+	default:
+		d.err = err
+	}
+	return n, n > 0
+}
+
+func (d wavDecoder) sample(b []byte) float64 {
+	switch {
+	case d.float && d.sampleSize == 4:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case d.float:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	switch d.sampleSize {
+	case 1: // 8-bit samples are unsigned.
+		return float64(int(b[0])-128) / (1 << 7)
+	case 2:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 3:
+		v := int32(b[0])<<8 | int32(b[1])<<16 | int32(b[2])<<24
+		return float64(v) / (1 << 31)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+func (d *wavDecoder) Err() error { return d.err }
+func (d *wavDecoder) Len() int   { return d.len }
+
+func (d *wavDecoder) Position() int { return d.pos }
+
+func (d *wavDecoder) Seek(p int) error {
+	if d.rs == nil {
+		return errors.New("wav: seek: resource is not io.Seeker")
+	}
+	if p < 0 || p > d.len {
+		return fmt.Errorf("wav: seek position %d out of range [0, %d]", p, d.len)
+	}
+	offset := d.dataStart + int64(p)*int64(d.blockAlign)
+	if _, err := d.rs.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("wav: seek: %w", err)
+	}
+	d.pos = p
+	return nil
+}
+
+func (d *wavDecoder) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package audios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// Stereo frames in every test WAV. Values are exact in 8-bit samples.
+var testWAVFrames = [][2]float64{{0.5, -0.25}, {-1, 0.75}, {0, 0.125}, {0.25, -0.5}}
+
+type wavChunk struct {
+	id   string
+	body []byte
+}
+
+// newWAV returns WAV data of given chunks in order.
+// Odd-sized chunks are padded as WAV requires.
+func newWAV(chunks ...wavChunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	for _, c := range chunks {
+		body.WriteString(c.id)
+		binary.Write(&body, binary.LittleEndian, uint32(len(c.body)))
+		body.Write(c.body)
+		if len(c.body)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(body.Len()))
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+func wavFmtChunk(code, channels, bits uint16, extensible bool) wavChunk {
+	var b bytes.Buffer
+	blockAlign := channels * bits / 8
+	tag := code
+	if extensible {
+		tag = wavFormatExtensible
+	}
+	for _, v := range []any{
+		tag, channels, uint32(defaultSampleRate),
+		uint32(defaultSampleRate) * uint32(blockAlign), blockAlign, bits,
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	if extensible {
+		// Size of extension, valid bits, channel mask, then sub format GUID.
+		for _, v := range []any{uint16(22), bits, uint32(0x3), code} {
+			binary.Write(&b, binary.LittleEndian, v)
+		}
+		b.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71})
+	}
+	return wavChunk{"fmt ", b.Bytes()}
+}
+
+func wavDataChunk(code, bits uint16, frames [][2]float64) wavChunk {
+	var b bytes.Buffer
+	for _, f := range frames {
+		for _, v := range f {
+			switch {
+			case code == wavFormatFloat && bits == 32:
+				binary.Write(&b, binary.LittleEndian, float32(v))
+			case code == wavFormatFloat:
+				binary.Write(&b, binary.LittleEndian, v)
+			case bits == 8:
+				b.WriteByte(byte(int(v*(1<<7)) + 128))
+			case bits == 16:
+				binary.Write(&b, binary.LittleEndian, int16(max(v*(1<<15), math.MinInt16)))
+			case bits == 24:
+				x := int32(v * (1 << 23))
+				b.Write([]byte{byte(x), byte(x >> 8), byte(x >> 16)})
+			default:
+				binary.Write(&b, binary.LittleEndian, int32(max(v*(1<<31), math.MinInt32)))
+			}
+		}
+	}
+	return wavChunk{"data", b.Bytes()}
+}
+
+func streamAll(t *testing.T, d interface {
+	Stream([][2]float64) (int, bool)
+}) [][2]float64 {
+	t.Helper()
+	var all [][2]float64
+	buf := make([][2]float64, 3) // Small buffer to stream in several calls.
+	for range 1 << 20 {
+		n, ok := d.Stream(buf)
+		all = append(all, buf[:n]...)
+		if !ok {
+			return all
+		}
+	}
+	t.Fatal("stream does not end")
+	return nil
+}
+
+func TestDecodeWAVFormats(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		code       uint16
+		bits       uint16
+		extensible bool
+	}{
+		{"8-bit", wavFormatPCM, 8, false},
+		{"16-bit", wavFormatPCM, 16, false},
+		{"24-bit", wavFormatPCM, 24, false},
+		{"32-bit", wavFormatPCM, 32, false},
+		{"float32", wavFormatFloat, 32, false},
+		{"float64", wavFormatFloat, 64, false},
+		{"extensible 24-bit", wavFormatPCM, 24, true},
+		{"extensible float32", wavFormatFloat, 32, true},
+	} {
+		data := newWAV(
+			wavFmtChunk(tc.code, 2, tc.bits, tc.extensible),
+			wavDataChunk(tc.code, tc.bits, testWAVFrames),
+		)
+		d, format, err := decodeWAV(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if format.SampleRate != defaultSampleRate || format.NumChannels != 2 || format.Precision != int(tc.bits/8) {
+			t.Errorf("%s: got format %+v", tc.name, format)
+		}
+		if d.Len() != len(testWAVFrames) {
+			t.Errorf("%s: got length %d, want %d", tc.name, d.Len(), len(testWAVFrames))
+		}
+		got := streamAll(t, d)
+		if len(got) != len(testWAVFrames) {
+			t.Errorf("%s: got %d frames, want %d", tc.name, len(got), len(testWAVFrames))
+			continue
+		}
+		for i, f := range testWAVFrames {
+			if got[i] != f {
+				t.Errorf("%s: frame %d: got %v, want %v", tc.name, i, got[i], f)
+			}
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}
+
+// Mono samples go to both channels.
+func TestDecodeWAVMono(t *testing.T) {
+	frames := [][2]float64{{0.5, 0}, {-0.25, 0}}
+	data := wavDataChunk(wavFormatPCM, 16, frames)
+	fmtChunk := wavFmtChunk(wavFormatPCM, 1, 16, false)
+	// Frames are written in stereo; mono data takes every sample in turn.
+	d, _, err := decodeWAV(bytes.NewReader(newWAV(fmtChunk, data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := streamAll(t, d)
+	want := [][2]float64{{0.5, 0.5}, {0, 0}, {-0.25, -0.25}, {0, 0}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("frame %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// Chunks other than fmt and data are skipped, including padded ones.
+func TestDecodeWAVOddChunks(t *testing.T) {
+	data := newWAV(
+		wavChunk{"LIST", []byte("abc")},
+		wavFmtChunk(wavFormatPCM, 2, 16, false),
+		wavChunk{"fact", []byte{1}},
+		wavDataChunk(wavFormatPCM, 16, testWAVFrames),
+	)
+	d, _, err := decodeWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := streamAll(t, d); len(got) != len(testWAVFrames) || got[0] != testWAVFrames[0] {
+		t.Errorf("got %v, want %v", got, testWAVFrames)
+	}
+}
+
+// Data shorter than its chunk size ends the stream with no error.
+func TestDecodeWAVTruncated(t *testing.T) {
+	data := newWAV(
+		wavFmtChunk(wavFormatPCM, 2, 16, false),
+		wavDataChunk(wavFormatPCM, 16, testWAVFrames),
+	)
+	// Cut the last frame in the middle.
+	data = data[:len(data)-2]
+	d, _, err := decodeWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := streamAll(t, d)
+	if want := len(testWAVFrames) - 1; len(got) != want || d.Len() != want {
+		t.Errorf("got %d frames and length %d, want %d", len(got), d.Len(), want)
+	}
+	if err := d.Err(); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
+
+func TestDecodeWAVSeek(t *testing.T) {
+	data := newWAV(
+		wavChunk{"LIST", []byte("abc")},
+		wavFmtChunk(wavFormatPCM, 2, 24, false),
+		wavDataChunk(wavFormatPCM, 24, testWAVFrames),
+	)
+	d, _, err := decodeWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []int{2, 0, 3, len(testWAVFrames)} {
+		if err := d.Seek(p); err != nil {
+			t.Fatalf("seek %d: %v", p, err)
+		}
+		if d.Position() != p {
+			t.Errorf("seek %d: got position %d", p, d.Position())
+		}
+		got := streamAll(t, d)
+		if len(got) != len(testWAVFrames)-p {
+			t.Errorf("seek %d: got %d frames, want %d", p, len(got), len(testWAVFrames)-p)
+		} else if len(got) > 0 && got[0] != testWAVFrames[p] {
+			t.Errorf("seek %d: got %v, want %v", p, got[0], testWAVFrames[p])
+		}
+	}
+	for _, p := range []int{-1, len(testWAVFrames) + 1} {
+		if err := d.Seek(p); err == nil {
+			t.Errorf("seek %d: got no error", p)
+		}
+	}
+
+	// Non-seekable reader decodes, but does not seek.
+	d, _, err = decodeWAV(struct{ io.Reader }{bytes.NewReader(data)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Seek(0); err == nil {
+		t.Error("seek at non-seekable reader: got no error")
+	}
+}
+
+func TestDecodeWAVErrors(t *testing.T) {
+	fmtChunk := wavFmtChunk(wavFormatPCM, 2, 16, false)
+	dataChunk := wavDataChunk(wavFormatPCM, 16, testWAVFrames)
+	// A chunk claiming 2GB should be skipped, not allocated.
+	huge := newWAV(fmtChunk)
+	huge = append(huge, "LIST\xff\xff\xff\x7fabc"...)
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not RIFF", []byte("RIFX\x00\x00\x00\x00WAVE"), "missing RIFF WAVE"},
+		{"no format", newWAV(dataChunk), "missing format"},
+		{"no data", newWAV(fmtChunk), "missing data"},
+		{"ADPCM", newWAV(wavFmtChunk(0x0002, 2, 4, false), dataChunk), "unsupported format"},
+		{"short format", newWAV(wavChunk{"fmt ", fmtChunk.body[:14]}, dataChunk), "too short"},
+		{"large format", newWAV(wavChunk{"fmt ", make([]byte, maxWAVFormatSize+2)}, dataChunk), "too large"},
+		{"huge chunk", huge, "EOF"},
+	} {
+		_, _, err := decodeWAV(bytes.NewReader(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
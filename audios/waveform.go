@@ -0,0 +1,49 @@
+package audios
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// PeakBinDuration is the time span of a peak.
+const PeakBinDuration = 50 * time.Millisecond
+
+// PeakRecorder passes samples through while recording peaks for a waveform.
+// It is for analyzing music along with loudness, without decoding twice.
+type PeakRecorder struct {
+	s       beep.Streamer
+	binSize int
+	count   int
+	peak    float64
+	peaks   []float64
+}
+
+func NewPeakRecorder(s beep.Streamer, sr beep.SampleRate) *PeakRecorder {
+	return &PeakRecorder{s: s, binSize: max(1, sr.N(PeakBinDuration))}
+}
+
+func (pr *PeakRecorder) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = pr.s.Stream(samples)
+	for _, s := range samples[:n] {
+		pr.peak = math.Max(pr.peak, math.Max(math.Abs(s[0]), math.Abs(s[1])))
+		pr.count++
+		if pr.count == pr.binSize {
+			pr.peaks = append(pr.peaks, pr.peak)
+			pr.count, pr.peak = 0, 0
+		}
+	}
+	return n, ok
+}
+
+func (pr *PeakRecorder) Err() error { return pr.s.Err() }
+
+// Peaks returns absolute peaks of each bin, including the last partial bin.
+func (pr PeakRecorder) Peaks() []float64 {
+	peaks := append([]float64(nil), pr.peaks...)
+	if pr.count > 0 {
+		peaks = append(peaks, pr.peak)
+	}
+	return peaks
+}
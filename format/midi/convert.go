@@ -0,0 +1,255 @@
+package midi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hndada/gosu/format/osu"
+)
+
+// Strategies of mapping notes to lanes.
+const (
+	StrategyPitchRange = "pitch-range" // Pitch range is split evenly into lanes.
+	StrategyTrack      = "track"       // Each track goes a lane, in order of tracks.
+	StrategyDensity    = "density"     // Pitch range, then notes are thinned out.
+)
+
+type ConvertOptions struct {
+	KeyCount          int
+	Strategy          string
+	IncludePercussion bool
+
+	// A note held at least this many beats goes a long note.
+	LongNoteBeats float64
+
+	// Density-limited strategy only.
+	MaxChordSize int     // Notes at the same time; the loudest ones are kept.
+	MinInterval  float64 // Milliseconds between notes in a lane.
+}
+
+func NewConvertOptions(keyCount int) ConvertOptions {
+	return ConvertOptions{
+		KeyCount:      keyCount,
+		Strategy:      StrategyPitchRange,
+		LongNoteBeats: 1,
+		MaxChordSize:  2,
+		MinInterval:   100,
+	}
+}
+
+// ToMania converts MIDI to osu!mania chart, which is then loaded
+// as a piano chart and can be saved as .osu by osu.Format.Encode.
+// The chart has no music; notes are to be played along with
+// the MIDI rendered separately, or in silence.
+func (f Format) ToMania(opts ConvertOptions) (*osu.Format, error) {
+	keyCount := opts.KeyCount
+	if keyCount < 1 {
+		return nil, fmt.Errorf("invalid key count: %d", keyCount)
+	}
+
+	var ns []Note
+	for _, n := range f.Notes {
+		if n.Channel == PercussionChannel && !opts.IncludePercussion {
+			continue
+		}
+		ns = append(ns, n)
+	}
+	if len(ns) == 0 {
+		return nil, fmt.Errorf("no notes to convert")
+	}
+
+	var lanes []int
+	switch opts.Strategy {
+	case StrategyPitchRange:
+		lanes = pitchRangeLanes(ns, keyCount)
+	case StrategyTrack:
+		lanes = trackLanes(ns, keyCount)
+	case StrategyDensity:
+		ns = limitChords(ns, opts.MaxChordSize)
+		lanes = pitchRangeLanes(ns, keyCount)
+	default:
+		return nil, fmt.Errorf("unknown strategy: %s", opts.Strategy)
+	}
+
+	m := &osu.Format{
+		FormatVersion: 14,
+		General: osu.General{
+			AudioFilename:   "virtual", // No music.
+			PreviewTime:     -1,
+			SampleSet:       "Normal",
+			StackLeniency:   0.7,
+			Mode:            osu.ModeMania,
+			OverlayPosition: "NoChange",
+		},
+		Editor: osu.Editor{BeatDivisor: 4, TimelineZoom: 1},
+		Metadata: osu.Metadata{
+			Title:   f.title(),
+			Version: fmt.Sprintf("%dK %s", keyCount, opts.Strategy),
+			Tags:    []string{"midi"},
+		},
+		Difficulty: osu.Difficulty{
+			HPDrainRate:       8,
+			CircleSize:        float64(keyCount),
+			OverallDifficulty: 8,
+			ApproachRate:      5,
+			SliderMultiplier:  1.4,
+			SliderTickRate:    1,
+		},
+		TimingPoints: f.timingPoints(),
+	}
+
+	ends := make([]float64, keyCount) // Time when each lane gets free.
+	for i := range ends {
+		ends[i] = math.Inf(-1)
+	}
+	for i, n := range ns {
+		lane, ok := freeLane(ends, lanes[i], n.Time)
+		if !ok {
+			continue // Every lane is held.
+		}
+		if opts.Strategy == StrategyDensity && n.Time-ends[lane] < opts.MinInterval {
+			continue
+		}
+
+		ho := osu.HitObject{
+			X:        (lane*512 + 256) / keyCount,
+			Y:        192,
+			Time:     int(math.Round(n.Time)),
+			NoteType: osu.HitTypeNote,
+		}
+		end := n.Time
+		// Tail is released a bit earlier, so that
+		// legato notes do not overlap with the next ones.
+		beatLength := f.TempoAt(n.Tick).BeatLength()
+		if opts.LongNoteBeats > 0 && n.Duration >= opts.LongNoteBeats*beatLength {
+			if e := n.Time + n.Duration - beatLength/8; e > n.Time {
+				end = e
+				ho.NoteType = osu.HitTypeHoldNote
+				ho.EndTime = int(math.Round(end))
+			}
+		}
+		ends[lane] = end
+		m.HitObjects = append(m.HitObjects, ho)
+	}
+	return m, nil
+}
+
+func (f Format) title() string {
+	for _, name := range f.TrackNames {
+		if name != "" {
+			return name
+		}
+	}
+	return "MIDI"
+}
+
+// Both tempo and time signature changes make uninherited timing points.
+// Meter is in quarter notes per measure, as osu! does.
+func (f Format) timingPoints() []osu.TimingPoint {
+	var ticks []int
+	for _, t := range f.Tempos {
+		ticks = append(ticks, t.Tick)
+	}
+	for _, ts := range f.TimeSignatures {
+		ticks = append(ticks, ts.Tick)
+	}
+	sort.Ints(ticks)
+
+	var tps []osu.TimingPoint
+	for i, tick := range ticks {
+		if i > 0 && tick == ticks[i-1] {
+			continue
+		}
+		ts := f.TimeSignatureAt(tick)
+		meter := ts.Numerator
+		if ts.Denominator > 0 {
+			meter = ts.Numerator * 4 / ts.Denominator
+		}
+		tps = append(tps, osu.TimingPoint{
+			Time:        int(math.Round(f.TickToTime(tick))),
+			BeatLength:  f.TempoAt(tick).BeatLength(),
+			Meter:       max(1, meter),
+			SampleSet:   osu.SampleSetNormal,
+			Volume:      100,
+			Uninherited: true,
+		})
+	}
+	return tps
+}
+
+// pitchRangeLanes splits the range of pitches into lanes evenly,
+// so that higher pitches go to the right as on a keyboard.
+func pitchRangeLanes(ns []Note, keyCount int) []int {
+	lo, hi := ns[0].Key, ns[0].Key
+	for _, n := range ns {
+		lo = min(lo, n.Key)
+		hi = max(hi, n.Key)
+	}
+	span := hi - lo + 1
+	lanes := make([]int, len(ns))
+	for i, n := range ns {
+		lanes[i] = (n.Key - lo) * keyCount / span
+	}
+	return lanes
+}
+
+// trackLanes maps tracks to lanes in order. Tracks more than
+// lanes wrap around to the first lane.
+func trackLanes(ns []Note, keyCount int) []int {
+	ranks := make(map[int]int)
+	var tracks []int
+	for _, n := range ns {
+		if _, ok := ranks[n.Track]; !ok {
+			ranks[n.Track] = 0
+			tracks = append(tracks, n.Track)
+		}
+	}
+	sort.Ints(tracks)
+	for i, tr := range tracks {
+		ranks[tr] = i
+	}
+
+	lanes := make([]int, len(ns))
+	for i, n := range ns {
+		lanes[i] = ranks[n.Track] % keyCount
+	}
+	return lanes
+}
+
+// limitChords keeps the loudest notes among notes at the same tick.
+// Notes are supposed to be sorted by tick.
+func limitChords(ns []Note, maxSize int) []Note {
+	if maxSize < 1 {
+		return ns
+	}
+	var kept []Note
+	for i := 0; i < len(ns); {
+		j := i
+		for j < len(ns) && ns[j].Tick == ns[i].Tick {
+			j++
+		}
+		chord := append([]Note(nil), ns[i:j]...)
+		if len(chord) > maxSize {
+			sort.SliceStable(chord, func(a, b int) bool { return chord[a].Velocity > chord[b].Velocity })
+			chord = chord[:maxSize]
+			sort.SliceStable(chord, func(a, b int) bool { return chord[a].Key < chord[b].Key })
+		}
+		kept = append(kept, chord...)
+		i = j
+	}
+	return kept
+}
+
+// freeLane returns the lane nearest to the given one
+// which is not occupied at the given time.
+func freeLane(ends []float64, lane int, time float64) (int, bool) {
+	for d := 0; d < len(ends); d++ {
+		for _, l := range []int{lane + d, lane - d} {
+			if l >= 0 && l < len(ends) && ends[l] < time {
+				return l, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,339 @@
+package midi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Format is a Standard MIDI File, reduced to what a chart needs:
+// tempo map, time signatures and notes. Times are in milliseconds.
+// Format 0 and 1 are supported.
+type Format struct {
+	FormatType     int
+	Division       int // Ticks per quarter note.
+	TrackNames     []string
+	Tempos         []Tempo         // Sorted by tick. The first one is at tick 0.
+	TimeSignatures []TimeSignature // Sorted by tick. The first one is at tick 0.
+	Notes          []Note          // Sorted by time, then by key.
+}
+
+type Tempo struct {
+	Tick                   int
+	Time                   float64
+	MicrosecondsPerQuarter int
+}
+
+// BeatLength returns the duration of a quarter note in milliseconds.
+func (t Tempo) BeatLength() float64 { return float64(t.MicrosecondsPerQuarter) / 1000 }
+
+type TimeSignature struct {
+	Tick        int
+	Time        float64
+	Numerator   int
+	Denominator int
+}
+
+// Note is a pair of note on and note off.
+type Note struct {
+	Track    int
+	Channel  int // 0-based; channel 9 is for percussion in General MIDI.
+	Key      int // 60 is middle C.
+	Velocity int
+	Tick     int
+	EndTick  int
+	Time     float64
+	Duration float64
+}
+
+// PercussionChannel is the 10th channel in General MIDI.
+const PercussionChannel = 9
+
+const defaultMicrosecondsPerQuarter = 500000 // 120 BPM
+
+func NewFormat(data []byte) (*Format, error) {
+	r := bytes.NewReader(data)
+	id, body, err := readChunk(r)
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	if id != "MThd" || len(body) < 6 {
+		return nil, errors.New("not a standard MIDI file")
+	}
+
+	f := &Format{FormatType: int(binary.BigEndian.Uint16(body[0:2]))}
+	trackCount := int(binary.BigEndian.Uint16(body[2:4]))
+	division := binary.BigEndian.Uint16(body[4:6])
+	if f.FormatType > 1 {
+		return nil, fmt.Errorf("unsupported MIDI format type: %d", f.FormatType)
+	}
+	// SMPTE division has no tempo; it is not used in music arrangements.
+	if division&0x8000 != 0 || division == 0 {
+		return nil, fmt.Errorf("unsupported division: %#x", division)
+	}
+	f.Division = int(division)
+
+	var tracks []track
+	for len(tracks) < trackCount {
+		id, body, err := readChunk(r)
+		if err == io.EOF {
+			break // Some files have fewer tracks than declared.
+		}
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", len(tracks), err)
+		}
+		if id != "MTrk" {
+			continue // Unknown chunks are to be skipped.
+		}
+		tr, err := newTrack(body, len(tracks))
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", len(tracks), err)
+		}
+		tracks = append(tracks, tr)
+	}
+
+	f.setTimeline(tracks)
+	for _, tr := range tracks {
+		f.TrackNames = append(f.TrackNames, tr.name)
+		f.Notes = append(f.Notes, tr.notes...)
+	}
+	for i, n := range f.Notes {
+		f.Notes[i].Time = f.TickToTime(n.Tick)
+		f.Notes[i].Duration = f.TickToTime(n.EndTick) - f.Notes[i].Time
+	}
+	sort.SliceStable(f.Notes, func(i, j int) bool {
+		if f.Notes[i].Tick != f.Notes[j].Tick {
+			return f.Notes[i].Tick < f.Notes[j].Tick
+		}
+		return f.Notes[i].Key < f.Notes[j].Key
+	})
+	return f, nil
+}
+
+func readChunk(r *bytes.Reader) (id string, body []byte, err error) {
+	var h [8]byte
+	if _, err = io.ReadFull(r, h[:]); err != nil {
+		return
+	}
+	size := binary.BigEndian.Uint32(h[4:])
+	if int64(size) > int64(r.Len()) {
+		return "", nil, fmt.Errorf("chunk %q is truncated", h[:4])
+	}
+	body = make([]byte, size)
+	_, err = io.ReadFull(r, body)
+	return string(h[:4]), body, err
+}
+
+// Tempo and time signature events may be in any track,
+// though they are usually in the first track.
+type track struct {
+	name     string
+	notes    []Note
+	tempos   []Tempo
+	timeSigs []TimeSignature
+}
+
+func newTrack(data []byte, index int) (tr track, err error) {
+	type noteKey struct{ channel, key int }
+	opens := make(map[noteKey][]Note) // Overlapped same notes are closed in order.
+	closeNote := func(k noteKey, tick int) {
+		ns := opens[k]
+		if len(ns) == 0 {
+			return
+		}
+		n := ns[0]
+		n.EndTick = tick
+		tr.notes = append(tr.notes, n)
+		opens[k] = ns[1:]
+	}
+
+	r := bytes.NewReader(data)
+	var tick int
+	var status byte // for running status
+	for r.Len() > 0 {
+		delta, err := readVarInt(r)
+		if err != nil {
+			return tr, err
+		}
+		tick += delta
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return tr, err
+		}
+		if b < 0x80 {
+			if status == 0 {
+				return tr, errors.New("data byte without status")
+			}
+			r.UnreadByte()
+			b = status
+		}
+
+		switch {
+		case b == 0xFF: // Meta event
+			kind, err := r.ReadByte()
+			if err != nil {
+				return tr, err
+			}
+			body, err := readVarBytes(r)
+			if err != nil {
+				return tr, err
+			}
+			switch kind {
+			case 0x03: // Track name
+				if tr.name == "" {
+					tr.name = string(body)
+				}
+			case 0x51: // Tempo
+				if len(body) >= 3 {
+					mpq := int(body[0])<<16 | int(body[1])<<8 | int(body[2])
+					tr.tempos = append(tr.tempos, Tempo{Tick: tick, MicrosecondsPerQuarter: mpq})
+				}
+			case 0x58: // Time signature
+				if len(body) >= 2 {
+					ts := TimeSignature{Tick: tick, Numerator: int(body[0]), Denominator: 1 << body[1]}
+					tr.timeSigs = append(tr.timeSigs, ts)
+				}
+			case 0x2F: // End of track
+				r.Seek(0, io.SeekEnd)
+			}
+		case b == 0xF0 || b == 0xF7: // System exclusive
+			if _, err := readVarBytes(r); err != nil {
+				return tr, err
+			}
+		case b >= 0xF0:
+			return tr, fmt.Errorf("unsupported status: %#x", b)
+		default: // Channel event
+			status = b
+			var args [2]byte
+			n := 2
+			if kind := b & 0xF0; kind == 0xC0 || kind == 0xD0 {
+				n = 1
+			}
+			if _, err := io.ReadFull(r, args[:n]); err != nil {
+				return tr, err
+			}
+
+			k := noteKey{int(b & 0x0F), int(args[0])}
+			switch b & 0xF0 {
+			case 0x90:
+				if args[1] > 0 {
+					n := Note{Track: index, Channel: k.channel, Key: k.key, Velocity: int(args[1]), Tick: tick}
+					opens[k] = append(opens[k], n)
+					break
+				}
+				closeNote(k, tick) // Note on with zero velocity works as note off.
+			case 0x80:
+				closeNote(k, tick)
+			}
+		}
+	}
+
+	// Notes left open are closed at the end of the track.
+	for k := range opens {
+		for len(opens[k]) > 0 {
+			closeNote(k, tick)
+		}
+	}
+	return tr, nil
+}
+
+func readVarInt(r *bytes.Reader) (int, error) {
+	var v int
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("variable-length quantity is too long")
+}
+
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, errors.New("event is truncated")
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+// setTimeline merges tempo and time signature events of all tracks.
+// A later event at the same tick overrides the earlier one.
+func (f *Format) setTimeline(tracks []track) {
+	tempos := []Tempo{{MicrosecondsPerQuarter: defaultMicrosecondsPerQuarter}}
+	timeSigs := []TimeSignature{{Numerator: 4, Denominator: 4}}
+	for _, tr := range tracks {
+		tempos = append(tempos, tr.tempos...)
+		timeSigs = append(timeSigs, tr.timeSigs...)
+	}
+	sort.SliceStable(tempos, func(i, j int) bool { return tempos[i].Tick < tempos[j].Tick })
+	sort.SliceStable(timeSigs, func(i, j int) bool { return timeSigs[i].Tick < timeSigs[j].Tick })
+
+	for _, t := range tempos {
+		if last := len(f.Tempos) - 1; last >= 0 && f.Tempos[last].Tick == t.Tick {
+			f.Tempos[last] = t
+			continue
+		}
+		f.Tempos = append(f.Tempos, t)
+	}
+	for i := 1; i < len(f.Tempos); i++ {
+		prev := f.Tempos[i-1]
+		ticks := float64(f.Tempos[i].Tick - prev.Tick)
+		f.Tempos[i].Time = prev.Time + ticks*prev.BeatLength()/float64(f.Division)
+	}
+
+	for _, ts := range timeSigs {
+		ts.Time = f.TickToTime(ts.Tick)
+		if last := len(f.TimeSignatures) - 1; last >= 0 && f.TimeSignatures[last].Tick == ts.Tick {
+			f.TimeSignatures[last] = ts
+			continue
+		}
+		f.TimeSignatures = append(f.TimeSignatures, ts)
+	}
+}
+
+// TickToTime converts ticks to milliseconds by the tempo map.
+// Tempos before the tick should have their times set.
+func (f Format) TickToTime(tick int) float64 {
+	t := f.TempoAt(tick)
+	return t.Time + float64(tick-t.Tick)*t.BeatLength()/float64(f.Division)
+}
+
+// TempoAt returns the tempo in effect at the tick.
+func (f Format) TempoAt(tick int) Tempo {
+	i := sort.Search(len(f.Tempos), func(i int) bool { return f.Tempos[i].Tick > tick })
+	if i == 0 {
+		return Tempo{MicrosecondsPerQuarter: defaultMicrosecondsPerQuarter}
+	}
+	return f.Tempos[i-1]
+}
+
+// TimeSignatureAt returns the time signature in effect at the tick.
+func (f Format) TimeSignatureAt(tick int) TimeSignature {
+	i := sort.Search(len(f.TimeSignatures), func(i int) bool { return f.TimeSignatures[i].Tick > tick })
+	if i == 0 {
+		return TimeSignature{Numerator: 4, Denominator: 4}
+	}
+	return f.TimeSignatures[i-1]
+}
+
+// Duration returns the end time of the last note.
+func (f Format) Duration() int {
+	var d float64
+	for _, n := range f.Notes {
+		d = max(d, n.Time+n.Duration)
+	}
+	return int(d)
+}
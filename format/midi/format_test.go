@@ -0,0 +1,109 @@
+package midi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/hndada/gosu/format/osu"
+)
+
+const testDivision = 480
+
+func chunk(id string, body []byte) []byte {
+	b := []byte(id)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+// Tempo goes twice faster at the third beat, then time signature goes 3/4.
+// Notes are written with running status and with zero-velocity note on.
+func newTestMIDI() []byte {
+	header := []byte{0, 1, 0, 2, testDivision >> 8, testDivision & 0xFF}
+	tempo := []byte{
+		0x00, 0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20, // 500000: 120 BPM
+		0x87, 0x40, 0xFF, 0x51, 0x03, 0x03, 0xD0, 0x90, // 960 ticks later, 250000: 240 BPM
+		0x00, 0xFF, 0x58, 0x04, 0x03, 0x02, 0x18, 0x08, // 3/4
+		0x00, 0xFF, 0x2F, 0x00,
+	}
+	notes := []byte{
+		0x00, 0xFF, 0x03, 0x04, 'l', 'e', 'a', 'd',
+		0x00, 0x90, 60, 100, // C4 on at 0
+		0x00, 72, 80, // C5 on at 0, running status
+		0x83, 0x60, 60, 0, // C4 off at 480
+		0x87, 0x40, 72, 0, // C5 off at 1440
+		0x00, 0x99, 36, 100, // Kick on percussion channel
+		0x10, 0x89, 36, 0,
+		0x00, 0xFF, 0x2F, 0x00,
+	}
+	var b bytes.Buffer
+	b.Write(chunk("MThd", header))
+	b.Write(chunk("MTrk", tempo))
+	b.Write(chunk("MTrk", notes))
+	return b.Bytes()
+}
+
+func TestNewFormat(t *testing.T) {
+	f, err := NewFormat(newTestMIDI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Tempos) != 2 || f.Tempos[1].Time != 1000 {
+		t.Fatalf("tempos: %+v", f.Tempos)
+	}
+	if ts := f.TimeSignatureAt(960); ts.Numerator != 3 || ts.Denominator != 4 {
+		t.Errorf("time signature: %+v", ts)
+	}
+	if f.TrackNames[1] != "lead" {
+		t.Errorf("track name: %q", f.TrackNames[1])
+	}
+
+	// C5 is held for 2 beats at 120 BPM, then 1 beat at 240 BPM.
+	want := []struct {
+		key            int
+		time, duration float64
+	}{{60, 0, 500}, {72, 0, 1250}, {36, 1250, 250.0 / 30}}
+	if len(f.Notes) != len(want) {
+		t.Fatalf("got %d notes, want %d", len(f.Notes), len(want))
+	}
+	for i, w := range want {
+		n := f.Notes[i]
+		if n.Key != w.key || n.Time != w.time || math.Abs(n.Duration-w.duration) > 1e-9 {
+			t.Errorf("note %d: got %+v, want %+v", i, n, w)
+		}
+	}
+}
+
+func TestToMania(t *testing.T) {
+	f, err := NewFormat(newTestMIDI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := f.ToMania(NewConvertOptions(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.HitObjects) != 2 {
+		t.Fatalf("got %d hit objects, want 2 without percussion", len(m.HitObjects))
+	}
+	low, high := m.HitObjects[0], m.HitObjects[1]
+	if low.Column(4) != 0 || high.Column(4) != 3 {
+		t.Errorf("columns: got %d and %d, want 0 and 3", low.Column(4), high.Column(4))
+	}
+	if low.NoteType != osu.HitTypeHoldNote || high.NoteType != osu.HitTypeHoldNote {
+		t.Errorf("notes held for a beat or longer should be long notes")
+	}
+	if len(m.TimingPoints) != 2 || m.TimingPoints[1].Meter != 3 {
+		t.Errorf("timing points: %+v", m.TimingPoints)
+	}
+
+	// Converted chart is read back from .osu.
+	m2, err := osu.NewFormat(m.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m2.HitObjects) != 2 || m2.HitObjects[1].EndTime != high.EndTime {
+		t.Errorf("encoded hit objects: %+v", m2.HitObjects)
+	}
+}
@@ -211,6 +211,34 @@ func (f Format) IsAuto() bool {
 	return f.ReplayData[len(f.ReplayData)-1].Z == 0
 }
 
+// Key mods of osu!mania, in ModsBits.
+const (
+	modKey4 = 1 << 15
+	modKey5 = 1 << 16
+	modKey6 = 1 << 17
+	modKey7 = 1 << 18
+	modKey8 = 1 << 19
+	modKey9 = 1 << 24
+	modKey1 = 1 << 26
+	modKey3 = 1 << 27
+	modKey2 = 1 << 28
+)
+
+// KeyCount returns the key count set by key mods, with which
+// osu! converts the chart. It returns 0 when no key mod is on.
+func (f Format) KeyCount() int {
+	for _, m := range []struct{ bit, keyCount int32 }{
+		{modKey1, 1}, {modKey2, 2}, {modKey3, 3},
+		{modKey4, 4}, {modKey5, 5}, {modKey6, 6},
+		{modKey7, 7}, {modKey8, 8}, {modKey9, 9},
+	} {
+		if f.ModsBits&m.bit != 0 {
+			return int(m.keyCount)
+		}
+	}
+	return 0
+}
+
 // func (f Format) MD5() (hash string, err error) {
 // 	var hashBytes []byte
 // 	hashBytes, err = hex.DecodeString(f.BeatmapMD5)
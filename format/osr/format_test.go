@@ -52,3 +52,21 @@ func TestMD5(t *testing.T) {
 		// fmt.Println(r.MD5())
 	}
 }
+
+func TestKeyCount(t *testing.T) {
+	for _, tc := range []struct {
+		mods int32
+		want int
+	}{
+		{0, 0},
+		{0x240, 0}, // Nightcore
+		{modKey4, 4},
+		{modKey7 | 0x40, 7},
+		{modKey9, 9},
+		{modKey1, 1},
+	} {
+		if got := (Format{ModsBits: tc.mods}).KeyCount(); got != tc.want {
+			t.Errorf("mods %#x: got %d, want %d", tc.mods, got, tc.want)
+		}
+	}
+}
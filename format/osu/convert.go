@@ -0,0 +1,199 @@
+package osu
+
+import (
+	"math"
+	"sort"
+)
+
+// Converters generate a chart of other mode from osu!standard chart,
+// in a similar way to osu!'s own converters.
+// Both converters return a new Format; the given Format is left untouched.
+
+// timingAt returns beat length and beat scale at the given time.
+// TimingPoints are supposed to be sorted by time.
+func (f Format) timingAt(time int) (beatLength, beatScale float64) {
+	beatLength, beatScale = 500, 1 // 120 BPM when there is no timing point.
+	var found bool
+	for _, tp := range f.TimingPoints {
+		if tp.Time > time {
+			// Uses the first uninherited one when the time is before it.
+			if !found && tp.Uninherited {
+				beatLength = tp.BeatLength
+			}
+			if found || tp.Uninherited {
+				break
+			}
+			continue
+		}
+		if tp.Uninherited {
+			beatLength = tp.BeatLength
+			beatScale = 1
+			found = true
+		} else {
+			beatScale = tp.BeatLengthScale()
+		}
+	}
+	return
+}
+
+// SliderSpeed returns speed of slider at the given time in osu! pixels per millisecond.
+func (f Format) SliderSpeed(time int) float64 {
+	beatLength, beatScale := f.timingAt(time)
+	bpm := 60000 / beatLength
+	return (bpm / 60000) * beatScale * (f.SliderMultiplier * 100)
+}
+
+func (f Format) sortedCopy() *Format {
+	f2 := f
+	f2.TimingPoints = make([]TimingPoint, len(f.TimingPoints))
+	copy(f2.TimingPoints, f.TimingPoints)
+	sort.SliceStable(f2.TimingPoints, func(i, j int) bool {
+		return f2.TimingPoints[i].Time < f2.TimingPoints[j].Time
+	})
+	f2.HitObjects = make([]HitObject, len(f.HitObjects))
+	copy(f2.HitObjects, f.HitObjects)
+	sort.SliceStable(f2.HitObjects, func(i, j int) bool {
+		return f2.HitObjects[i].Time < f2.HitObjects[j].Time
+	})
+	return &f2
+}
+
+// ToTaiko converts osu!standard chart to osu!taiko chart.
+// Sliders go rolls and spinners go shakes, which drum mode already handles.
+// Short sliders are split into normal notes at each tick, as osu! does,
+// since a roll shorter than 2 beats is hardly playable as a roll.
+func (f Format) ToTaiko() *Format {
+	f2 := f.sortedCopy()
+	f2.Mode = ModeTaiko
+
+	hos := make([]HitObject, 0, len(f2.HitObjects))
+	for _, ho := range f2.HitObjects {
+		if ho.NoteType&HitTypeSlider == 0 {
+			hos = append(hos, ho)
+			continue
+		}
+
+		beatLength, _ := f2.timingAt(ho.Time)
+		duration := float64(ho.SliderDuration(f2.SliderSpeed(ho.Time)))
+		if duration >= 2*beatLength || ho.SliderParams.Slides == 0 {
+			hos = append(hos, ho)
+			continue
+		}
+
+		tickRate := f2.SliderTickRate
+		if tickRate <= 0 {
+			tickRate = 1
+		}
+		spanDuration := duration / float64(ho.SliderParams.Slides)
+		step := math.Min(beatLength/tickRate, spanDuration)
+		if step <= 0 {
+			hos = append(hos, ho)
+			continue
+		}
+		for t := 0.0; t <= duration+1; t += step {
+			n := ho
+			n.Time = ho.Time + int(t)
+			n.NoteType = HitTypeNote | ho.NoteType&NewCombo
+			n.SliderParams = SliderParams{}
+			hos = append(hos, n)
+		}
+	}
+	f2.HitObjects = hos
+	return f2
+}
+
+// ManiaKeyCount returns the key count which osu! chooses
+// when converting osu!standard chart to osu!mania chart.
+func (f Format) ManiaKeyCount() int {
+	var sliderOrSpinner int
+	for _, ho := range f.HitObjects {
+		if ho.NoteType&(HitTypeSlider|HitTypeSpinner) != 0 {
+			sliderOrSpinner++
+		}
+	}
+
+	od := int(math.Round(f.OverallDifficulty))
+	cs := int(math.Round(f.CircleSize))
+	total := float64(len(f.HitObjects))
+	if total == 0 {
+		return 4
+	}
+	ratio := float64(sliderOrSpinner) / total
+	switch {
+	case ratio < 0.2:
+		return 7
+	case ratio < 0.3 || cs >= 5:
+		if od > 5 {
+			return 7
+		}
+		return 6
+	case ratio > 0.6:
+		if od > 4 {
+			return 5
+		}
+		return 4
+	}
+	return max(4, min(od+1, 7))
+}
+
+// ToMania converts osu!standard chart to osu!mania chart with given key count.
+// Sliders and spinners go long notes. A column is derived from X position.
+// When the column is occupied by a long note, the nearest free column is used.
+func (f Format) ToMania(keyCount int) *Format {
+	f2 := f.sortedCopy()
+	f2.Mode = ModeMania
+	f2.CircleSize = float64(keyCount)
+
+	const minHoldDuration = 60
+	ends := make([]int, keyCount) // Time when each column gets free.
+	for i := range ends {
+		ends[i] = math.MinInt
+	}
+	for i, ho := range f2.HitObjects {
+		n := HitObject{
+			Time:      ho.Time,
+			NoteType:  HitTypeNote | ho.NoteType&NewCombo,
+			HitSound:  ho.HitSound,
+			HitSample: ho.HitSample,
+		}
+		end := ho.Time
+		switch {
+		case ho.NoteType&HitTypeSlider != 0:
+			end = ho.Time + ho.SliderDuration(f2.SliderSpeed(ho.Time))
+		case ho.NoteType&HitTypeSpinner != 0:
+			end = ho.EndTime
+		}
+		if end-ho.Time >= minHoldDuration {
+			n.NoteType = HitTypeHoldNote | ho.NoteType&NewCombo
+			n.EndTime = end
+		} else {
+			end = ho.Time
+		}
+
+		col := freeColumn(ends, ho.Column(keyCount), ho.Time)
+		ends[col] = end
+		n.X = (col*512 + 256) / keyCount
+		n.Y = 192
+		f2.HitObjects[i] = n
+	}
+	return f2
+}
+
+// freeColumn returns the column nearest to the given one
+// which is not occupied at the given time.
+func freeColumn(ends []int, col, time int) int {
+	if col >= len(ends) {
+		col = len(ends) - 1
+	}
+	if col < 0 {
+		col = 0
+	}
+	for d := 0; d < len(ends); d++ {
+		for _, c := range []int{col + d, col - d} {
+			if c >= 0 && c < len(ends) && ends[c] < time {
+				return c
+			}
+		}
+	}
+	return col
+}
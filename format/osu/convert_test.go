@@ -0,0 +1,38 @@
+package osu
+
+import "testing"
+
+func TestManiaKeyCount(t *testing.T) {
+	newFormat := func(notes, sliders, spinners int, od, cs float64) Format {
+		var f Format
+		f.OverallDifficulty = od
+		f.CircleSize = cs
+		for _, c := range []struct {
+			count    int
+			noteType int
+		}{{notes, HitTypeNote}, {sliders, HitTypeSlider}, {spinners, HitTypeSpinner}} {
+			for range c.count {
+				f.HitObjects = append(f.HitObjects, HitObject{NoteType: c.noteType})
+			}
+		}
+		return f
+	}
+	for _, tc := range []struct {
+		name string
+		f    Format
+		want int
+	}{
+		{"empty", Format{}, 4},
+		{"circles", newFormat(10, 0, 0, 3, 4), 7},
+		{"some sliders", newFormat(75, 25, 0, 3, 4), 6},
+		// Sliders count as much as spinners do.
+		{"mostly sliders", newFormat(30, 70, 0, 5, 4), 5},
+		{"mostly spinners", newFormat(30, 0, 70, 5, 4), 5},
+		{"mostly sliders, low OD", newFormat(30, 70, 0, 3, 4), 4},
+		{"half sliders", newFormat(50, 50, 0, 2, 4), 4},
+	} {
+		if got := tc.f.ManiaKeyCount(); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package osu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Encode returns the chart in .osu format, which NewFormat reads back.
+// Colours and storyboard other than backgrounds, videos, breaks
+// and samples are not written, since they are not parsed either.
+func (f Format) Encode() []byte {
+	var b bytes.Buffer
+	w := func(format string, a ...any) { fmt.Fprintf(&b, format+"\r\n", a...) }
+
+	w("osu file format v14")
+	w("")
+	w("[General]")
+	w("AudioFilename: %s", f.AudioFilename)
+	w("AudioLeadIn: %d", f.AudioLeadIn)
+	w("PreviewTime: %d", f.PreviewTime)
+	w("Countdown: %d", f.Countdown)
+	w("SampleSet: %s", f.SampleSet)
+	w("StackLeniency: %s", formatFloat(f.StackLeniency))
+	w("Mode: %d", f.Mode)
+	w("LetterboxInBreaks: %d", formatBool(f.LetterboxInBreaks))
+	w("SpecialStyle: %d", formatBool(f.SpecialStyle))
+	w("WidescreenStoryboard: %d", formatBool(f.WidescreenStoryboard))
+	w("SamplesMatchPlaybackRate: %d", formatBool(f.SamplesMatchPlaybackRate))
+	w("")
+	w("[Editor]")
+	if len(f.Bookmarks) > 0 {
+		w("Bookmarks: %s", joinInts(f.Bookmarks, ","))
+	}
+	w("DistanceSpacing: %s", formatFloat(f.DistanceSpacing))
+	w("BeatDivisor: %d", f.BeatDivisor)
+	w("GridSize: %d", f.GridSize)
+	w("TimelineZoom: %s", formatFloat(f.TimelineZoom))
+	w("")
+	w("[Metadata]")
+	w("Title:%s", f.Title)
+	w("TitleUnicode:%s", f.TitleUnicode)
+	w("Artist:%s", f.Artist)
+	w("ArtistUnicode:%s", f.ArtistUnicode)
+	w("Creator:%s", f.Creator)
+	w("Version:%s", f.Version)
+	w("Source:%s", f.Source)
+	w("Tags:%s", strings.Join(f.Tags, " "))
+	w("BeatmapID:%d", f.BeatmapID)
+	w("BeatmapSetID:%d", f.BeatmapSetID)
+	w("")
+	w("[Difficulty]")
+	w("HPDrainRate:%s", formatFloat(f.HPDrainRate))
+	w("CircleSize:%s", formatFloat(f.CircleSize))
+	w("OverallDifficulty:%s", formatFloat(f.OverallDifficulty))
+	w("ApproachRate:%s", formatFloat(f.ApproachRate))
+	w("SliderMultiplier:%s", formatFloat(f.SliderMultiplier))
+	w("SliderTickRate:%s", formatFloat(f.SliderTickRate))
+	w("")
+	w("[Events]")
+	for _, e := range f.Events {
+		switch e.Type {
+		case "Background":
+			w(`0,%d,"%s",%d,%d`, e.StartTime, e.Filename, e.XOffset, e.YOffset)
+		case "Video":
+			w(`Video,%d,"%s",%d,%d`, e.StartTime, e.Filename, e.XOffset, e.YOffset)
+		case "Break":
+			w("2,%d,%d", e.StartTime, e.EndTime)
+		case "Sample":
+			w(`Sample,%d,%d,"%s",%d`, e.StartTime, e.Layer, e.Filename, e.Volume)
+		}
+	}
+	w("")
+	w("[TimingPoints]")
+	for _, tp := range f.TimingPoints {
+		w("%d,%s,%d,%d,%d,%d,%d,%d", tp.Time, formatBeatLength(tp.BeatLength),
+			tp.Meter, tp.SampleSet, tp.SampleIndex, tp.Volume,
+			formatBool(tp.Uninherited), tp.Effects)
+	}
+	w("")
+	w("[HitObjects]")
+	for _, ho := range f.HitObjects {
+		w("%s", ho.encode())
+	}
+	return b.Bytes()
+}
+
+func (ho HitObject) encode() string {
+	s := fmt.Sprintf("%d,%d,%d,%d,%d", ho.X, ho.Y, ho.Time, ho.NoteType, ho.HitSound)
+	hs := ho.HitSample.encode()
+	switch ho.NoteType & ComboMask {
+	case HitTypeSlider:
+		return s + "," + ho.SliderParams.encode() + "," + hs
+	case HitTypeSpinner:
+		return fmt.Sprintf("%s,%d,%s", s, ho.EndTime, hs)
+	case HitTypeHoldNote:
+		return fmt.Sprintf("%s,%d:%s", s, ho.EndTime, hs)
+	}
+	return s + "," + hs
+}
+
+func (sp SliderParams) encode() string {
+	curve := []string{sp.CurveType}
+	for _, p := range sp.CurvePoints {
+		curve = append(curve, encodePoint(p))
+	}
+	s := fmt.Sprintf("%s,%d,%s", strings.Join(curve, "|"), sp.Slides, formatFloat(sp.Length))
+	if len(sp.EdgeSounds) == 0 || len(sp.EdgeSets) == 0 {
+		return s
+	}
+	sets := make([]string, len(sp.EdgeSets))
+	for i, p := range sp.EdgeSets {
+		sets[i] = encodePoint(p)
+	}
+	return s + "," + joinInts(sp.EdgeSounds, "|") + "," + strings.Join(sets, "|")
+}
+
+func (hs HitSample) encode() string {
+	return fmt.Sprintf("%d:%d:%d:%d:%s", hs.NormalSet, hs.AdditionSet, hs.Index, hs.Volume, hs.Filename)
+}
+
+func encodePoint(p Point) string { return fmt.Sprintf("%d:%d", p[0], p[1]) }
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+// Infinite beat lengths are written as osu! does.
+func formatBeatLength(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "∞"
+	case math.IsInf(v, -1):
+		return "-∞"
+	}
+	return formatFloat(v)
+}
+
+func formatBool(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func joinInts(vs []int, sep string) string {
+	ss := make([]string, len(vs))
+	for i, v := range vs {
+		ss[i] = strconv.Itoa(v)
+	}
+	return strings.Join(ss, sep)
+}
@@ -14,6 +14,8 @@ type Event struct { // delimiter,
 	Filename  string
 	XOffset   int
 	YOffset   int
+	Layer     int // Sample only.
+	Volume    int // Sample only. 100 when omitted.
 }
 
 // Exported functions are not guaranteed to be at top of the file.
@@ -27,6 +29,8 @@ func newEvent(line string) (ev Event, err error) {
 		ev.Type = "Video"
 	case "2", "Break":
 		ev.Type = "Break"
+	case "5", "Sample":
+		ev.Type = "Sample"
 	}
 
 	switch ev.Type {
@@ -45,6 +49,25 @@ func newEvent(line string) (ev Event, err error) {
 			return
 		}
 
+	case "Sample":
+		// Sample,time,layer,filepath,volume
+		if len(vs) < 4 {
+			return ev, errors.New("invalid event: not enough length")
+		}
+		if ev.StartTime, err = parseInt(vs[1]); err != nil {
+			return
+		}
+		if ev.Layer, err = parseInt(vs[2]); err != nil {
+			return
+		}
+		ev.Filename = strings.Trim(vs[3], `"`)
+		ev.Volume = 100
+		if len(vs) >= 5 {
+			if ev.Volume, err = parseInt(vs[4]); err != nil {
+				return
+			}
+		}
+
 	case "Break":
 		if len(vs) < 3 {
 			return ev, errors.New("invalid event: not enough length")
@@ -77,3 +100,15 @@ func (f Format) Video() (Event, bool) {
 	}
 	return Event{}, false
 }
+
+// Samples returns storyboard sample events, which are
+// played regardless of the player's input.
+func (f Format) Samples() []Event {
+	var es []Event
+	for _, e := range f.Events {
+		if e.Type == "Sample" {
+			es = append(es, e)
+		}
+	}
+	return es
+}
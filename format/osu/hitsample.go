@@ -12,6 +12,15 @@ const (
 	HitSoundClap
 )
 
+// Sample sets are used in HitSample and TimingPoint.
+// SampleSetAuto means the sample set is inherited.
+const (
+	SampleSetAuto = iota
+	SampleSetNormal
+	SampleSetSoft
+	SampleSetDrum
+)
+
 type HitSample struct { // delimiter:
 	NormalSet   int    // Sample set of the normal sound.
 	AdditionSet int    // Sample set of the whistle, finish, and clap sounds.
@@ -48,28 +57,114 @@ func newHitSample(s string) (hs HitSample, err error) {
 			return
 		}
 	}
-	hs.Filename = vs[4]
+	if len(vs) >= 5 {
+		hs.Filename = vs[4]
+	}
 
 	return
 }
 
-func (h HitObject) SampleFilename() string {
+// ResolveHitSample returns the hit object's HitSample with inherited
+// fields filled. Zero fields are inherited from the timing point at the
+// hit object's time, then sample set from General.SampleSet.
+// Addition set is inherited from the normal set.
+// Index stays 0 when the timing point also has 0, which means skin's samples.
+func (f Format) ResolveHitSample(ho HitObject) HitSample {
+	hs := ho.HitSample
+	tp, ok := f.timingPointAt(ho.Time)
+	if hs.NormalSet == SampleSetAuto && ok {
+		hs.NormalSet = tp.SampleSet
+	}
+	if hs.NormalSet == SampleSetAuto {
+		hs.NormalSet = f.sampleSet()
+	}
+	if hs.AdditionSet == SampleSetAuto {
+		hs.AdditionSet = hs.NormalSet
+	}
+	if hs.Index == 0 && ok {
+		hs.Index = tp.SampleIndex
+	}
+	if hs.Volume == 0 && ok {
+		hs.Volume = tp.Volume
+	}
+	return hs
+}
+
+// timingPointAt returns the latest timing point at the given time,
+// regardless of whether it is inherited or not. The first timing point
+// is returned when the time is before every timing point.
+// A latter one in the file wins when timing points share the same time.
+func (f Format) timingPointAt(time int) (TimingPoint, bool) {
+	var (
+		tp, first   TimingPoint
+		found, some bool
+	)
+	for _, tp2 := range f.TimingPoints {
+		if !some || tp2.Time < first.Time {
+			first = tp2
+			some = true
+		}
+		if tp2.Time <= time && (!found || tp2.Time >= tp.Time) {
+			tp = tp2
+			found = true
+		}
+	}
+	if !found {
+		return first, some
+	}
+	return tp, true
+}
+
+// sampleSet converts General.SampleSet to the sample set.
+// "None" and unknown values go to Normal.
+func (f Format) sampleSet() int {
+	switch f.SampleSet {
+	case "Soft":
+		return SampleSetSoft
+	case "Drum":
+		return SampleSetDrum
+	}
+	return SampleSetNormal
+}
+
+// UsesSkinSamples reports whether skin's samples should be played
+// instead of the beatmap's. HitSample is supposed to be resolved.
+func (h HitObject) UsesSkinSamples() bool {
+	return h.HitSample.Index == 0 && h.HitSample.Filename == ""
+}
+
+var (
+	sampleSetNames = [...]string{"", "normal", "soft", "drum"}
+	hitSoundNames  = [...]string{"normal", "whistle", "finish", "clap"}
+)
+
+// SampleFilenames returns names of sample files to be played together.
+// HitSample is supposed to be resolved by Format.ResolveHitSample.
+// A custom filename replaces every sound. Otherwise, normal sound is
+// always played, and addition sounds are played along with their bits.
+// Format of the name: <sampleSet>-hit<hitSound><index>.wav
+func (h HitObject) SampleFilenames() []string {
 	if h.HitSample.Filename != "" {
-		return h.HitSample.Filename
+		return []string{h.HitSample.Filename}
 	}
 
-	// default sample
-	// Todo: test
-	sampleSetName := h.sampleSetName()
-	if sampleSetName == "" {
-		return ""
+	names := []string{h.sampleFilename(h.HitSample.NormalSet, 0)}
+	for i := 1; i < len(hitSoundNames); i++ {
+		if h.HitSound&(1<<i) != 0 {
+			names = append(names, h.sampleFilename(h.HitSample.AdditionSet, i))
+		}
 	}
+	return names
+}
 
-	// <sampleSet>-hit<hitSound><index>.wav
+func (h HitObject) sampleFilename(set, sound int) string {
+	if set <= SampleSetAuto || set >= len(sampleSetNames) {
+		set = SampleSetNormal
+	}
 	var b strings.Builder
-	b.WriteString(sampleSetName)
+	b.WriteString(sampleSetNames[set])
 	b.WriteString("-hit")
-	b.WriteString(h.hitSoundName())
+	b.WriteString(hitSoundNames[sound])
 
 	// Index is omitted if it is 0 or 1.
 	if h.HitSample.Index >= 2 {
@@ -81,28 +176,3 @@ func (h HitObject) SampleFilename() string {
 	b.WriteString(".wav")
 	return b.String()
 }
-
-func (h HitObject) isHitSoundNormal() bool { return h.HitSound <= HitSoundNormal }
-
-var sampleSetNames = [...]string{"", "normal", "soft", "drum"}
-
-func (h HitObject) sampleSetName() string {
-	i := h.HitSample.NormalSet
-	if !h.isHitSoundNormal() && h.HitSample.AdditionSet != 0 {
-		i = h.HitSample.AdditionSet
-	}
-	return sampleSetNames[i]
-}
-
-var hitSoundNames = [...]string{"normal", "whistle", "finish", "clap"}
-
-// Todo: need a test when both bits are set such as Finish and Clap.
-func (h HitObject) hitSoundName() string {
-	for i := 3; i >= 0; i-- {
-		v := 1 << i // 8, 4, 2, 1
-		if h.HitSound&v != 0 {
-			return hitSoundNames[i]
-		}
-	}
-	return hitSoundNames[0]
-}
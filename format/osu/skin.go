@@ -0,0 +1,288 @@
+package osu
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Skin is skin.ini of osu! skins, reduced to [General] and [Mania].
+// Each [Mania] section is for a key count.
+type Skin struct {
+	Name    string
+	Author  string
+	Version string
+	Manias  map[int]ManiaSkin // by key count
+}
+
+// ManiaSkin is a [Mania] section. Lengths are in osu!'s virtual pixels,
+// where the screen is 480 pixels high. Image names are paths from
+// the skin directory without extension; empty names are up to
+// the importer, as osu! falls back to default images.
+type ManiaSkin struct {
+	Keys            int
+	ColumnStart     float64
+	ColumnWidth     []float64 // by column
+	ColumnSpacing   []float64 // between columns
+	ColumnLineWidth []float64 // Keys + 1 lines, including both sides.
+	HitPosition     float64   // Judgment line from the top.
+	ScorePosition   float64   // Judgments from the top. Zero is unset.
+	JudgementLine   bool
+
+	// By column.
+	NoteImages  []string
+	NoteImagesH []string // Head
+	NoteImagesL []string // Body
+	NoteImagesT []string // Tail
+	KeyImages   []string
+	KeyImagesD  []string // Pressed
+
+	StageHint string
+	LightingN string            // Lights by hitting notes
+	LightingL string            // Lights by holding long notes
+	Hits      map[string]string // Judgment images; e.g., Hit300g: mania-hit300g
+
+	Colours          []color.NRGBA // Column backgrounds
+	ColourLights     []color.NRGBA // Lights above keys
+	ColourColumnLine color.NRGBA
+}
+
+// Default values of osu!.
+const (
+	defaultColumnWidth     = 30
+	defaultColumnLineWidth = 2
+	defaultHitPosition     = 402
+	defaultColumnStart     = 136
+)
+
+func newManiaSkin() ManiaSkin {
+	return ManiaSkin{
+		ColumnStart:      defaultColumnStart,
+		HitPosition:      defaultHitPosition,
+		JudgementLine:    true,
+		Hits:             make(map[string]string),
+		ColourColumnLine: color.NRGBA{255, 255, 255, 255},
+	}
+}
+
+func NewSkin(data []byte) (*Skin, error) {
+	s := &Skin{Manias: make(map[int]ManiaSkin)}
+	data = bytes.TrimPrefix(data, []byte("\ufeff")) // UTF-8 BOM
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	var section string
+	var mania *ManiaSkin
+	flush := func() {
+		if mania != nil && mania.Keys > 0 {
+			mania.fill()
+			s.Manias[mania.Keys] = *mania
+		}
+		mania = nil
+	}
+	for scanner.Scan() {
+		line := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+		if isPass(line) {
+			continue
+		}
+		if isSection(line) {
+			flush()
+			section = strings.Trim(strings.TrimSpace(line), "[]")
+			if section == "Mania" {
+				m := newManiaSkin()
+				mania = &m
+			}
+			continue
+		}
+
+		k, v, err := keyValue(line, `:`)
+		if err != nil {
+			continue // Skins in the wild have broken lines.
+		}
+		v = strings.TrimSpace(v)
+		switch section {
+		case "General":
+			switch k {
+			case "Name":
+				s.Name = v
+			case "Author":
+				s.Author = v
+			case "Version":
+				s.Version = v
+			}
+		case "Mania":
+			// A bad value, such as of a column out of range,
+			// is skipped, leaving the key as it was.
+			m := *mania
+			if err := m.set(k, v); err == nil {
+				*mania = m
+			}
+		}
+	}
+	flush()
+	return s, scanner.Err()
+}
+
+func (m *ManiaSkin) set(k, v string) (err error) {
+	switch k {
+	case "Keys":
+		m.Keys, err = parseInt(v)
+	case "ColumnStart":
+		m.ColumnStart, err = parseFloat(v)
+	case "ColumnWidth":
+		m.ColumnWidth, err = parseFloats(v)
+	case "ColumnSpacing":
+		m.ColumnSpacing, err = parseFloats(v)
+	case "ColumnLineWidth":
+		m.ColumnLineWidth, err = parseFloats(v)
+	case "HitPosition":
+		m.HitPosition, err = parseFloat(v)
+	case "ScorePosition":
+		m.ScorePosition, err = parseFloat(v)
+	case "JudgementLine":
+		m.JudgementLine = v == "1" || strings.EqualFold(v, "true")
+	case "StageHint":
+		m.StageHint = skinPath(v)
+	case "LightingN":
+		m.LightingN = skinPath(v)
+	case "LightingL":
+		m.LightingL = skinPath(v)
+	case "ColourColumnLine":
+		m.ColourColumnLine = newSkinColor(v)
+	case "Hit0", "Hit50", "Hit100", "Hit200", "Hit300", "Hit300g":
+		m.Hits[k] = skinPath(v)
+	default:
+		return m.setColumn(k, v)
+	}
+	return
+}
+
+// Column keys have column index in the middle, such as
+// NoteImage0H and KeyImage0D. Colours start from 1.
+func (m *ManiaSkin) setColumn(k, v string) error {
+	type column struct {
+		prefix, suffix string
+		images         *[]string
+		colours        *[]color.NRGBA
+	}
+	for _, c := range []column{
+		{"NoteImage", "H", &m.NoteImagesH, nil},
+		{"NoteImage", "L", &m.NoteImagesL, nil},
+		{"NoteImage", "T", &m.NoteImagesT, nil},
+		{"NoteImage", "", &m.NoteImages, nil},
+		{"KeyImage", "D", &m.KeyImagesD, nil},
+		{"KeyImage", "", &m.KeyImages, nil},
+		{"ColourLight", "", nil, &m.ColourLights},
+		{"Colour", "", nil, &m.Colours},
+	} {
+		if !strings.HasPrefix(k, c.prefix) || !strings.HasSuffix(k, c.suffix) {
+			continue
+		}
+		i, err := strconv.Atoi(k[len(c.prefix) : len(k)-len(c.suffix)])
+		if err != nil {
+			continue // Such as ColourLight1 tried for Colour.
+		}
+		if c.colours != nil {
+			i-- // 1-based
+		}
+		if i < 0 || i >= 18 { // osu! supports up to 18 keys.
+			return fmt.Errorf("column out of range: %d", i)
+		}
+		if c.images != nil {
+			*c.images = setAt(*c.images, i, skinPath(v))
+		} else {
+			*c.colours = setAt(*c.colours, i, newSkinColor(v))
+		}
+		return nil
+	}
+	return nil // Other keys are not used.
+}
+
+func setAt[T any](vs []T, i int, v T) []T {
+	for len(vs) <= i {
+		var zero T
+		vs = append(vs, zero)
+	}
+	vs[i] = v
+	return vs
+}
+
+// fill makes column values have the length of key count.
+// Missing widths are filled with the last given one.
+func (m *ManiaSkin) fill() {
+	m.ColumnWidth = fillFloats(m.ColumnWidth, m.Keys, defaultColumnWidth)
+	m.ColumnSpacing = fillFloats(m.ColumnSpacing, m.Keys-1, 0)
+	m.ColumnLineWidth = fillFloats(m.ColumnLineWidth, m.Keys+1, defaultColumnLineWidth)
+	for _, images := range []*[]string{
+		&m.NoteImages, &m.NoteImagesH, &m.NoteImagesL, &m.NoteImagesT,
+		&m.KeyImages, &m.KeyImagesD,
+	} {
+		*images = resize(*images, m.Keys)
+	}
+	m.Colours = resize(m.Colours, m.Keys)
+	m.ColourLights = resize(m.ColourLights, m.Keys)
+}
+
+func resize[T any](vs []T, n int) []T {
+	if len(vs) >= n {
+		return vs[:n]
+	}
+	return append(vs, make([]T, n-len(vs))...)
+}
+
+func fillFloats(vs []float64, n int, v float64) []float64 {
+	if n < 0 {
+		n = 0
+	}
+	if len(vs) > 0 {
+		v = vs[len(vs)-1]
+	}
+	for len(vs) < n {
+		vs = append(vs, v)
+	}
+	return vs[:n]
+}
+
+func parseFloats(s string) ([]float64, error) {
+	var vs []float64
+	for _, chunk := range strings.Split(s, `,`) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+		v, err := parseFloat(chunk)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// skinPath converts Windows path to slash-separated one for io/fs.
+func skinPath(v string) string {
+	return strings.Trim(strings.ReplaceAll(v, `\`, `/`), "/")
+}
+
+// Skin colours may have alpha, unlike beatmap colours.
+func newSkinColor(chunks string) color.NRGBA {
+	c := color.NRGBA{A: 255}
+	for i, chunk := range strings.Split(chunks, `,`) {
+		v, _ := parseInt(strings.TrimSpace(chunk))
+		v = max(0, min(255, v))
+		switch i {
+		case 0:
+			c.R = uint8(v)
+		case 1:
+			c.G = uint8(v)
+		case 2:
+			c.B = uint8(v)
+		case 3:
+			c.A = uint8(v)
+		}
+	}
+	return c
+}
@@ -0,0 +1,75 @@
+package osu
+
+import (
+	"image/color"
+	"testing"
+)
+
+const testSkin = "\ufeff" + `[General]
+Name: Test Skin
+Author: gosu
+
+// Comments are skipped.
+[Mania]
+Keys: 4
+ColumnWidth: 40,45,45,40
+ColumnLineWidth: 1,0,0,0,1
+HitPosition: 420
+NoteImage0: Notes\note1
+NoteImage0H: Notes\note1H
+KeyImage3D: keys/key1D
+Hit300g: judge\perfect
+ColourLight1: 255,0,0
+Colour2: 0,0,0,128
+
+[Mania]
+Keys: 7
+ColumnWidth: 30,32
+// Bad values are skipped.
+HitPosition: high
+ColumnSpacing: 1,x
+NoteImage20: Notes\note20
+Colour0: 255,0,0
+
+[Colours]
+Combo1: 255,255,255
+`
+
+func TestNewSkin(t *testing.T) {
+	s, err := NewSkin([]byte(testSkin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "Test Skin" || len(s.Manias) != 2 {
+		t.Fatalf("got %q with %d mania sections", s.Name, len(s.Manias))
+	}
+
+	m := s.Manias[4]
+	if m.HitPosition != 420 || m.ColumnWidth[1] != 45 || len(m.ColumnLineWidth) != 5 {
+		t.Errorf("layout: %+v", m)
+	}
+	if len(m.ColumnSpacing) != 3 {
+		t.Errorf("got %d spacings, want 3", len(m.ColumnSpacing))
+	}
+	if m.NoteImages[0] != "Notes/note1" || m.NoteImagesH[0] != "Notes/note1H" || m.NoteImages[1] != "" {
+		t.Errorf("note images: %q, %q", m.NoteImages, m.NoteImagesH)
+	}
+	if len(m.KeyImages) != 4 || m.KeyImagesD[3] != "keys/key1D" {
+		t.Errorf("key images: %q, %q", m.KeyImages, m.KeyImagesD)
+	}
+	if m.Hits["Hit300g"] != "judge/perfect" {
+		t.Errorf("judgment images: %v", m.Hits)
+	}
+	if m.ColourLights[0] != (color.NRGBA{255, 0, 0, 255}) || m.Colours[1] != (color.NRGBA{0, 0, 0, 128}) {
+		t.Errorf("colours: %v, %v", m.ColourLights, m.Colours)
+	}
+
+	// Missing widths are filled with the last given one.
+	m7 := s.Manias[7]
+	if len(m7.ColumnWidth) != 7 || m7.ColumnWidth[6] != 32 || m7.HitPosition != defaultHitPosition {
+		t.Errorf("7K: %+v", m7)
+	}
+	if len(m7.NoteImages) != 7 || len(m7.ColumnSpacing) != 6 || m7.ColumnSpacing[0] != 0 {
+		t.Errorf("7K with bad values: %+v", m7)
+	}
+}
@@ -0,0 +1,273 @@
+package ultrastar
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UltraStar chart is a plain text file.
+// Header lines start with '#', and each of note lines starts with
+// a note type letter. A chart ends with 'E'.
+// Reference: https://usdx.eu/format/
+type NoteType byte
+
+const (
+	NoteNormal     NoteType = ':'
+	NoteGolden     NoteType = '*'
+	NoteFreestyle  NoteType = 'F'
+	NoteRap        NoteType = 'R'
+	NoteRapGolden  NoteType = 'G'
+	noteLineBreak           = '-'
+	noteEnd                 = 'E'
+	notePlayerMark          = 'P'
+)
+
+// Pitch 0 stands for C4, which is 60 in MIDI note number.
+const MIDIOffset = 60
+
+type Note struct {
+	Type     NoteType
+	Beat     int
+	Length   int
+	Pitch    int
+	Syllable string
+}
+
+// Line is a group of notes displayed at once.
+// EndBeat is the beat of the line break which ends the line.
+type Line struct {
+	Notes   []Note
+	EndBeat int
+}
+
+type Format struct {
+	Title         string
+	Artist        string
+	Creator       string
+	Genre         string
+	Language      string
+	Edition       string
+	Year          int
+	AudioFilename string // #MP3 or #AUDIO
+	Cover         string
+	Background    string
+	Video         string
+	VideoGap      float64 // in seconds
+	BPM           float64 // A beat lasts a quarter of 60000/BPM ms.
+	Gap           float64 // in milliseconds
+	Start         float64 // in seconds
+	End           float64 // in milliseconds
+	PreviewStart  float64 // in seconds; negative when not set
+	Relative      bool
+	Lines         []Line
+}
+
+func NewFormat(data []byte) (*Format, error) {
+	f := &Format{PreviewStart: -1}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+
+	r := bytes.NewReader(data)
+	scanner := bufio.NewScanner(r)
+
+	var (
+		line       Line
+		relBeat    int // Base beat in relative mode.
+		hasBPM     bool
+		hasHeader  bool
+		playerSeen bool
+	)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) == 0 {
+			continue
+		}
+
+		switch text[0] {
+		case '#':
+			key, value, err := keyValue(text[1:])
+			if err != nil {
+				return f, err
+			}
+			if err := f.setHeader(key, value); err != nil {
+				return f, fmt.Errorf("error at %s: %w", text, err)
+			}
+			if key == "BPM" {
+				hasBPM = true
+			}
+			hasHeader = true
+		case byte(NoteNormal), byte(NoteGolden), byte(NoteFreestyle),
+			byte(NoteRap), byte(NoteRapGolden):
+			n, err := newNote(text)
+			if err != nil {
+				return f, fmt.Errorf("error at %s: %w", text, err)
+			}
+			n.Beat += relBeat
+			line.Notes = append(line.Notes, n)
+		case noteLineBreak:
+			vs := strings.Fields(text[1:])
+			if len(vs) == 0 {
+				return f, fmt.Errorf("error at %s: no beat", text)
+			}
+			beat, err := strconv.Atoi(vs[0])
+			if err != nil {
+				return f, fmt.Errorf("error at %s: %w", text, err)
+			}
+			line.EndBeat = beat + relBeat
+			f.Lines = append(f.Lines, line)
+			line = Line{}
+
+			// In relative mode, following beats are relative to the line break.
+			if f.Relative {
+				if len(vs) >= 2 {
+					if beat, err = strconv.Atoi(vs[1]); err != nil {
+						return f, fmt.Errorf("error at %s: %w", text, err)
+					}
+				}
+				relBeat += beat
+			}
+		case notePlayerMark:
+			// Only the first player's part is read in duet charts.
+			if playerSeen {
+				goto end
+			}
+			playerSeen = true
+		case noteEnd:
+			goto end
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return f, err
+	}
+
+end:
+	if len(line.Notes) > 0 {
+		last := line.Notes[len(line.Notes)-1]
+		line.EndBeat = last.Beat + last.Length
+		f.Lines = append(f.Lines, line)
+	}
+	if !hasHeader || !hasBPM {
+		return f, errors.New("not an UltraStar chart: no BPM")
+	}
+	if f.BPM <= 0 {
+		return f, fmt.Errorf("invalid BPM: %f", f.BPM)
+	}
+	return f, nil
+}
+
+func keyValue(text string) (key, value string, err error) {
+	kv := strings.SplitN(text, ":", 2)
+	if len(kv) < 2 {
+		return "", "", fmt.Errorf("%s: key value not enough length", text)
+	}
+	key = strings.ToUpper(strings.TrimSpace(kv[0]))
+	value = strings.TrimSpace(kv[1])
+	return key, value, nil
+}
+
+// Some charts use comma as a decimal separator.
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+}
+
+func (f *Format) setHeader(key, value string) (err error) {
+	switch key {
+	case "TITLE":
+		f.Title = value
+	case "ARTIST":
+		f.Artist = value
+	case "CREATOR", "AUTHOR":
+		f.Creator = value
+	case "GENRE":
+		f.Genre = value
+	case "LANGUAGE":
+		f.Language = value
+	case "EDITION":
+		f.Edition = value
+	case "YEAR":
+		f.Year, _ = strconv.Atoi(value) // Year is not crucial.
+	case "MP3", "AUDIO":
+		f.AudioFilename = value
+	case "COVER":
+		f.Cover = value
+	case "BACKGROUND":
+		f.Background = value
+	case "VIDEO":
+		f.Video = value
+	case "VIDEOGAP":
+		f.VideoGap, err = parseFloat(value)
+	case "BPM":
+		f.BPM, err = parseFloat(value)
+	case "GAP":
+		f.Gap, err = parseFloat(value)
+	case "START":
+		f.Start, err = parseFloat(value)
+	case "END":
+		f.End, err = parseFloat(value)
+	case "PREVIEWSTART":
+		f.PreviewStart, err = parseFloat(value)
+	case "RELATIVE":
+		f.Relative = strings.EqualFold(value, "yes")
+	}
+	return
+}
+
+// Numeric fields may be separated by any number of spaces or tabs.
+// Syllable is the rest of the line after a separator,
+// which may start with a space.
+func newNote(text string) (n Note, err error) {
+	// type beat length pitch syllable
+	vs := strings.Fields(text)
+	if len(vs) < 4 {
+		return n, errors.New("note has not enough length")
+	}
+	n.Type = NoteType(vs[0][0])
+	if n.Beat, err = strconv.Atoi(vs[1]); err != nil {
+		return
+	}
+	if n.Length, err = strconv.Atoi(vs[2]); err != nil {
+		return
+	}
+	if n.Pitch, err = strconv.Atoi(vs[3]); err != nil {
+		return
+	}
+	rest := text
+	for _, v := range vs[:4] {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)[len(v):]
+	}
+	if len(rest) > 0 {
+		n.Syllable = rest[1:]
+	}
+	return
+}
+
+// BeatDuration returns the duration of a beat in milliseconds.
+func (f Format) BeatDuration() float64 { return 60000 / (f.BPM * 4) }
+
+// Time returns the time of the beat in milliseconds.
+func (f Format) Time(beat int) float64 { return f.Gap + float64(beat)*f.BeatDuration() }
+
+func (f Format) Duration() int {
+	if len(f.Lines) == 0 {
+		return 0
+	}
+	ns := f.Lines[len(f.Lines)-1].Notes
+	if len(ns) == 0 {
+		return 0
+	}
+	last := ns[len(ns)-1]
+	return int(f.Time(last.Beat + last.Length))
+}
+
+// Text returns lyrics of the line.
+func (l Line) Text() string {
+	var b strings.Builder
+	for _, n := range l.Notes {
+		b.WriteString(n.Syllable)
+	}
+	return strings.TrimSpace(b.String())
+}
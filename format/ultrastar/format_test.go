@@ -0,0 +1,87 @@
+package ultrastar
+
+import "testing"
+
+const chart = `#TITLE:Test Song
+#ARTIST:gosu
+#MP3:test.mp3
+#BPM:300,5
+#GAP:1000
+: 0 4 0 Hel
+: 4 4 2 lo
+* 8 8 4  world
+- 18
+F 20 4 0 ~
+R 24 4 0  yeah
+E
+: 99 1 0 ignored
+`
+
+func TestNewFormat(t *testing.T) {
+	f, err := NewFormat([]byte(chart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.BPM != 300.5 {
+		t.Errorf("BPM: got %f, want 300.5", f.BPM)
+	}
+	if f.PreviewStart >= 0 {
+		t.Errorf("preview start: got %f, want negative when not set", f.PreviewStart)
+	}
+	if len(f.Lines) != 2 {
+		t.Fatalf("lines: got %d, want 2", len(f.Lines))
+	}
+	if got := f.Lines[0].Text(); got != "Hello world" {
+		t.Errorf("line text: got %q", got)
+	}
+	if n := f.Lines[0].Notes[2]; n.Type != NoteGolden || n.Pitch != 4 || n.Length != 8 {
+		t.Errorf("golden note: got %+v", n)
+	}
+	if f.Lines[0].EndBeat != 18 {
+		t.Errorf("end beat: got %d, want 18", f.Lines[0].EndBeat)
+	}
+	if n := f.Lines[1].Notes[0]; n.Type != NoteFreestyle {
+		t.Errorf("freestyle note: got %+v", n)
+	}
+}
+
+func TestRelative(t *testing.T) {
+	data := "#TITLE:a\n#BPM:100\n#RELATIVE:yes\n: 0 2 0 a\n- 4 6\n: 0 2 0 b\nE\n"
+	f, err := NewFormat([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beat := f.Lines[1].Notes[0].Beat; beat != 6 {
+		t.Errorf("relative beat: got %d, want 6", beat)
+	}
+}
+
+func TestNewNote(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want Note
+	}{
+		{": 0 4 5 Hel", Note{NoteNormal, 0, 4, 5, "Hel"}},
+		{":  12\t4   -3  world", Note{NoteNormal, 12, 4, -3, " world"}},
+		{"* 8 8 4 a b", Note{NoteGolden, 8, 8, 4, "a b"}},
+		{"F 20 4 0", Note{NoteFreestyle, 20, 4, 0, ""}},
+	} {
+		n, err := newNote(tc.text)
+		if err != nil {
+			t.Errorf("%q: %v", tc.text, err)
+			continue
+		}
+		if n != tc.want {
+			t.Errorf("%q: got %+v, want %+v", tc.text, n, tc.want)
+		}
+	}
+	if _, err := newNote(": 0 4"); err == nil {
+		t.Error("expected error for note without pitch")
+	}
+}
+
+func TestNotUltraStar(t *testing.T) {
+	if _, err := NewFormat([]byte("just a readme\n")); err == nil {
+		t.Error("expected error for non-UltraStar text")
+	}
+}
@@ -14,4 +14,36 @@ type PlayArgs struct {
 	Mods           plays.Mods
 	ReplayFS       fs.FS
 	ReplayFilename string
+	MusicGain      float64 // For loudness normalization, in dB.
+}
+
+// CalibrateArgs requests the calibration scene.
+type CalibrateArgs struct{}
+
+// CalibrateResult is returned by calibration scene.
+// Offsets are applied only when OK is true.
+type CalibrateResult struct {
+	MusicOffset  int32
+	VisualOffset int32
+	OK           bool
+}
+
+// KeyBindingArgs requests the key binding scene.
+type KeyBindingArgs struct{}
+
+// KeyBindingResult is returned when leaving key binding scene.
+type KeyBindingResult struct{}
+
+// PlayQuit is returned by play scene when the play is quit
+// in the middle. No record is saved.
+type PlayQuit struct{}
+
+// PlayResult is returned by play scene when the play is finished.
+type PlayResult struct {
+	ChartHash string
+	Mode      int
+	SubMode   int
+	Level     float64
+	Score     float64
+	Accuracy  float64
 }
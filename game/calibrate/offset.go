@@ -0,0 +1,70 @@
+package calibrate
+
+import (
+	"math"
+	"sort"
+)
+
+// At least minTapCount taps are required for a reliable offset.
+const minTapCount = 8
+
+// Outlier threshold has a floor not to reject too much
+// when taps are consistent.
+const minThreshold = 10 // ms
+
+// tapOffset returns a robust average of tap errors.
+// Each tap is matched with the nearest beat; taps too far
+// from any beat, or at warm-up beats, are discarded.
+// Outliers are then rejected by median absolute deviation.
+func tapOffset(taps []int32) (int32, bool) {
+	lead := int32(leadDuration.Milliseconds())
+	interval := int32(beatInterval.Milliseconds())
+
+	var es []float64
+	for _, t := range taps {
+		i := int(math.Round(float64(t-lead) / float64(interval)))
+		if i < warmupCount || i >= beatCount {
+			continue
+		}
+		e := t - (lead + int32(i)*interval)
+		if e < -interval/2 || e > interval/2 {
+			continue
+		}
+		es = append(es, float64(e))
+	}
+	if len(es) < minTapCount {
+		return 0, false
+	}
+
+	m := median(es)
+	ds := make([]float64, len(es))
+	for i, e := range es {
+		ds[i] = math.Abs(e - m)
+	}
+	// 1.4826 makes MAD a consistent estimator of standard deviation.
+	threshold := max(3*1.4826*median(ds), minThreshold)
+
+	var sum float64
+	var count int
+	for _, e := range es {
+		if math.Abs(e-m) <= threshold {
+			sum += e
+			count++
+		}
+	}
+	if count < minTapCount {
+		return 0, false
+	}
+	return int32(math.Round(sum / float64(count))), true
+}
+
+func median(vs []float64) float64 {
+	sorted := make([]float64, len(vs))
+	copy(sorted, vs)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
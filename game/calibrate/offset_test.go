@@ -0,0 +1,44 @@
+package calibrate
+
+import "testing"
+
+// beatTaps returns taps at beats in [from, to), off by offset
+// with jitter of a few milliseconds which averages to zero.
+func beatTaps(from, to int, offset int32) []int32 {
+	lead := int32(leadDuration.Milliseconds())
+	interval := int32(beatInterval.Milliseconds())
+	jitters := []int32{-4, -2, 0, 2, 4}
+	var taps []int32
+	for i := from; i < to; i++ {
+		taps = append(taps, lead+int32(i)*interval+offset+jitters[i%len(jitters)])
+	}
+	return taps
+}
+
+func TestTapOffset(t *testing.T) {
+	lead := int32(leadDuration.Milliseconds())
+	interval := int32(beatInterval.Milliseconds())
+	// Taps far off from the rest, at beats 10 and 15.
+	outliers := []int32{lead + 10*interval + 180, lead + 15*interval + 200}
+
+	for _, tc := range []struct {
+		name string
+		taps []int32
+		want int32
+		ok   bool
+	}{
+		{"late", beatTaps(warmupCount, beatCount, 30), 30, true},
+		{"early", beatTaps(warmupCount, beatCount, -40), -40, true},
+		{"warm-up beats", append(beatTaps(0, warmupCount, 200), beatTaps(warmupCount, beatCount, 30)...), 30, true},
+		{"after the last beat", append(beatTaps(warmupCount, beatCount, 30), beatTaps(beatCount, beatCount+4, -200)...), 30, true},
+		{"outliers", append(beatTaps(warmupCount, beatCount, 30), outliers...), 30, true},
+		{"too few taps", beatTaps(warmupCount, warmupCount+minTapCount-1, 30), 0, false},
+		{"warm-up only", beatTaps(0, warmupCount, 30), 0, false},
+		{"no taps", nil, 0, false},
+	} {
+		got, ok := tapOffset(tc.taps)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: got %d, %v; want %d, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package calibrate
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hndada/gosu/audios"
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/input"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/times"
+	"github.com/hndada/gosu/ui"
+)
+
+// Calibration goes in two phases: audio, then visual.
+// In audio phase, a player taps to metronome clicks without any visual cue.
+// In visual phase, a player taps to a flashing box without any sound.
+const (
+	audioPhase = iota
+	visualPhase
+	resultPhase
+)
+
+const (
+	leadDuration  = 2 * time.Second
+	beatInterval  = 500 * time.Millisecond // 120 BPM
+	beatCount     = 24
+	warmupCount   = 4 // First few beats are not counted.
+	flashDuration = 80 * time.Millisecond
+	meter         = 4
+)
+
+// Any of these keys works as a tap.
+var tapKeys = []input.Key{input.KeySpace, input.KeyZ, input.KeyX, input.KeyD, input.KeyF, input.KeyJ, input.KeyK}
+
+type Scene struct {
+	*game.Game
+	phase             int
+	startTime         time.Time
+	keyboard          *input.Keyboard
+	lastKeyboardState input.KeyboardState
+	metronome         *audios.Metronome
+
+	phasesTaps    [2][]int32 // Tap times in milliseconds.
+	phasesOffset  [2]int32
+	phasesOK      [2]bool
+	flash         bool
+	flashSprite   draws.Sprite
+	beatIndex     int
+	phasesMessage [3]string
+}
+
+func (Scene) New(g *game.Game, _ game.Args) (game.Scene, error) {
+	s := &Scene{Game: g}
+
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	sprite := draws.NewSprite(img)
+	sprite.SetSize(200, 200)
+	sprite.Locate(plays.ScreenSizeX/2, plays.ScreenSizeY/2, draws.CenterMiddle)
+	s.flashSprite = sprite
+
+	s.phasesMessage = [3]string{
+		"Audio offset: tap Space to the clicks. (Esc to cancel)",
+		"Visual offset: tap Space when the box flashes. (Esc to cancel)",
+		"",
+	}
+	s.startPhase(audioPhase)
+	return s, nil
+}
+
+func (s *Scene) startPhase(phase int) {
+	s.phase = phase
+	if phase == resultPhase {
+		return
+	}
+
+	s.startTime = times.Now()
+	s.keyboard = input.NewKeyboard(tapKeys)
+	s.lastKeyboardState = input.KeyboardState{}
+	s.keyboard.Listen(s.startTime)
+	if phase == audioPhase {
+		s.metronome = audios.NewMetronome(leadDuration, beatInterval, beatCount, meter)
+		s.metronome.SetVolume(s.Options.MusicVolume)
+		s.metronome.Play()
+	}
+}
+
+func (s *Scene) stopPhase() {
+	if s.keyboard != nil {
+		s.keyboard.Stop()
+		s.keyboard = nil
+	}
+	if s.metronome != nil {
+		s.metronome.Close()
+		s.metronome = nil
+	}
+}
+
+func (s *Scene) Update() any {
+	if ui.IsEscapeJustPressed() {
+		s.stopPhase()
+		return game.CalibrateResult{}
+	}
+	if s.phase == resultPhase {
+		if ui.IsEnterJustPressed() {
+			return s.result()
+		}
+		return nil
+	}
+
+	now := times.Since(s.startTime)
+	kss := s.keyboard.Read(now)
+	kss = append([]input.KeyboardState{s.lastKeyboardState}, kss...)
+	for _, ka := range plays.KeyboardActions(kss) {
+		for _, a := range ka.KeysAction {
+			if a == plays.Hit {
+				s.phasesTaps[s.phase] = append(s.phasesTaps[s.phase], ka.Time)
+				break
+			}
+		}
+	}
+	s.lastKeyboardState = kss[len(kss)-1]
+
+	sinceLead := now - leadDuration
+	s.beatIndex = int(sinceLead / beatInterval)
+	s.flash = s.phase == visualPhase &&
+		sinceLead >= 0 && s.beatIndex < beatCount &&
+		sinceLead%beatInterval < flashDuration
+
+	if now > leadDuration+beatCount*beatInterval+time.Second {
+		s.stopPhase()
+		s.phasesOffset[s.phase], s.phasesOK[s.phase] = tapOffset(s.phasesTaps[s.phase])
+		s.startPhase(s.phase + 1)
+	}
+	return nil
+}
+
+// Music offset is the opposite of audio tap offset:
+// when a player taps late, music should be played earlier.
+func (s Scene) result() game.CalibrateResult {
+	r := game.CalibrateResult{
+		MusicOffset:  s.Options.MusicOffset,
+		VisualOffset: s.Options.VisualOffset,
+	}
+	if s.phasesOK[audioPhase] {
+		r.MusicOffset = -s.phasesOffset[audioPhase]
+		r.OK = true
+	}
+	if s.phasesOK[visualPhase] {
+		r.VisualOffset = s.phasesOffset[visualPhase]
+		r.OK = true
+	}
+	return r
+}
+
+func (s Scene) resultMessage() string {
+	r := s.result()
+	msg := "Calibration finished. (Enter to apply, Esc to cancel)\n"
+	if s.phasesOK[audioPhase] {
+		msg += fmt.Sprintf("Music offset: %dms\n", r.MusicOffset)
+	} else {
+		msg += "Music offset: not enough taps\n"
+	}
+	if s.phasesOK[visualPhase] {
+		msg += fmt.Sprintf("Visual offset: %dms\n", r.VisualOffset)
+	} else {
+		msg += "Visual offset: not enough taps\n"
+	}
+	return msg
+}
+
+func (s Scene) Draw(dst draws.Image) {
+	if s.flash {
+		s.flashSprite.Draw(dst)
+	}
+
+	msg := s.phasesMessage[s.phase]
+	if s.phase == resultPhase {
+		msg = s.resultMessage()
+	} else if s.beatIndex >= 0 && s.beatIndex < beatCount {
+		msg += fmt.Sprintf("\n%d / %d", s.beatIndex+1, beatCount)
+	}
+	t := draws.NewText(msg)
+	t.Locate(plays.ScreenSizeX/2, 0.8*plays.ScreenSizeY, draws.CenterMiddle)
+	t.Draw(dst)
+}
+
+func (Scene) WindowTitle() string { return "gosu | Calibration" }
+
+func (s Scene) DebugString() string {
+	var taps [2]int
+	for phase, ts := range s.phasesTaps {
+		taps[phase] = len(ts)
+	}
+	return fmt.Sprintf("Phase: %d\nTaps: %v\n", s.phase, taps)
+}
@@ -1,13 +1,23 @@
 package game
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/hndada/gosu/audios"
 	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/drum"
+	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
+	"github.com/hndada/gosu/util"
 )
 
 // A function which load database should not load the entire file system into memory.
@@ -18,10 +28,14 @@ import (
 type Database struct {
 	Chart  []ChartRow
 	Replay []ReplayRow
+
+	// Musics are analyzed infos of music files by MusicHash,
+	// such as peaks for drawing waveforms. They persist in a file.
+	Musics map[string]MusicInfo
 }
 
 type FSFile struct {
-	FS   fs.FS
+	FS   fs.FS  `json:"-"`
 	Name string // Includes path
 }
 
@@ -35,6 +49,23 @@ type ChartRow struct {
 	SubMode            int
 	ChartHash          string
 	Level              float64
+	PreviewTime        int32 // Negative when not set.
+
+	// MusicGain normalizes loudness of the music, in dB.
+	// Music files are identified by MusicHash.
+	MusicFilename string
+	MusicHash     string
+	MusicGain     float64
+
+	// Tags contains both tags from the chart file and pattern tags.
+	Tags []string
+
+	// Section-wise values are for drawing graphs at song select.
+	Difficulties  []float64
+	Densities     []float64
+	PeakNPS       float64
+	DrainTime     int32
+	LongNoteRatio float64
 }
 
 func (c ChartRow) MusicString() string {
@@ -45,9 +76,36 @@ func (c ChartRow) LevelString() string {
 	return fmt.Sprintf("Level %.0f", c.Level)
 }
 
+// Rows are labeled by mode, since a converted chart
+// is listed in both piano and drum mode.
 func (c ChartRow) ChartString() string {
 	// return fmt.Sprintf("[Lv. %.0f] %s [%s]", c.Level, c.MusicName, c.ChartName) // [Lv. %4.2f]
-	return fmt.Sprintf("%s [%s]", c.MusicName, c.ChartName)
+	return fmt.Sprintf("%s [%s] (%s)", c.MusicName, c.ChartName, c.ModeString())
+}
+
+func (c ChartRow) ModeString() string {
+	switch c.Mode {
+	case plays.ModePiano:
+		return fmt.Sprintf("Piano %dK", c.SubMode)
+	case plays.ModeDrum:
+		return "Drum"
+	case plays.ModeSing:
+		return "Sing"
+	}
+	return "Unknown"
+}
+
+// Music file is located in the same directory with the chart file.
+func (c ChartRow) MusicPath() string {
+	if c.MusicFilename == "" {
+		return ""
+	}
+	return path.Join(path.Dir(c.Name), c.MusicFilename)
+}
+
+func (c ChartRow) DrainTimeString() string {
+	sec := c.DrainTime / 1000
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
 }
 
 func (c ChartRow) IsMatch(query string) bool {
@@ -56,9 +114,28 @@ func (c ChartRow) IsMatch(query string) bool {
 			return true
 		}
 	}
+	return c.HasTag(query)
+}
+
+// Tags are case-insensitive.
+func (c ChartRow) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
 	return false
 }
 
+func (c *ChartRow) addTags(tags []string) {
+	for _, t := range tags {
+		if t == "" || c.HasTag(t) {
+			continue
+		}
+		c.Tags = append(c.Tags, t)
+	}
+}
+
 type ReplayRow struct {
 	FSFile
 	ChartHash string
@@ -74,11 +151,20 @@ func NewDatabase(root fs.FS) (*Database, error) {
 		if err != nil {
 			return nil, fmt.Errorf("NewDatabase music: %w", err)
 		}
-		db, err := newChartDB(fsys)
+		cache := loadChartCache(root)
+		musicCache := loadMusicCache(root)
+		db, charts, musics, err := newChartDB(fsys, cache, musicCache)
 		if err != nil {
 			return nil, err
 		}
 		dbs.Chart = db
+		dbs.Musics = musics
+		if err := saveChartCache(charts); err != nil {
+			fmt.Printf("Failed to save %s: %v\n", chartsFilename, err)
+		}
+		if err := saveMusicCache(musics); err != nil {
+			fmt.Printf("Failed to save %s: %v\n", musicsFilename, err)
+		}
 	}
 
 	if _, err := fs.Stat(root, "replays"); err == nil {
@@ -95,15 +181,75 @@ func NewDatabase(root fs.FS) (*Database, error) {
 	return &dbs, nil
 }
 
+// chartCache has rows of a chart file. Rows are reused while neither
+// the chart file nor its music file has been modified since, hence
+// charts are not parsed and music files are not read at every launch.
+// Rows are empty when the file is not a chart to be listed.
+type chartCache struct {
+	ModTime      time.Time
+	MusicModTime time.Time
+	Rows         []ChartRow
+}
+
+const chartsFilename = "charts.json"
+
+func loadChartCache(fsys fs.FS) map[string]chartCache {
+	cache := make(map[string]chartCache)
+	if data, err := fs.ReadFile(fsys, chartsFilename); err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			fmt.Printf("Failed to unmarshal %s: %v\n", chartsFilename, err)
+		}
+	}
+	return cache
+}
+
+// Rows of charts no longer existing are dropped from the cache.
+func saveChartCache(charts map[string]chartCache) error {
+	data, err := json.Marshal(charts)
+	if err != nil {
+		return fmt.Errorf("marshal chart rows: %w", err)
+	}
+	return os.WriteFile(chartsFilename, data, 0644)
+}
+
+// fileModTime returns zero time when the file does not exist.
+func fileModTime(fsys fs.FS, name string) time.Time {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// isFresh reports whether the rows are still of the files.
+// Rows are not fresh either when the music info is missing.
+func (cc chartCache) isFresh(fsys fs.FS, modTime time.Time, musicCache map[string]MusicInfo) bool {
+	if !cc.ModTime.Equal(modTime) {
+		return false
+	}
+	if len(cc.Rows) == 0 || cc.Rows[0].MusicFilename == "" {
+		return true
+	}
+	row := cc.Rows[0]
+	if _, ok := musicCache[row.MusicHash]; !ok {
+		return false
+	}
+	return cc.MusicModTime.Equal(fileModTime(fsys, row.MusicPath()))
+}
+
 // NewMusicDB reads only first depth of root for directory.
 // Then it will read all charts in each directory.
-func newChartDB(fsys fs.FS) ([]ChartRow, error) {
+// Rows of charts and music infos in use are returned, to be cached.
+func newChartDB(fsys fs.FS, cache map[string]chartCache, musicCache map[string]MusicInfo) ([]ChartRow, map[string]chartCache, map[string]MusicInfo, error) {
 	dirs, err := fs.ReadDir(fsys, ".")
 	if err != nil {
-		return nil, fmt.Errorf("newChartDB dirs: %w", err)
+		return nil, nil, nil, fmt.Errorf("newChartDB dirs: %w", err)
 	}
 
 	var db []ChartRow
+	charts := make(map[string]chartCache)
+	hashes := make(map[string]string) // Charts in a set share music.
+	musics := make(map[string]MusicInfo)
 	for _, dir := range dirs {
 		if !dir.IsDir() {
 			continue
@@ -111,7 +257,7 @@ func newChartDB(fsys fs.FS) ([]ChartRow, error) {
 		dname := dir.Name()
 		fs, err := fs.ReadDir(fsys, dname)
 		if err != nil {
-			return nil, fmt.Errorf("newChartDB dir: %w", err)
+			return nil, nil, nil, fmt.Errorf("newChartDB dir: %w", err)
 		}
 
 		for _, f := range fs {
@@ -120,33 +266,209 @@ func newChartDB(fsys fs.FS) ([]ChartRow, error) {
 			}
 
 			ext := filepath.Ext(f.Name())
-			if ext != ".osu" {
+			if ext != ".osu" && ext != ".txt" {
 				continue
 			}
 
 			fname := path.Join(dname, f.Name())
-			c, err := plays.NewChartHeaderFromFile(fsys, fname)
+			info, err := f.Info()
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				continue
 			}
+			cc, ok := cache[fname]
+			if ok && cc.isFresh(fsys, info.ModTime(), musicCache) {
+				for i, row := range cc.Rows {
+					cc.Rows[i].FS = fsys
+					if row.MusicHash != "" {
+						hashes[row.MusicPath()] = row.MusicHash
+						musics[row.MusicHash] = musicCache[row.MusicHash]
+					}
+				}
+			} else {
+				rows, err := newChartRows(fsys, fname, hashes, musicCache, musics)
+				if err != nil {
+					// .txt may be just a plain text, not an UltraStar chart.
+					if ext != ".txt" {
+						fmt.Printf("Error: %v\n", err)
+					}
+				}
+				cc = chartCache{ModTime: info.ModTime(), Rows: rows}
+				if len(rows) > 0 && rows[0].MusicFilename != "" {
+					cc.MusicModTime = fileModTime(fsys, rows[0].MusicPath())
+				}
+			}
+			charts[fname] = cc
+			db = append(db, cc.Rows...)
+		}
+	}
+	return db, charts, musics, nil
+}
+
+// newChartRows parses the chart file, and loads infos of its music.
+// Converted chart has a row for each mode.
+func newChartRows(fsys fs.FS, fname string, hashes map[string]string, musicCache, musics map[string]MusicInfo) ([]ChartRow, error) {
+	c, err := plays.NewChartHeaderFromFile(fsys, fname)
+	if err != nil {
+		return nil, err
+	}
+	// Modes not supported, such as osu!catch, are not listed.
+	if c.Mode < 0 {
+		return nil, nil
+	}
 
-			db = append(db, ChartRow{
-				FSFile: FSFile{
-					FS:   fsys,
-					Name: fname,
-				},
-				MusicName: c.MusicName,
-				Artist:    c.Artist,
-				ChartName: c.ChartName,
-				Mode:      c.Mode,
-				SubMode:   c.SubMode,
-				ChartHash: c.ChartHash,
-				// Level:     c.Level,
-			})
+	row := ChartRow{
+		FSFile: FSFile{
+			FS:   fsys,
+			Name: fname,
+		},
+		MusicName: c.MusicName,
+		Artist:    c.Artist,
+		ChartName: c.ChartName,
+		Mode:      c.Mode,
+		SubMode:   c.SubMode,
+		ChartHash: c.ChartHash,
+		// Level:     c.Level,
+		PreviewTime: c.PreviewTime,
+	}
+	if c.MusicFilename != "" {
+		mname := path.Join(path.Dir(fname), c.MusicFilename)
+		hash, ok := hashes[mname]
+		if !ok {
+			hash, err = loadMusicInfo(fsys, mname, musicCache, musics)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			hashes[mname] = hash
 		}
+		row.MusicFilename = c.MusicFilename
+		row.MusicHash = hash
+		row.MusicGain = musics[hash].Gain
 	}
-	return db, nil
+	row.addTags(c.Tags)
+	rows := []ChartRow{row}
+	// Converted chart is listed in drum mode as well.
+	if c.Converted {
+		row.addTags([]string{"converted"})
+		rows[0] = row
+		drumRow := row
+		drumRow.Mode = plays.ModeDrum
+		drumRow.SubMode = 4
+		drumRow.Tags = append([]string{}, row.Tags...)
+		rows = append(rows, drumRow)
+	}
+	for i := range rows {
+		if err := rows[i].setSections(fsys, fname); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+	return rows, nil
+}
+
+// MusicInfo is analyzed from a music file. Since the whole file
+// is decoded for it, it is cached in a file by MusicHash.
+type MusicInfo struct {
+	Gain  float64   // in dB
+	Peaks []float64 // Each peak spans audios.PeakBinDuration.
+}
+
+const musicsFilename = "musics.json"
+
+func loadMusicCache(fsys fs.FS) map[string]MusicInfo {
+	cache := make(map[string]MusicInfo)
+	if data, err := fs.ReadFile(fsys, musicsFilename); err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			fmt.Printf("Failed to unmarshal %s: %v\n", musicsFilename, err)
+		}
+	}
+	return cache
+}
+
+// Infos of music no longer in use are dropped from the cache.
+func saveMusicCache(musics map[string]MusicInfo) error {
+	data, err := json.Marshal(musics)
+	if err != nil {
+		return fmt.Errorf("marshal music infos: %w", err)
+	}
+	return os.WriteFile(musicsFilename, data, 0644)
+}
+
+// loadMusicInfo puts the info of the music file to musics,
+// and returns its hash. The file is analyzed only when
+// the cache has no info of the hash.
+func loadMusicInfo(fsys fs.FS, name string, cache, musics map[string]MusicInfo) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("loadMusicInfo: %w", err)
+	}
+	hash := util.MD5(data)
+	if mi, ok := cache[hash]; ok {
+		musics[hash] = mi
+		return hash, nil
+	}
+	mi, err := newMusicInfo(data, filepath.Ext(name))
+	if err != nil {
+		return hash, fmt.Errorf("loadMusicInfo: %w", err)
+	}
+	musics[hash] = mi
+	return hash, nil
+}
+
+// newMusicInfo analyzes loudness of the music to normalize it,
+// and records peaks for a waveform along with it.
+func newMusicInfo(data []byte, ext string) (MusicInfo, error) {
+	rc := io.NopCloser(bytes.NewReader(data))
+	streamer, format, err := audios.Decode(rc, ext)
+	if err != nil {
+		return MusicInfo{}, err
+	}
+	defer streamer.Close()
+	pr := audios.NewPeakRecorder(streamer, format.SampleRate)
+	lufs := audios.Loudness(pr, format.SampleRate)
+	return MusicInfo{
+		Gain:  audios.LoudnessGain(lufs),
+		Peaks: pr.Peaks(),
+	}, nil
+}
+
+// setSections loads notes of the chart, since ChartHeader
+// tells nothing about where the hard parts are.
+func (c *ChartRow) setSections(fsys fs.FS, name string) error {
+	switch c.Mode {
+	case plays.ModePiano:
+		chart, err := piano.NewChart(fsys, name, piano.Mods{})
+		if err != nil {
+			return fmt.Errorf("setSections: %w", err)
+		}
+		c.Level = chart.Level()
+		c.Difficulties = chart.Difficulties()
+		c.Densities = chart.Densities()
+		c.PeakNPS = chart.PeakNPS()
+		c.DrainTime = chart.DrainTime()
+		c.LongNoteRatio = chart.LongNoteRatio()
+		c.addTags(chart.PatternTags())
+	case plays.ModeDrum:
+		chart, err := drum.NewChart(fsys, name, drum.Mods{})
+		if err != nil {
+			return fmt.Errorf("setSections: %w", err)
+		}
+		c.Level = chart.Level()
+		c.Difficulties = chart.Difficulties()
+		c.Densities = chart.Densities()
+		c.PeakNPS = chart.PeakNPS()
+		c.DrainTime = chart.DrainTime()
+	case plays.ModeSing:
+		chart, err := sing.NewChart(fsys, name, sing.Mods{})
+		if err != nil {
+			return fmt.Errorf("setSections: %w", err)
+		}
+		c.Level = chart.Level()
+		c.Difficulties = chart.Difficulties()
+		c.Densities = chart.Densities()
+		c.PeakNPS = chart.PeakNPS()
+		c.DrainTime = chart.DrainTime()
+	}
+	return nil
 }
 
 func newReplayDB(fsys fs.FS) ([]ReplayRow, error) {
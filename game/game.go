@@ -32,10 +32,13 @@ type Game struct {
 	Options   *Options
 	Handlers  *Handlers
 	Database  *Database
+	Records   *Records
 
-	SceneSelect  Scene
-	ScenePlay    Scene
-	CurrentScene Scene
+	SceneSelect     Scene
+	ScenePlay       Scene
+	SceneCalibrate  Scene
+	SceneKeyBinding Scene
+	CurrentScene    Scene
 }
 
 func NewGame(fsys fs.FS) (*Game, error) {
@@ -43,31 +46,46 @@ func NewGame(fsys fs.FS) (*Game, error) {
 		KeyboardState: &ui.KeyboardState{},
 	}
 
-	if resFS, err := fs.Sub(fsys, "resources"); err == nil {
-		s.Resources = NewResources(resFS)
-	} else {
-		s.Resources = NewResources(resources.DefaultFS)
+	resFS, err := fs.Sub(fsys, "resources")
+	if err != nil {
+		resFS = resources.DefaultFS
 	}
+	s.Resources = NewResources(resFS)
 
 	// NewOptions is always called, as there
 	// might be omitted fields on a local option file.
 	s.Options = NewOptions()
-	if data, err := fs.ReadFile(fsys, "options.json"); err == nil {
+	if data, err := fs.ReadFile(fsys, optionsFilename); err == nil {
 		if err := json.Unmarshal(data, s.Options); err != nil {
-			fmt.Printf("Failed to unmarshal options.json: %v\n", err)
+			fmt.Printf("Failed to unmarshal %s: %v\n", optionsFilename, err)
 		}
 	}
 	// It is always necessary to set derived values.
 	s.Options.Normalize()
 	s.Options.Piano.SetDerived()
+	s.Options.Drum.SetDerived()
+	s.Options.Sing.SetDerived()
+
+	// Skin is imported after options, since it overrides some of them.
+	if name := s.Options.OsuSkinPath; name != "" {
+		skinFS, err := fs.Sub(fsys, name)
+		if err == nil {
+			err = piano.ImportOsuSkin(skinFS, s.Resources.Piano, s.Options.Piano)
+		}
+		if err != nil {
+			fmt.Printf("Failed to import osu! skin %s: %v\n", name, err)
+		}
+	}
 
-	s.Handlers = NewHandlers(s.Options, s.KeyboardState)
+	sp := newInterfaceSoundPlayer(resFS, &s.Options.SoundVolumeScale)
+	s.Handlers = NewHandlers(s.Options, s.KeyboardState, sp)
 
 	dbs, err := NewDatabase(fsys)
 	if err != nil {
 		panic(err)
 	}
 	s.Database = dbs
+	s.Records = NewRecords(fsys)
 
 	// issue: It jitters when Vsync is enabled.
 	ebiten.SetTPS(ebiten.SyncWithFPS)
@@ -90,7 +108,43 @@ func (g *Game) Update() error {
 		g.CurrentScene = g.ScenePlay
 		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
 		// debug.SetGCPercent(0)
-	case piano.Scorer:
+	case CalibrateArgs:
+		g.SceneCalibrate, err = g.SceneCalibrate.New(g, args)
+		if err != nil {
+			fmt.Println("calibrate scene error:", err)
+			return nil
+		}
+		g.CurrentScene = g.SceneCalibrate
+		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
+	case CalibrateResult:
+		if args.OK {
+			g.Options.MusicOffset = args.MusicOffset
+			g.Options.VisualOffset = args.VisualOffset
+			if err := g.Options.Save(); err != nil {
+				fmt.Println("failed to save options:", err)
+			}
+		}
+		g.CurrentScene = g.SceneSelect
+		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
+	case KeyBindingArgs:
+		g.SceneKeyBinding, err = g.SceneKeyBinding.New(g, args)
+		if err != nil {
+			fmt.Println("key binding scene error:", err)
+			return nil
+		}
+		g.CurrentScene = g.SceneKeyBinding
+		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
+	case KeyBindingResult:
+		// Mappings are already applied and saved in the scene.
+		g.CurrentScene = g.SceneSelect
+		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
+	case PlayQuit:
+		g.CurrentScene = g.SceneSelect
+		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
+	case PlayResult:
+		if err := g.Records.Add(args); err != nil {
+			fmt.Println("failed to save record:", err)
+		}
 		g.CurrentScene = g.SceneSelect
 		ebiten.SetWindowTitle(g.CurrentScene.WindowTitle())
 		// debug.SetGCPercent(100)
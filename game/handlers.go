@@ -1,6 +1,7 @@
 package game
 
 import (
+	"github.com/hndada/gosu/audios"
 	"github.com/hndada/gosu/input"
 	"github.com/hndada/gosu/plays"
 	"github.com/hndada/gosu/ui"
@@ -46,8 +47,8 @@ type Handlers struct {
 	SpeedScales []ui.KeyNumberHandler[float64]
 }
 
-func NewHandlers(opts *Options, kbs *ui.KeyboardState) *Handlers {
-	return &Handlers{
+func NewHandlers(opts *Options, kbs *ui.KeyboardState, sp *audios.SoundPlayer) *Handlers {
+	hs := &Handlers{
 		MusicVolume:          newMusicVolumeHandler(opts, kbs),
 		SoundVolumeScale:     newSoundVolumeScaleHandler(opts, kbs),
 		MusicOffset:          newMusicOffsetHandler(opts, kbs),
@@ -58,6 +59,19 @@ func NewHandlers(opts *Options, kbs *ui.KeyboardState) *Handlers {
 		SubMode:     newSubModeHandlers(opts, kbs)[0],
 		SpeedScales: newSpeedScaleHandlers(opts, kbs),
 	}
+
+	// All handlers share a sound player for interface sounds.
+	hs.MusicVolume.SoundPlayer = sp
+	hs.SoundVolumeScale.SoundPlayer = sp
+	hs.MusicOffset.SoundPlayer = sp
+	hs.BackgroundBrightness.SoundPlayer = sp
+	hs.DebugPrint.SoundPlayer = sp
+	hs.Mode.SoundPlayer = sp
+	hs.SubMode.SoundPlayer = sp
+	for i := range hs.SpeedScales {
+		hs.SpeedScales[i].SoundPlayer = sp
+	}
+	return hs
 }
 
 // Control contains sound filename.
@@ -158,8 +172,8 @@ func newModeHandler(opts *Options, kbs *ui.KeyboardState) ui.KeyNumberHandler[in
 	return ui.KeyNumberHandler[int]{
 		NumberController: ui.NumberController[int]{
 			Value: &opts.Mode,
-			Min:   plays.ModeAll,
-			Max:   plays.ModePiano,
+			Min:   plays.ModePiano,
+			Max:   plays.ModeSing,
 			Unit:  1,
 		},
 		KeyListener: *ui.NewKeyListener(
@@ -187,11 +201,15 @@ func newSubModeHandlers(opts *Options, kbs *ui.KeyboardState) []ui.KeyNumberHand
 	}
 
 	hs := make([]ui.KeyNumberHandler[int], 0, 3)
-	for mode := plays.ModePiano; mode <= plays.ModePiano; mode++ {
+	for mode := plays.ModePiano; mode <= plays.ModeSing; mode++ {
 		min, max := 0, 0
 		switch mode {
 		case plays.ModePiano:
 			min, max = 4, 10
+		case plays.ModeDrum:
+			min, max = 4, 4
+		case plays.ModeSing:
+			min, max = 1, 1
 		}
 
 		hs = append(hs, ui.KeyNumberHandler[int]{
@@ -227,11 +245,15 @@ func newSpeedScaleHandlers(opts *Options, kbs *ui.KeyboardState) []ui.KeyNumberH
 	}
 
 	hs := make([]ui.KeyNumberHandler[float64], 0, 3)
-	for mode := plays.ModePiano; mode <= plays.ModePiano; mode++ {
+	for mode := plays.ModePiano; mode <= plays.ModeSing; mode++ {
 		var ptr *float64
 		switch mode {
 		case plays.ModePiano:
 			ptr = &opts.Piano.SpeedScale
+		case plays.ModeDrum:
+			ptr = &opts.Drum.SpeedScale
+		case plays.ModeSing:
+			ptr = &opts.Sing.SpeedScale
 		}
 		hs = append(hs, ui.KeyNumberHandler[float64]{
 			NumberController: ui.NumberController[float64]{
@@ -0,0 +1,192 @@
+package keybind
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/input"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/ui"
+)
+
+// Key binding edits piano key mappings for each key count.
+// A player selects a lane, then presses a key to bind it.
+// A new mapping is applied and saved as soon as a key is bound.
+const (
+	minKeyCount = 1
+	maxKeyCount = 10
+)
+
+const (
+	laneHeight   = 200
+	lanePosition = plays.ScreenSizeY / 2 // Vertical center of lanes.
+)
+
+// Modifier keys are used along with other keys as shortcuts.
+var modifierKeys = []input.Key{
+	input.KeyShiftLeft, input.KeyShiftRight,
+	input.KeyControlLeft, input.KeyControlRight,
+	input.KeyAltLeft, input.KeyAltRight,
+	input.KeyMetaLeft, input.KeyMetaRight,
+}
+
+type Scene struct {
+	*game.Game
+	keyCount   int
+	lane       int
+	names      []string // Key names of current key count.
+	listening  bool     // Waiting for a key to bind.
+	message    string
+	laneSprite draws.Sprite
+}
+
+func (Scene) New(g *game.Game, _ game.Args) (game.Scene, error) {
+	s := &Scene{Game: g}
+
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	s.laneSprite = draws.NewSprite(img)
+
+	keyCount := g.Options.SubMode
+	if keyCount < minKeyCount || keyCount > maxKeyCount {
+		keyCount = 4
+	}
+	s.setKeyCount(keyCount)
+	return s, nil
+}
+
+func (s *Scene) setKeyCount(keyCount int) {
+	s.keyCount = keyCount
+	s.lane = 0
+	s.names = append([]string(nil), s.Options.Piano.KeyMappings[keyCount]...)
+	// Lanes without keys are left blank to be bound.
+	for len(s.names) < keyCount {
+		s.names = append(s.names, "")
+	}
+	s.names = s.names[:keyCount]
+}
+
+func (s *Scene) Update() any {
+	if s.listening {
+		if ui.IsEscapeJustPressed() {
+			s.listening = false
+			s.message = "Canceled."
+			return nil
+		}
+		for k := input.Key(0); k < input.KeyFinal; k++ {
+			if input.IsKeyJustPressed(k) {
+				s.bind(k)
+				break
+			}
+		}
+		return nil
+	}
+
+	switch {
+	case ui.IsEscapeJustPressed():
+		return game.KeyBindingResult{}
+	case ui.IsEnterJustPressed():
+		s.listening = true
+		s.message = fmt.Sprintf("Press a key for lane %d. (Esc to cancel)", s.lane+1)
+	case input.IsKeyJustPressed(input.KeyArrowLeft):
+		s.lane = (s.lane + s.keyCount - 1) % s.keyCount
+	case input.IsKeyJustPressed(input.KeyArrowRight):
+		s.lane = (s.lane + 1) % s.keyCount
+	case input.IsKeyJustPressed(input.KeyArrowUp):
+		s.setKeyCount(min(s.keyCount+1, maxKeyCount))
+		s.message = ""
+	case input.IsKeyJustPressed(input.KeyArrowDown):
+		s.setKeyCount(max(s.keyCount-1, minKeyCount))
+		s.message = ""
+	}
+	return nil
+}
+
+// bind keeps listening when the key is not allowed.
+// A key already bound to another lane is swapped,
+// so that the mapping stays free of conflicts.
+func (s *Scene) bind(k input.Key) {
+	name := input.KeyToName(k)
+	for _, mk := range modifierKeys {
+		if k == mk {
+			s.message = fmt.Sprintf("Modifier keys cannot be bound: %s", name)
+			return
+		}
+	}
+	// Function keys are reserved for shortcuts.
+	if k >= input.KeyF1 && k <= input.KeyF12 {
+		s.message = fmt.Sprintf("Function keys cannot be bound: %s", name)
+		return
+	}
+
+	names := append([]string(nil), s.names...)
+	s.message = fmt.Sprintf("Lane %d: %s", s.lane+1, name)
+	for k2, name2 := range names {
+		if k2 != s.lane && name2 == name {
+			names[k2] = names[s.lane]
+			s.message = fmt.Sprintf("%s was bound to lane %d; swapped.", name, k2+1)
+		}
+	}
+	names[s.lane] = name
+	s.names = names
+	s.listening = false
+
+	// A mapping with blank lanes is not applied until all lanes are bound.
+	if err := s.Options.Piano.SetKeyMapping(s.keyCount, names); err != nil {
+		s.message = fmt.Sprintf("Not applied: %v", err)
+		return
+	}
+	if err := s.Options.Save(); err != nil {
+		s.message = fmt.Sprintf("Applied, but failed to save: %v", err)
+		return
+	}
+	s.lane = (s.lane + 1) % s.keyCount
+}
+
+func (s Scene) Draw(dst draws.Image) {
+	opts := s.Options.Piano
+	xs, ws := opts.KeyLayout(s.keyCount)
+	order := opts.KeyOrder(s.keyCount)
+	for k := range xs {
+		clr := opts.NoteColors[order[k]]
+		if k != s.lane {
+			clr.A /= 3
+		}
+		sprite := s.laneSprite
+		sprite.SetSize(ws[k]-2, laneHeight) // Gap between lanes.
+		sprite.Locate(xs[k], lanePosition, draws.CenterMiddle)
+		sprite.ColorScale.ScaleWithColor(clr)
+		sprite.Draw(dst)
+
+		name := s.names[k]
+		if s.listening && k == s.lane {
+			name = "?"
+		}
+		t := draws.NewText(name)
+		t.Locate(xs[k], lanePosition+laneHeight/2+20, draws.CenterMiddle)
+		t.Draw(dst)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Key count: %d (Up/Down)", s.keyCount),
+		"Select a lane with Left/Right, then press Enter to bind. (Esc to back)",
+	}
+	t := draws.NewText(strings.Join(lines, "\n"))
+	t.Locate(plays.ScreenSizeX/2, 0.15*plays.ScreenSizeY, draws.CenterMiddle)
+	t.Draw(dst)
+
+	if s.message != "" {
+		t := draws.NewText(s.message)
+		t.Locate(plays.ScreenSizeX/2, 0.8*plays.ScreenSizeY, draws.CenterMiddle)
+		t.Draw(dst)
+	}
+}
+
+func (Scene) WindowTitle() string { return "gosu | Key binding" }
+
+func (s Scene) DebugString() string {
+	return fmt.Sprintf("Key mapping: %v\n", s.names)
+}
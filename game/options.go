@@ -1,13 +1,17 @@
 package game
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hndada/gosu/draws"
 	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/drum"
 	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
 )
 
 // Options passed to each scene.
@@ -34,6 +38,7 @@ type Options struct {
 	ResourcesPaths []string
 	MusicPaths     []string
 	ReplaysPaths   []string
+	OsuSkinPath    string // osu! skin directory imported for piano mode, if set.
 
 	// screenSize is the logical size of the screen, and
 	// Resolution is the physical size of the screen.
@@ -46,6 +51,12 @@ type Options struct {
 	MusicVolume      float64
 	SoundVolumeScale float64
 	MusicOffset      int32
+	VisualOffset     int32 // Positive value makes notes drawn earlier.
+	PreservePitch    bool  // Music keeps its pitch at rate mods.
+
+	// ConvertKeyCount is the key count of osu!standard charts played
+	// in piano mode. Zero goes to the key count which osu! would choose.
+	ConvertKeyCount int
 
 	MouseCursorImageScale float64
 
@@ -54,6 +65,8 @@ type Options struct {
 	ErrorMeterScale float64
 	ScoreImageScale float64
 	Piano           *piano.Options
+	Drum            *drum.Options
+	Sing            *sing.Options
 }
 
 // Todo: *Options vs Options
@@ -73,6 +86,7 @@ func NewOptions() *Options {
 		MusicVolume:      0.60,
 		SoundVolumeScale: 0.60,
 		MusicOffset:      -20,
+		PreservePitch:    true,
 
 		MouseCursorImageScale: 1.0,
 
@@ -81,11 +95,26 @@ func NewOptions() *Options {
 		ErrorMeterScale: 1.0,
 		ScoreImageScale: 1.0,
 		Piano:           piano.NewOptions(),
+		Drum:            drum.NewOptions(),
+		Sing:            sing.NewOptions(),
 	}
 	opts.Piano.SetDerived()
+	opts.Drum.SetDerived()
+	opts.Sing.SetDerived()
 	return opts
 }
 
+const optionsFilename = "options.json"
+
+// Save writes options to the file, which is loaded at NewGame.
+func (opts Options) Save() error {
+	data, err := json.MarshalIndent(opts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal options: %w", err)
+	}
+	return os.WriteFile(optionsFilename, data, 0644)
+}
+
 func (opts *Options) Normalize() {
 	// Leading dot and slash is not allowed in fs.
 	for i, path := range opts.MusicPaths {
@@ -95,6 +124,13 @@ func (opts *Options) Normalize() {
 		path = strings.TrimPrefix(path, "\\")
 		opts.MusicPaths[i] = path
 	}
+	// Converted charts are played in 4 to 10 keys.
+	if opts.ConvertKeyCount < 0 {
+		opts.ConvertKeyCount = 0
+	}
+	if opts.ConvertKeyCount > 0 {
+		opts.ConvertKeyCount = max(4, min(opts.ConvertKeyCount, 10))
+	}
 }
 
 func (opts Options) DebugString() string {
@@ -105,6 +141,10 @@ func (opts Options) DebugString() string {
 	switch opts.Mode {
 	case plays.ModePiano:
 		speedScale = opts.Piano.SpeedScale
+	case plays.ModeDrum:
+		speedScale = opts.Drum.SpeedScale
+	case plays.ModeSing:
+		speedScale = opts.Sing.SpeedScale
 	}
 
 	f(&b, "FPS: %.2f\n", ebiten.ActualFPS())
@@ -114,6 +154,8 @@ func (opts Options) DebugString() string {
 	f(&b, "Music volume (Ctrl+ Left/Right): %.0f\n", opts.MusicVolume*100)
 	f(&b, "Sound volume (Alt+ Left/Right): %.0f\n", opts.SoundVolumeScale*100)
 	f(&b, "Music offset (Shift+ Left/Right): %dms\n", opts.MusicOffset)
+	f(&b, "Visual offset (F10 to calibrate): %dms\n", opts.VisualOffset)
+	f(&b, "Key bindings (F5 to edit): %v\n", opts.Piano.KeyMappings[opts.SubMode])
 	f(&b, "Background brightness: (Ctrl+ O/P): %.0f\n", opts.BackgroundBrightness*100)
 	f(&b, "Debug print (F12): %v\n", opts.DebugPrint)
 	// f(&b, "Replay (F11): %v\n", opts.Replay)
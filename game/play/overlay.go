@@ -0,0 +1,118 @@
+package play
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/input"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/ui"
+)
+
+// Pause and result screens are drawn over the play.
+// Their buttons are clicked by mouse, while keys work as well.
+const (
+	buttonWidth  = 300
+	buttonHeight = 60
+)
+
+type button struct {
+	sprite draws.Sprite
+	text   draws.Text
+	mouse  *ui.MouseListener
+}
+
+func newButton(label string, y float64) button {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	s := draws.NewSprite(img)
+	s.SetSize(buttonWidth, buttonHeight)
+	s.Locate(plays.ScreenSizeX/2, y, draws.CenterMiddle)
+
+	t := draws.NewText(label)
+	t.Locate(plays.ScreenSizeX/2, y, draws.CenterMiddle)
+
+	box := s.Box
+	return button{sprite: s, text: t, mouse: ui.NewMouseListener(&box)}
+}
+
+// update should be called at every update, even when the result
+// is not used, so that the listener keeps track of the buttons.
+func (b button) update() (clicked bool) {
+	b.mouse.Update()
+	return b.mouse.IsClicked(input.MouseButtonLeft)
+}
+
+func (b button) Draw(dst draws.Image) {
+	s := b.sprite
+	if b.mouse.IsCursorEntered() {
+		s.ColorScale.ScaleWithColor(color.NRGBA{R: 128, G: 128, B: 128, A: 224})
+	} else {
+		s.ColorScale.ScaleWithColor(color.NRGBA{R: 64, G: 64, B: 64, A: 224})
+	}
+	s.Draw(dst)
+	b.text.Draw(dst)
+}
+
+func newDimSprite() draws.Sprite {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.Black)
+	s := draws.NewSprite(img)
+	s.SetSize(plays.ScreenSizeX, plays.ScreenSizeY)
+	s.ColorScale.ScaleAlpha(0.6)
+	return s
+}
+
+type pauseScreen struct {
+	dim    draws.Sprite
+	resume button
+	quit   button
+}
+
+func newPauseScreen() pauseScreen {
+	return pauseScreen{
+		dim:    newDimSprite(),
+		resume: newButton("Resume", 0.45*plays.ScreenSizeY),
+		quit:   newButton("Quit", 0.55*plays.ScreenSizeY),
+	}
+}
+
+// Both buttons are updated at once, for the same reason as button.update.
+func (ps pauseScreen) update() (resume, quit bool) {
+	return ps.resume.update(), ps.quit.update()
+}
+
+func (ps pauseScreen) Draw(dst draws.Image) {
+	ps.dim.Draw(dst)
+	ps.resume.Draw(dst)
+	ps.quit.Draw(dst)
+}
+
+type resultScreen struct {
+	dim  draws.Sprite
+	text draws.Text
+	back button
+}
+
+func newResultScreen(r game.PlayResult) resultScreen {
+	t := draws.NewText(fmt.Sprintf("Score: %.0f\nAccuracy: %.2f%%", r.Score, r.Accuracy*100))
+	t.Locate(plays.ScreenSizeX/2, 0.4*plays.ScreenSizeY, draws.CenterMiddle)
+	return resultScreen{
+		dim:  newDimSprite(),
+		text: t,
+		back: newButton("Back", 0.6*plays.ScreenSizeY),
+	}
+}
+
+func (rs resultScreen) update() (back bool) {
+	clicked := rs.back.update()
+	return clicked || ui.IsEnterJustPressed() || ui.IsEscapeJustPressed()
+}
+
+func (rs resultScreen) Draw(dst draws.Image) {
+	rs.dim.Draw(dst)
+	rs.text.Draw(dst)
+	rs.back.Draw(dst)
+}
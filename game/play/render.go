@@ -0,0 +1,71 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hndada/gosu/audios"
+	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/times"
+)
+
+// RenderAudio mixes the music and the samples of a play by the replay
+// into a WAV file, without an audio device. Samples are played at the
+// replay's key-press times as in a play. It is for making highlight audio
+// and for checking sample timing. Music offset is not applied, since
+// there is no latency to compensate in offline rendering.
+func RenderAudio(g *game.Game, args game.PlayArgs, w io.WriteSeeker) error {
+	mods, ok := args.Mods.(piano.Mods)
+	if !ok {
+		return fmt.Errorf("unsupported mods for rendering: %T", args.Mods)
+	}
+	if args.ReplayFS == nil {
+		return errors.New("replay is required for rendering")
+	}
+	c, err := piano.NewChart(args.ChartFS, args.ChartFilename, mods)
+	if err != nil {
+		return fmt.Errorf("failed to create chart: %w", err)
+	}
+	replay, _, err := plays.NewReplay(args.ReplayFS, args.ReplayFilename, c.SubMode)
+	if err != nil {
+		return fmt.Errorf("failed to load replay file: %w", err)
+	}
+
+	m, err := audios.NewMixer(w)
+	if err != nil {
+		return fmt.Errorf("failed to create mixer: %w", err)
+	}
+	s := Scene{Game: g, ChartHeader: c.ChartHeader}
+	sp := s.newSamplePlayer(args.ChartFS, c.MusicFilename)
+	sp.SetOutput(m)
+
+	rate := times.PlaybackRate()
+	mp, err := audios.NewMusicPlayerFromFile(args.ChartFS, c.MusicFilename)
+	if err != nil {
+		// Samples are rendered even without music, as in a play.
+		mp = audios.NewSilentMusicPlayer(time.Duration(c.TotalDuration()) * time.Millisecond)
+	}
+	defer mp.Close()
+	mp.SetOutput(m)
+	mp.SetGain(args.MusicGain)
+	mp.SetVolume(g.Options.MusicVolume)
+	if rate != 1 {
+		mp.SetTimeStretch(g.Options.PreservePitch)
+		mp.SetPlaybackRate(rate)
+	}
+	mp.Play()
+
+	// Output time is chart time divided by playback rate.
+	advance := func(now int32) error {
+		d := float64(now) * float64(time.Millisecond) / rate
+		return m.Advance(time.Duration(d))
+	}
+	if err := piano.RenderSamples(g.Resources.Piano, c, mods, replay, &sp, advance); err != nil {
+		return fmt.Errorf("failed to render samples: %w", err)
+	}
+	return m.Close()
+}
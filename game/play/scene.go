@@ -1,17 +1,22 @@
 package play
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
-	"path/filepath"
+	"math"
 	"time"
 
 	"github.com/hndada/gosu/audios"
 	"github.com/hndada/gosu/draws"
 	"github.com/hndada/gosu/game"
 	"github.com/hndada/gosu/input"
+	"github.com/hndada/gosu/input/voice"
 	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/drum"
 	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
 	"github.com/hndada/gosu/times"
 )
 
@@ -28,28 +33,44 @@ type Scene struct {
 
 	*plays.ChartHeader
 	play              play
+	level             float64 // for rating
 	musicPlayer       *audios.MusicPlayer
+	soundPlayer       *audios.SoundPlayer
+	backgroundSamples []plays.BackgroundSample
+	backgroundIndex   int
 	keyboard          input.KeyboardReader
+	voiceCloser       io.Closer
 	lastKeyboardState input.KeyboardState
 
 	// Timer
 	firstUpdated bool
 	startTime    time.Time
+	listenTime   time.Time // Start time of keyboard.
 	pauseTime    time.Time
 	paused       bool
 	musicOffset  int32
 	musicPlayed  bool // This really matters.
+	musicSync    musicSync
+
+	// warning is shown at the beginning, such as for missing music.
+	warning string
+
+	pauseScreen  pauseScreen
+	result       *game.PlayResult // Set when the play is finished.
+	resultScreen resultScreen
 }
 
+const warningDuration = 5 * time.Second
+
 // (*Scene, error) is typically used for regular functions that operate on struct pointers.
 // (s *Scene, err error) is typically used for methods attached to structs.
 // chartFS fs.FS, cname string, replayFS fs.FS, rname string, mods plays.Mods) (*Scene, error) {
 func (Scene) New(g *game.Game, _args game.Args) (game.Scene, error) {
 	args := _args.(game.PlayArgs)
 	s := &Scene{Game: g}
-	switch g.Options.Mode {
-	case plays.ModePiano:
-		mods := args.Mods.(piano.Mods)
+	var totalDuration int32 // for playing without music
+	switch mods := args.Mods.(type) {
+	case piano.Mods:
 		c, err := piano.NewChart(args.ChartFS, args.ChartFilename, mods)
 		if err != nil {
 			err = fmt.Errorf("failed to create chart: %w", err)
@@ -57,9 +78,12 @@ func (Scene) New(g *game.Game, _args game.Args) (game.Scene, error) {
 		}
 
 		s.ChartHeader = c.ChartHeader
-		// Todo: add default sound
-		// soft-hitnormal.wav
+		s.level = c.Level()
+		totalDuration = c.TotalDuration()
+		c.Dynamics.VisualOffset = s.Options.VisualOffset
 		sp := s.newSamplePlayer(args.ChartFS, s.MusicFilename)
+		s.soundPlayer = &sp
+		s.backgroundSamples = c.BackgroundSamples
 
 		play, err := piano.NewPlay(s.Resources.Piano, s.Options.Piano, c, mods, &sp)
 		if err != nil {
@@ -67,29 +91,95 @@ func (Scene) New(g *game.Game, _args game.Args) (game.Scene, error) {
 			return nil, err
 		}
 		s.play = play
+	case drum.Mods:
+		c, err := drum.NewChart(args.ChartFS, args.ChartFilename, mods)
+		if err != nil {
+			err = fmt.Errorf("failed to create chart: %w", err)
+			return nil, err
+		}
+
+		s.ChartHeader = c.ChartHeader
+		s.level = c.Level()
+		totalDuration = c.TotalDuration()
+		c.Dynamics.VisualOffset = s.Options.VisualOffset
+		sp := s.newSamplePlayer(args.ChartFS, s.MusicFilename)
+		s.soundPlayer = &sp
+		s.backgroundSamples = c.BackgroundSamples
+
+		play, err := drum.NewPlay(s.Resources.Drum, s.Options.Drum, c, mods, &sp)
+		if err != nil {
+			err = fmt.Errorf("failed to create play scene: %w", err)
+			return nil, err
+		}
+		s.play = play
+	case sing.Mods:
+		c, err := sing.NewChart(args.ChartFS, args.ChartFilename, mods)
+		if err != nil {
+			err = fmt.Errorf("failed to create chart: %w", err)
+			return nil, err
+		}
+
+		s.ChartHeader = c.ChartHeader
+		s.level = c.Level()
+		totalDuration = c.TotalDuration()
+		c.Dynamics.VisualOffset = s.Options.VisualOffset
+		vr, err := s.newVoiceReader(args)
+		if err != nil {
+			err = fmt.Errorf("failed to load voice: %w", err)
+			return nil, err
+		}
+
+		play, err := sing.NewPlay(s.Resources.Sing, s.Options.Sing, c, mods, vr)
+		if err != nil {
+			err = fmt.Errorf("failed to create play scene: %w", err)
+			return nil, err
+		}
+		s.play = play
+	default:
+		return nil, fmt.Errorf("unsupported mods: %T", mods)
 	}
 
+	// Silent charts and charts with missing music are played
+	// with silence, so that the clock and offset work as usual.
 	mp, err := audios.NewMusicPlayerFromFile(args.ChartFS, s.MusicFilename)
 	if err != nil {
-		err = fmt.Errorf("failed to load music file: %w", err)
-		return nil, err
+		if s.MusicFilename == "" {
+			s.warning = "No music: playing without audio."
+		} else {
+			s.warning = fmt.Sprintf("Failed to load music: playing without audio.\n(%v)", err)
+		}
+		mp = audios.NewSilentMusicPlayer(time.Duration(totalDuration) * time.Millisecond)
 	}
 	s.musicPlayer = mp
+	mp.SetGain(args.MusicGain)
 	mp.SetVolume(s.Options.MusicVolume)
+	// Time stretch is skipped at normal rate to save computation.
+	if rate := times.PlaybackRate(); rate != 1 {
+		mp.SetTimeStretch(s.Options.PreservePitch)
+		mp.SetPlaybackRate(rate)
+	}
 	s.musicOffset = s.Options.MusicOffset
+	s.pauseScreen = newPauseScreen()
 
 	var keyCount int
 	var keyNames []string
-	switch s.Options.Mode {
+	padNames := make(map[string][]string) // by controller GUID
+	switch s.Mode {
 	case plays.ModePiano:
-		keyCount = s.Options.SubMode
+		keyCount = s.SubMode
 		keyNames = s.Options.Piano.KeyMappings[keyCount]
+		for guid, m := range s.Options.Piano.GamepadMappings {
+			if names, ok := m[keyCount]; ok {
+				padNames[guid] = names
+			}
+		}
 	case plays.ModeDrum:
-		keyCount = 4
-		// keyNames = opts.Drum.Key.Mappings
+		keyCount = len(s.Options.Drum.KeyMappings)
+		keyNames = s.Options.Drum.KeyMappings
 	}
 
-	if args.ReplayFS != nil {
+	// Sing mode uses replay as recorded voice.
+	if args.ReplayFS != nil && s.Mode != plays.ModeSing {
 		kb, _, err := plays.NewReplay(args.ReplayFS, args.ReplayFilename, keyCount)
 		if err != nil {
 			err = fmt.Errorf("failed to load replay file: %w", err)
@@ -98,14 +188,52 @@ func (Scene) New(g *game.Game, _args game.Args) (game.Scene, error) {
 		s.keyboard = kb
 	} else {
 		keys := input.NamesToKeys(keyNames)
-		s.keyboard = input.NewKeyboard(keys)
+		kb := input.NewKeyboardWithGamepads(keys, padNames)
+		if s.Mode == plays.ModePiano {
+			xs, ws := s.Options.Piano.KeyLayout(keyCount)
+			kb.AddTouchSource(input.EbitenTouchSource{}, input.NewTouchLanes(xs, ws))
+		}
+		s.keyboard = kb
 	}
 
 	return s, nil
 }
 
+// Recorded voice in WAV works as a replay of sing mode.
+// Otherwise, voice is read from microphone. The song is still
+// playable without microphone, though no note would be hit.
+func (s *Scene) newVoiceReader(args game.PlayArgs) (voice.Reader, error) {
+	if args.ReplayFS == nil {
+		cr, err := voice.NewCaptureReader()
+		switch {
+		case errors.Is(err, voice.ErrNoCaptureTool):
+			s.warning = "No tool to record the microphone: playing without voice.\nInstall arecord (alsa-utils) or rec (sox) to sing."
+			return voice.SilentReader{}, nil
+		case err != nil:
+			s.warning = fmt.Sprintf("No microphone: playing without voice.\n(%v)", err)
+			return voice.SilentReader{}, nil
+		}
+		s.voiceCloser = cr
+		return cr, nil
+	}
+	f, err := args.ReplayFS.Open(args.ReplayFilename)
+	if err != nil {
+		return nil, err
+	}
+	vr, err := voice.NewWAVReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.voiceCloser = vr
+	return vr, nil
+}
+
 func (s Scene) newSamplePlayer(fsys fs.FS, musicFilename string) audios.SoundPlayer {
 	sp := audios.NewSoundPlayer(&s.Options.SoundVolumeScale)
+	if s.SamplesMatchPlaybackRate {
+		sp.PlaybackRate = times.PlaybackRate()
+	}
 	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -124,8 +252,7 @@ func (s Scene) newSamplePlayer(fsys fs.FS, musicFilename string) audios.SoundPla
 			return nil
 		}
 
-		switch ext := filepath.Ext(path); ext {
-		case ".wav", ".ogg", ".mp3", ".OGG", ".WAV", ".MP3":
+		if audios.IsAudioFile(path) {
 			sp.AddFile(fsys, path)
 		}
 		return nil
@@ -179,9 +306,7 @@ func (s *Scene) SetMusicOffset(newOffset int32) {
 func (s *Scene) firstUpdate() {
 	const wait = 1800 * time.Millisecond
 	s.startTime = times.Now().Add(wait)
-	if kb, ok := s.keyboard.(*input.Keyboard); ok {
-		kb.Listen(s.startTime)
-	}
+	s.listen()
 	// s.startTime = times.Now() // TODO: OK to comment out?
 }
 
@@ -190,6 +315,33 @@ func (s *Scene) Update() any {
 		s.firstUpdate()
 		s.firstUpdated = true
 	}
+	if s.result != nil {
+		if s.resultScreen.update() {
+			return *s.result
+		}
+		return nil
+	}
+
+	if input.IsKeyJustPressed(input.KeyTab) {
+		if s.paused {
+			s.Resume()
+		} else {
+			s.Pause()
+		}
+	} else if s.paused {
+		switch resume, quit := s.pauseScreen.update(); {
+		case resume:
+			s.Resume()
+		case quit:
+			s.Close()
+			s.musicPlayer.Close()
+			return game.PlayQuit{}
+		}
+	}
+	s.Handlers.MusicOffset.Handle()
+	if s.Options.MusicOffset != s.musicOffset {
+		s.SetMusicOffset(s.Options.MusicOffset)
+	}
 
 	// Use unified time.
 	now := s.now()
@@ -202,18 +354,75 @@ func (s *Scene) Update() any {
 		s.musicPlayer.Play()
 		s.musicPlayed = true
 	}
+	if s.musicPlayed && !s.paused {
+		s.sync(now)
+		now = s.now()
+		nowMS = int32(now.Milliseconds())
+	}
+	if !s.paused {
+		s.playBackgroundSamples(nowMS)
+	}
 
 	// kss's length is mostly 1.
-	kss := s.keyboard.Read(now)
+	kss := s.readKeyboard(now)
 	kss = append([]input.KeyboardState{s.lastKeyboardState}, kss...)
 	kas := plays.KeyboardActions(kss)
 	r := s.play.Update(nowMS, kas)
 	s.lastKeyboardState = kss[len(kss)-1]
 
 	// s.PlaySounds()
+	switch scorer := r.(type) {
+	case piano.Scorer:
+		s.finish(s.playResult(scorer.Score, scorer.Accuracy()))
+		return nil
+	case drum.Scorer:
+		s.finish(s.playResult(scorer.Score, scorer.Accuracy()))
+		return nil
+	case sing.Scorer:
+		s.finish(s.playResult(scorer.Score, scorer.Accuracy()))
+		return nil
+	}
 	return r
 }
 
+// Result is returned when the player leaves the result screen.
+func (s *Scene) finish(r game.PlayResult) {
+	s.Close()
+	s.result = &r
+	s.resultScreen = newResultScreen(r)
+}
+
+// Background samples are scheduled a bit ahead with delay,
+// so that they are played on time regardless of the update rate.
+func (s *Scene) playBackgroundSamples(now int32) {
+	const lookahead = 50 // in millisecond
+	if s.soundPlayer == nil {
+		return
+	}
+	for ; s.backgroundIndex < len(s.backgroundSamples); s.backgroundIndex++ {
+		bs := s.backgroundSamples[s.backgroundIndex]
+		if bs.Time >= now+lookahead {
+			break
+		}
+		// Delay is in real time, while sample time is in chart time.
+		d := float64(bs.Time-now) * float64(time.Millisecond) / times.PlaybackRate()
+		for _, name := range bs.Filenames {
+			s.soundPlayer.PlayWithDelay(name, bs.Volume, time.Duration(d))
+		}
+	}
+}
+
+func (s Scene) playResult(score, acc float64) game.PlayResult {
+	return game.PlayResult{
+		ChartHash: s.ChartHash,
+		Mode:      s.Mode,
+		SubMode:   s.SubMode,
+		Level:     s.level,
+		Score:     score,
+		Accuracy:  acc,
+	}
+}
+
 // func (s Scene) PlaySounds() {
 // 	for _, samp := range s.play.SampleBuffer() {
 // 		vol := samp.Volume * s.Audio.SoundVolumeScale
@@ -224,6 +433,9 @@ func (s *Scene) Update() any {
 func (s *Scene) Pause() {
 	s.pauseTime = times.Now()
 	s.musicPlayer.Pause()
+	if s.soundPlayer != nil {
+		s.soundPlayer.StopAll()
+	}
 	if kb, ok := s.keyboard.(*input.Keyboard); ok {
 		kb.Stop()
 	}
@@ -234,9 +446,7 @@ func (s *Scene) Resume() {
 	elapsedTime := times.Since(s.pauseTime)
 	s.startTime = s.startTime.Add(elapsedTime)
 	s.musicPlayer.Resume()
-	if kb, ok := s.keyboard.(*input.Keyboard); ok {
-		kb.Listen(s.startTime)
-	}
+	s.listen()
 	s.paused = false
 }
 
@@ -246,24 +456,118 @@ func (s *Scene) Close() {
 	if kb, ok := s.keyboard.(*input.Keyboard); ok {
 		kb.Stop()
 	}
+	if s.voiceCloser != nil {
+		s.voiceCloser.Close()
+	}
 }
 
 func (s Scene) Draw(dst draws.Image) {
 	s.play.Draw(dst)
+	if s.warning != "" && s.now() < warningDuration {
+		t := draws.NewText(s.warning)
+		t.Locate(plays.ScreenSizeX/2, 0.2*plays.ScreenSizeY, draws.CenterMiddle)
+		t.Draw(dst)
+	}
+	switch {
+	case s.result != nil:
+		s.resultScreen.Draw(dst)
+	case s.paused:
+		s.pauseScreen.Draw(dst)
+	}
 }
 
 func (s Scene) DebugString() string {
 	const str = `
 	Press TAB to pause.
-	Press ESC to back to choose a song.`
-	return s.play.DebugString() + str
+	Click Quit at pause to back to choose a song.`
+	return s.play.DebugString() + s.musicSync.DebugString() + str
 }
 
-// func (t *Timer) sync() {
-// 	const threshold = 30 * 1000
-// 	since := int32(times.Since(t.startTime).Milliseconds())
-// 	if e := since - t.Now(); e >= threshold {
-// 		fmt.Printf("%dms: adjusting time error (%dms)\n", since, e)
-// 		t.Tick += ToTick(e)
-// 	}
-// }
+// Keyboard keeps its own start time, since it cannot seek once it starts.
+// Hence, states are shifted when the scene's start time has changed
+// by offset or by sync.
+func (s *Scene) listen() {
+	kb, ok := s.keyboard.(*input.Keyboard)
+	if !ok {
+		return
+	}
+	s.listenTime = s.startTime
+	kb.Listen(s.listenTime)
+}
+
+// Replay states are already in chart time.
+func (s *Scene) readKeyboard(now time.Duration) []input.KeyboardState {
+	if _, ok := s.keyboard.(*input.Keyboard); !ok {
+		return s.keyboard.Read(now)
+	}
+	shift := s.listenTime.Sub(s.startTime)
+	kss := s.keyboard.Read(now - shift)
+	for i := range kss {
+		kss[i].Time += shift
+	}
+	return kss
+}
+
+// sync compares the scene clock with the music clock,
+// then moves start time slightly to follow the music.
+// Delayed start time is same as early music.
+func (s *Scene) sync(now time.Duration) {
+	if s.musicPlayer.IsEmpty() {
+		return
+	}
+	musicTime := s.musicPlayer.Current()
+	// Music clock stops at the end of music.
+	if musicTime >= s.musicPlayer.Duration() {
+		return
+	}
+	musicOffset := time.Duration(s.musicOffset) * time.Millisecond
+	e := now - musicOffset - musicTime
+	if d := s.musicSync.update(e); d != 0 {
+		s.startTime = s.startTime.Add(d)
+	}
+}
+
+// musicSync estimates drift of the scene clock from the music clock.
+// Music position proceeds by the speaker's buffer, so that raw error
+// is a saw wave. Error in the first while is regarded as a baseline,
+// which is already dealt with by music offset. Only the drift from the
+// baseline is corrected, and only a little per update, so that notes
+// would not look like they suddenly teleport.
+type musicSync struct {
+	count     int
+	baseline  float64 // in millisecond
+	drift     float64 // smoothed, in millisecond
+	corrected float64 // total correction, in millisecond
+}
+
+const (
+	syncWarmupCount = 60   // About a second.
+	syncSmoothing   = 0.02 // Weight of the latest error.
+	syncThreshold   = 5    // in millisecond
+	syncMaxStep     = 1    // in millisecond per update
+)
+
+// update returns the amount of time to delay start time.
+func (ms *musicSync) update(e time.Duration) time.Duration {
+	v := float64(e) / float64(time.Millisecond)
+	if ms.count < syncWarmupCount {
+		ms.count++
+		ms.baseline += (v - ms.baseline) / float64(ms.count)
+		return 0
+	}
+	ms.drift += syncSmoothing * (v - ms.baseline - ms.drift)
+	if math.Abs(ms.drift) < syncThreshold {
+		return 0
+	}
+	step := math.Max(-syncMaxStep, math.Min(syncMaxStep, ms.drift))
+	ms.drift -= step
+	ms.corrected += step
+	return time.Duration(step * float64(time.Millisecond))
+}
+
+func (ms musicSync) DebugString() string {
+	if ms.count < syncWarmupCount {
+		return "Music desync: measuring\n"
+	}
+	return fmt.Sprintf("Music desync: %+.1fms (corrected: %+.1fms)\n", ms.drift, ms.corrected)
+}
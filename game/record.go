@@ -0,0 +1,147 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hndada/gosu/plays"
+)
+
+const recordsFilename = "records.json"
+
+// Record is a result of a play, stored persistently.
+type Record struct {
+	PlayResult
+	PlayedAt time.Time
+}
+
+// Performance is a value of a record which contributes to the rating.
+// A player gets nothing at a chart when the accuracy is too low.
+func (r Record) Performance() float64 {
+	return r.Level * accuracyFactor(r.Accuracy)
+}
+
+var accuracyFactor = plays.LinearInterpolate(
+	[]float64{0, 0.80, 0.90, 0.95, 1.00},
+	[]float64{0, 0.00, 0.50, 0.80, 1.00},
+)
+
+// Performances are summed with weights decaying by ratingDecayFactor,
+// best first. Multiplying (1 - ratingDecayFactor) makes the rating
+// close to the level of best charts after playing enough charts,
+// while a new play never lowers the rating.
+const ratingDecayFactor = 0.95
+
+// Rating is separated by key mode: Mode and SubMode.
+type ratingKey struct{ mode, subMode int }
+
+type Records struct {
+	Records []Record
+	ratings map[ratingKey]float64
+}
+
+func NewRecords(fsys fs.FS) *Records {
+	rs := &Records{}
+	if data, err := fs.ReadFile(fsys, recordsFilename); err == nil {
+		if err := json.Unmarshal(data, rs); err != nil {
+			fmt.Printf("Failed to unmarshal %s: %v\n", recordsFilename, err)
+		}
+	}
+	rs.setRatings()
+	return rs
+}
+
+// Add updates ratings and saves records to file.
+func (rs *Records) Add(r PlayResult) error {
+	rs.Records = append(rs.Records, Record{
+		PlayResult: r,
+		PlayedAt:   time.Now(),
+	})
+	rs.setRatings()
+	return rs.save()
+}
+
+func (rs Records) save() error {
+	data, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal records: %w", err)
+	}
+	return os.WriteFile(recordsFilename, data, 0644)
+}
+
+// Only best performance of each chart is counted.
+func (rs *Records) setRatings() {
+	bests := make(map[ratingKey]map[string]float64)
+	for _, r := range rs.Records {
+		k := ratingKey{r.Mode, r.SubMode}
+		if bests[k] == nil {
+			bests[k] = make(map[string]float64)
+		}
+		if p := r.Performance(); bests[k][r.ChartHash] < p {
+			bests[k][r.ChartHash] = p
+		}
+	}
+
+	rs.ratings = make(map[ratingKey]float64)
+	for k, chartBests := range bests {
+		ps := make([]float64, 0, len(chartBests))
+		for _, p := range chartBests {
+			ps = append(ps, p)
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(ps)))
+		sum := plays.WeightedSum(ps, ratingDecayFactor)
+		rs.ratings[k] = sum * (1 - ratingDecayFactor)
+	}
+}
+
+func (rs Records) Rating(mode, subMode int) float64 {
+	return rs.ratings[ratingKey{mode, subMode}]
+}
+
+func (rs Records) isPlayed(chartHash string) bool {
+	for _, r := range rs.Records {
+		if r.ChartHash == chartHash {
+			return true
+		}
+	}
+	return false
+}
+
+// Charts whose level is within the range from the rating are recommended.
+const recommendLevelRange = 1.0
+
+// Recommend returns charts near the player's rating, closest first.
+// Unplayed charts come before played ones.
+func (db Database) Recommend(rs *Records, mode, subMode int) []ChartRow {
+	rating := rs.Rating(mode, subMode)
+	var cs []ChartRow
+	for _, c := range db.Chart {
+		if c.Mode != mode || c.SubMode != subMode {
+			continue
+		}
+		if d := c.Level - rating; d < -recommendLevelRange || d > recommendLevelRange {
+			continue
+		}
+		cs = append(cs, c)
+	}
+
+	distance := func(c ChartRow) float64 {
+		if d := c.Level - rating; d > 0 {
+			return d
+		} else {
+			return -d
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		pi, pj := rs.isPlayed(cs[i].ChartHash), rs.isPlayed(cs[j].ChartHash)
+		if pi != pj {
+			return !pi
+		}
+		return distance(cs[i]) < distance(cs[j])
+	})
+	return cs
+}
@@ -1,10 +1,14 @@
 package game
 
 import (
+	"fmt"
 	"io/fs"
 
+	"github.com/hndada/gosu/audios"
 	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays/drum"
 	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
 )
 
 // Todo: deal with two kinds of values: file path and directory path.
@@ -27,6 +31,8 @@ type Resources struct {
 	CursorTrailImage       draws.Image
 
 	Piano *piano.Resources
+	Drum  *drum.Resources
+	Sing  *sing.Resources
 }
 
 func NewResources(fsys fs.FS) (res *Resources) {
@@ -52,5 +58,24 @@ func NewResources(fsys fs.FS) (res *Resources) {
 		res.CursorTrailImage = draws.NewImageFromFile(fsys, fname)
 	}
 	res.Piano = piano.NewResources(fsys)
+	res.Drum = drum.NewResources(fsys)
+	res.Sing = sing.NewResources(fsys)
 	return
 }
+
+// Sounds in a directory make a group, which plays one of them at a time.
+// How to pick one is set in the group's options file of the skin.
+func newInterfaceSoundPlayer(fsys fs.FS, scale *float64) *audios.SoundPlayer {
+	sp := audios.NewSoundPlayer(scale)
+	for _, name := range []string{SoundToggleOff, SoundToggleOn, SoundTransitionDown, SoundTransitionUp} {
+		if err := sp.AddFile(fsys, name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+	for _, name := range []string{SoundTaps, SoundSwipes} {
+		if err := sp.AddDir(fsys, name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+	return &sp
+}
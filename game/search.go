@@ -25,8 +25,10 @@ const (
 )
 
 // TODO: add filter
+// Charts should have all Tags to be searched.
 type SearchQuery struct {
 	Query      string
+	Tags       []string
 	GroupBy    int
 	GroupOrder int
 	SortBy     int
@@ -56,6 +58,15 @@ func GroupBy[T comparable](raws []ChartRow, key func(ChartRow) T) [][]ChartRow {
 	return ccs
 }
 
+func (q SearchQuery) isTagsMatch(c ChartRow) bool {
+	for _, tag := range q.Tags {
+		if !c.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
 func (db Database) Search(q SearchQuery) (r SearchResult) {
 	raws := make([]ChartRow, 0, 100)
 	isQueryExist := q.Query != ""
@@ -63,6 +74,9 @@ func (db Database) Search(q SearchQuery) (r SearchResult) {
 		if isQueryExist && !c.IsMatch(q.Query) {
 			continue
 		}
+		if !q.isTagsMatch(c) {
+			continue
+		}
 		raws = append(raws, c)
 	}
 
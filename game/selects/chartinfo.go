@@ -0,0 +1,113 @@
+package selects
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+)
+
+const (
+	chartInfoPositionX = 30
+	chartInfoPositionY = 80
+	graphWidth         = 400
+	graphHeight        = 60
+	graphColumnCount   = 80
+	graphGap           = 10 // between difficulty and density graph.
+)
+
+// ChartInfoComponent shows where the hard parts of the chart are.
+type ChartInfoComponent struct {
+	texts             []draws.Text
+	barSprite         draws.Sprite
+	graphY            float64   // Graphs are drawn below the texts.
+	difficultyColumns []float64 // Normalized to [0, 1].
+	densityColumns    []float64 // Normalized to [0, 1].
+}
+
+func newChartInfoComponent(c *game.ChartRow) (cmp ChartInfoComponent) {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	cmp.barSprite = draws.NewSprite(img)
+
+	lines := []string{
+		fmt.Sprintf("Peak NPS: %.1f", c.PeakNPS),
+		fmt.Sprintf("Drain time: %s", c.DrainTimeString()),
+		fmt.Sprintf("Long note: %.0f%%", c.LongNoteRatio*100),
+	}
+	y := float64(chartInfoPositionY)
+	for _, line := range lines {
+		t := draws.NewText(line)
+		t.Locate(chartInfoPositionX, y, draws.LeftTop)
+		cmp.texts = append(cmp.texts, t)
+		y += t.H()
+	}
+	cmp.graphY = y + graphGap
+
+	cmp.difficultyColumns = mergeColumns(c.Difficulties, graphColumnCount)
+	cmp.densityColumns = mergeColumns(c.Densities, graphColumnCount)
+	return
+}
+
+// mergeColumns merges sections into at most n columns
+// by taking the maximum, so that a short peak is not flattened.
+func mergeColumns(sections []float64, n int) []float64 {
+	if len(sections) == 0 {
+		return nil
+	}
+	if len(sections) < n {
+		n = len(sections)
+	}
+
+	cols := make([]float64, n)
+	for i, v := range sections {
+		ci := i * n / len(sections)
+		if cols[ci] < v {
+			cols[ci] = v
+		}
+	}
+
+	var peak float64
+	for _, v := range cols {
+		if peak < v {
+			peak = v
+		}
+	}
+	if peak == 0 {
+		return cols
+	}
+	for i := range cols {
+		cols[i] /= peak
+	}
+	return cols
+}
+
+func (cmp ChartInfoComponent) Draw(dst draws.Image) {
+	var (
+		pink    = color.NRGBA{R: 255, G: 128, B: 255, A: 192}
+		skyblue = color.NRGBA{R: 64, G: 255, B: 255, A: 192}
+	)
+	for _, t := range cmp.texts {
+		t.Draw(dst)
+	}
+
+	bottom := cmp.graphY + graphHeight
+	cmp.drawGraph(dst, cmp.difficultyColumns, bottom, pink)
+	bottom += graphGap + graphHeight
+	cmp.drawGraph(dst, cmp.densityColumns, bottom, skyblue)
+}
+
+func (cmp ChartInfoComponent) drawGraph(dst draws.Image, cols []float64, bottom float64, clr color.NRGBA) {
+	if len(cols) == 0 {
+		return
+	}
+	w := float64(graphWidth) / float64(len(cols))
+	for i, v := range cols {
+		s := cmp.barSprite
+		s.SetSize(w, v*graphHeight)
+		s.Locate(chartInfoPositionX+w*float64(i), bottom, draws.LeftBottom)
+		s.ColorScale.ScaleWithColor(clr)
+		s.Draw(dst)
+	}
+}
@@ -2,6 +2,7 @@ package selects
 
 import (
 	"image/color"
+	"math"
 	"time"
 
 	"github.com/hndada/gosu/draws"
@@ -32,9 +33,15 @@ type ChartListComponent struct {
 	depth        int
 	indexHandler ui.KeyNumberHandler[int]
 	depthHandler ui.KeyNumberHandler[int]
+
+	mouse *ui.MouseListener
+	wheel float64 // Wheel movement left to move the focus.
 }
 
-func newChartListComponent(boxSprite draws.Sprite, kbs *ui.KeyboardState, r game.SearchResult) (cmp ChartListComponent) {
+// Returning pointer is required, since handlers
+// hold pointers to the component's fields.
+func newChartListComponent(boxSprite draws.Sprite, kbs *ui.KeyboardState, r game.SearchResult) *ChartListComponent {
+	cmp := &ChartListComponent{}
 	cmp.sprite = boxSprite
 	// cmp.h = boxSprite.H()
 
@@ -44,6 +51,12 @@ func newChartListComponent(boxSprite draws.Sprite, kbs *ui.KeyboardState, r game
 	cmp.js = make([]int, len(r.Charts))
 	cmp.indexHandler = cmp.newIndexHandler(&cmp.i, len(r.Charts), kbs)
 	cmp.depthHandler = cmp.newDepthHandler(&cmp.depth, kbs)
+	cmp.updateIndexHandler()
+
+	// Mouse is listened over the whole column of the list.
+	box := boxSprite.Box
+	box.SetSize(chartListBoxWidth, game.ScreenSizeY)
+	cmp.mouse = ui.NewMouseListener(&box)
 	return cmp
 }
 
@@ -99,22 +112,76 @@ func (ChartListComponent) newDepthHandler(depth *int, kbs *ui.KeyboardState) ui.
 func (cmp ChartListComponent) j() int                { return cmp.js[cmp.i] }
 func (cmp ChartListComponent) chart() *game.ChartRow { return &cmp.charts[cmp.i][cmp.j()] }
 
+// index returns the focused index at current depth.
+func (cmp ChartListComponent) index() int { return []int{cmp.i, cmp.j()}[cmp.depth] }
+
+func (cmp ChartListComponent) listLen() int {
+	if cmp.depth == depthFolder {
+		return len(cmp.charts)
+	}
+	return len(cmp.charts[cmp.i])
+}
+
 func (cmp *ChartListComponent) update() (c *game.ChartRow, isPlay bool) {
-	if _, ok := cmp.depthHandler.Update(); ok {
-		cmp.updateIndexHandler()
-		cmp.updateTween()
+	cmp.mouse.Update()
+	lastDepth, lastIndex := cmp.depth, cmp.index()
+	cmp.depthHandler.Handle()
+	if cmp.depth == lastDepth {
+		cmp.indexHandler.Handle()
+		cmp.updateMouse()
 	}
+
 	if cmp.depth == depthPlay {
 		cmp.depth = depthChart
 		return cmp.chart(), true
 	}
-	if _, ok := cmp.indexHandler.Update(); ok {
+	if cmp.depth != lastDepth {
+		cmp.updateIndexHandler()
+		cmp.updateTween()
+	} else if cmp.index() != lastIndex {
 		cmp.updateTween()
 	}
-
 	return cmp.chart(), false
 }
 
+// Left click focuses an item, and clicking the focused item again
+// works as Enter. Right click works as Escape. Wheel moves the focus.
+func (cmp *ChartListComponent) updateMouse() {
+	switch {
+	case cmp.mouse.IsClicked(input.MouseButtonLeft):
+		_, y := input.MouseCursorPosition()
+		i, ok := cmp.itemAt(y)
+		if !ok {
+			break
+		}
+		if i == cmp.index() {
+			cmp.depthHandler.Increase()
+			return
+		}
+		*cmp.indexHandler.Value = i
+	case cmp.mouse.IsClicked(input.MouseButtonRight):
+		cmp.depthHandler.Decrease()
+		return
+	}
+
+	// Touchpads scroll by fractions of a notch.
+	cmp.wheel += cmp.mouse.MouseWheelMovement().Y
+	for ; cmp.wheel >= 1; cmp.wheel-- {
+		cmp.indexHandler.Decrease()
+	}
+	for ; cmp.wheel <= -1; cmp.wheel++ {
+		cmp.indexHandler.Increase()
+	}
+}
+
+// itemAt returns the index of the item at the given height.
+// Items are drawn relative to the cursor at the center of the screen.
+func (cmp ChartListComponent) itemAt(y float64) (int, bool) {
+	pos := y - float64(game.ScreenSizeY/2) + cmp.tween.Value()
+	i := int(math.Floor(pos/chartListBoxHeight + 0.5))
+	return i, i >= 0 && i < cmp.listLen()
+}
+
 func (cmp *ChartListComponent) updateIndexHandler() {
 	var maxLen int
 	var ptr *int
@@ -132,7 +199,7 @@ func (cmp *ChartListComponent) updateIndexHandler() {
 
 func (cmp *ChartListComponent) updateTween() {
 	begin := cmp.tween.Value()
-	target := chartListBoxHeight * float64([]int{cmp.i, cmp.j()}[cmp.depth])
+	target := chartListBoxHeight * float64(cmp.index())
 	change := target - begin
 	cmp.tween = tween.Tween{MaxLoop: 1}
 	cmp.tween.Add(begin, change, 400*time.Millisecond, tween.EaseOutExponential)
@@ -9,53 +9,96 @@ import (
 	"github.com/hndada/gosu/tween"
 )
 
-// musicPlayer loops music with fade in/out.
+// PreviewMusicPlayer loops music between preview points with fade in/out.
 // (old memo: MusicPlayer should be pointer so that it plays only once.)
 type PreviewMusicPlayer struct {
 	*audios.MusicPlayer
 	volume    *float64
+	tween     tween.Tween
+	start     time.Duration // Preview points in the music.
+	end       time.Duration
 	startTime time.Time
+	played    bool
 }
 
-const waitDuration = 150 * time.Millisecond
+const (
+	waitDuration    = 150 * time.Millisecond
+	fadeInDuration  = 1 * time.Second
+	fadeOutDuration = 2 * time.Second
+	previewDuration = 15 * time.Second // From fade in to the end of fade out.
 
-var volumeTween tween.Tween
+	// Preview starts at the ratio of the music when preview time is not set.
+	defaultPreviewRatio = 0.4
 
-func init() {
-	tw := tween.Tween{}
-	tw.Add(0, 0, waitDuration, tween.EaseLinear)   // wait
-	tw.Add(0, 1, 1*time.Second, tween.EaseLinear)  // fade in
-	tw.Add(1, 1, 12*time.Second, tween.EaseLinear) // keep
-	tw.Add(1, 0, 2*time.Second, tween.EaseLinear)  // fade out
-	volumeTween = tw
-}
+	// Preview ends before the music does. Once the music is drained,
+	// the output drops it, and seeking back does not play it again.
+	previewEndMargin = 500 * time.Millisecond
+)
 
-func NewPreviewMusicPlayer(fsys fs.FS, name string, volume *float64) (PreviewMusicPlayer, error) {
+// Gain is for normalizing loudness, in dB.
+// Preview time is in milliseconds, negative when not set.
+func NewPreviewMusicPlayer(fsys fs.FS, name string, volume *float64, gain float64, previewTime int32) (PreviewMusicPlayer, error) {
 	mp, err := audios.NewMusicPlayerFromFile(fsys, name)
 	if err != nil {
 		return PreviewMusicPlayer{}, err
 	}
+	mp.SetGain(gain)
+
+	dur := mp.Duration()
+	start := time.Duration(previewTime) * time.Millisecond
+	if previewTime < 0 || start >= dur {
+		start = time.Duration(float64(dur) * defaultPreviewRatio)
+	}
+	end := max(start, min(start+previewDuration, dur-previewEndMargin))
+	if err := mp.Seek(start); err != nil {
+		mp.Close()
+		return PreviewMusicPlayer{}, err
+	}
+
+	tw := tween.Tween{MaxLoop: 1}
+	tw.Add(0, 1, fadeInDuration, tween.EaseLinear)                                   // fade in
+	tw.Add(1, 0, max(0, end-start-fadeInDuration-fadeOutDuration), tween.EaseLinear) // keep
+	tw.Add(1, -1, fadeOutDuration, tween.EaseLinear)                                 // fade out
 
 	return PreviewMusicPlayer{
 		MusicPlayer: mp,
 		volume:      volume,
+		tween:       tw,
+		start:       start,
+		end:         end,
 		startTime:   times.Now(),
 	}, nil
 }
 
+// Music is played once; it loops by seeking back to the start point.
 func (mp *PreviewMusicPlayer) Update() {
-	volumeTween.Update()
-	vol := *mp.volume * volumeTween.Value()
-	mp.SetVolume(vol)
-
-	t := times.Since(mp.startTime)
-	switch {
-	case t > waitDuration:
+	if mp.MusicPlayer == nil {
+		return
+	}
+	if !mp.played {
+		mp.SetVolume(0)
+		if times.Since(mp.startTime) < waitDuration {
+			return
+		}
+		mp.tween.Start()
 		mp.Play()
-	case t > mp.Duration()+waitDuration:
-		mp.Rewind()
-		mp.startTime = times.Now()
+		mp.played = true
+	}
+
+	if mp.Current() >= mp.end {
+		mp.Seek(mp.start)
+		mp.tween.Start()
+	}
+	mp.tween.Update()
+	mp.SetVolume(*mp.volume * mp.tween.Value())
+}
+
+// Progress returns the play position in ratio of the music.
+func (mp PreviewMusicPlayer) Progress() float64 {
+	if mp.MusicPlayer == nil || mp.Duration() == 0 {
+		return 0
 	}
+	return float64(mp.Current()) / float64(mp.Duration())
 }
 
 func (pmp *PreviewMusicPlayer) Close() {
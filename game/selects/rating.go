@@ -0,0 +1,60 @@
+package selects
+
+import (
+	"fmt"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+)
+
+const (
+	ratingPositionX     = 30
+	ratingPositionY     = game.ScreenSizeY - 200
+	recommendationCount = 3
+)
+
+// RatingComponent shows player's rating and charts
+// recommended for practice at the current key mode.
+type RatingComponent struct {
+	mode        int
+	subMode     int
+	recordCount int
+	texts       []draws.Text
+}
+
+func newRatingComponent(g *game.Game, mode, subMode int) (cmp RatingComponent) {
+	cmp.mode = mode
+	cmp.subMode = subMode
+	cmp.recordCount = len(g.Records.Records)
+
+	lines := []string{fmt.Sprintf("Rating: %.2f", g.Records.Rating(mode, subMode))}
+	cs := g.Database.Recommend(g.Records, mode, subMode)
+	if len(cs) > recommendationCount {
+		cs = cs[:recommendationCount]
+	}
+	for _, c := range cs {
+		line := fmt.Sprintf("Recommended: %s [%s] (%s)", c.MusicName, c.ChartName, c.LevelString())
+		lines = append(lines, line)
+	}
+
+	y := float64(ratingPositionY)
+	for _, line := range lines {
+		t := draws.NewText(line)
+		t.Locate(ratingPositionX, y, draws.LeftTop)
+		cmp.texts = append(cmp.texts, t)
+		y += t.H()
+	}
+	return
+}
+
+// isStale returns true when key mode has changed or a new record is added.
+func (cmp RatingComponent) isStale(g *game.Game, mode, subMode int) bool {
+	return cmp.texts == nil || cmp.mode != mode || cmp.subMode != subMode ||
+		cmp.recordCount != len(g.Records.Records)
+}
+
+func (cmp RatingComponent) Draw(dst draws.Image) {
+	for _, t := range cmp.texts {
+		t.Draw(dst)
+	}
+}
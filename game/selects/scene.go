@@ -3,8 +3,11 @@ package selects
 import (
 	"github.com/hndada/gosu/draws"
 	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/input"
 	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/drum"
 	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
 )
 
 // TODO: list key handler: double click left/right to open advanced options
@@ -13,15 +16,14 @@ type Scene struct {
 	*game.Game
 	boxSprite draws.Sprite
 
-	// searchBox          SearchBoxComponent
-	chartList          ChartListComponent
+	searchBox          SearchBoxComponent
+	chartList          *ChartListComponent
 	lastChart          *game.ChartRow
-	
-	// volume             *float64
 	background         game.BackgroundComponent
 	previewMusicPlayer PreviewMusicPlayer
-
-	// chartInfo  ChartInfoComponent
+	chartInfo          ChartInfoComponent
+	waveform           WaveformComponent
+	rating             RatingComponent
 
 	// Score box color: Gray128 with 50% transparent
 	// Hovered Score box color: Gray96 with 50% transparent
@@ -62,42 +64,89 @@ func (s *Scene) Update() any {
 	s.Handlers.SubMode.Handle()
 	s.Handlers.SpeedScales[s.mode()].Handle()
 
+	if input.IsKeyJustPressed(input.KeyF10) {
+		s.previewMusicPlayer.Close()
+		s.lastChart = nil // Preview music will be replayed after calibration.
+		return game.CalibrateArgs{}
+	}
+	if input.IsKeyJustPressed(input.KeyF5) {
+		s.previewMusicPlayer.Close()
+		s.lastChart = nil
+		return game.KeyBindingArgs{}
+	}
+
 	c, isPlay := s.chartList.update()
 	if c != nil && isPlay {
 		return s.playChart(c)
 	}
 
 	lc := s.lastChart
-	if lc == nil || lc.MusicName != c.MusicName {
+	if lc == nil || lc.MusicPath() != c.MusicPath() {
 		s.previewMusicPlayer.Close()
-		pmp, err := NewPreviewMusicPlayer(c.FS, c.MusicName, s.volume)
+		s.previewMusicPlayer = PreviewMusicPlayer{}
+		pmp, err := NewPreviewMusicPlayer(c.FS, c.MusicPath(), &s.Options.MusicVolume, c.MusicGain, c.PreviewTime)
 		if err == nil { // music file may not exist
 			s.previewMusicPlayer = pmp
 		}
+		s.waveform = newWaveformComponent(s.Database.Musics[c.MusicHash].Peaks)
 	}
 	if lc == nil || lc.BackgroundFilename != c.BackgroundFilename {
 		s.background = game.NewBackgroundComponent(s.Resources, s.Options)
 	}
+	if lc == nil || lc.ChartHash != c.ChartHash {
+		s.chartInfo = newChartInfoComponent(c)
+	}
+	s.previewMusicPlayer.Update()
+	s.waveform.Update(s.previewMusicPlayer.Progress())
+	if s.rating.isStale(s.Game, s.mode(), s.subMode()) {
+		s.rating = newRatingComponent(s.Game, s.mode(), s.subMode())
+	}
 	s.lastChart = c
 	return nil
 }
 
 func (s Scene) mode() int { return *s.Handlers.Mode.Value }
 
+// Drum has only one key layout, and sing has only one singer.
+func (s Scene) subMode() int {
+	switch s.mode() {
+	case plays.ModeDrum:
+		return len(s.Options.Drum.KeyMappings)
+	case plays.ModeSing:
+		return 1
+	}
+	return s.Options.SubMode
+}
+
 func (s *Scene) playChart(row *game.ChartRow) any {
+	// Mods are chosen by the chart's mode, not by the current mode.
+	var mods plays.Mods
+	switch row.Mode {
+	case plays.ModePiano:
+		mods = piano.Mods{KeyCount: s.Options.ConvertKeyCount}
+	case plays.ModeDrum:
+		mods = drum.Mods{}
+	case plays.ModeSing:
+		mods = sing.Mods{}
+	default:
+		return nil
+	}
 	// It is fine to call Close at blank MusicPlayer.
 	s.previewMusicPlayer.Close()
-	mods := []plays.Mods{piano.Mods{}}[s.mode()]
 	return game.PlayArgs{
 		ChartFS:       row.FS,
 		ChartFilename: row.Name,
 		Mods:          mods,
+		MusicGain:     row.MusicGain,
 	}
 }
 
 func (s Scene) Draw(dst draws.Image) {
 	s.background.Draw(dst)
 	s.chartList.Draw(dst)
+	s.chartInfo.Draw(dst)
+	s.waveform.Draw(dst)
+	s.rating.Draw(dst)
 	s.searchBox.Draw(dst)
 }
 
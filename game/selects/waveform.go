@@ -0,0 +1,60 @@
+package selects
+
+import (
+	"image/color"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/game"
+)
+
+const (
+	waveformPositionY   = game.ScreenSizeY - 100 // Vertical center of the waveform.
+	waveformHeight      = 80
+	waveformColumnCount = 200
+)
+
+// WaveformComponent shows the music of the chart with the play position marked.
+type WaveformComponent struct {
+	barSprite draws.Sprite
+	columns   []float64 // Normalized to [0, 1].
+	progress  float64
+}
+
+// Peaks are cached in a file by music hash, since decoding takes a while.
+func newWaveformComponent(peaks []float64) (cmp WaveformComponent) {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	cmp.barSprite = draws.NewSprite(img)
+	cmp.columns = mergeColumns(peaks, waveformColumnCount)
+	return
+}
+
+// Progress is the play position in ratio of the music.
+func (cmp *WaveformComponent) Update(progress float64) { cmp.progress = progress }
+
+func (cmp WaveformComponent) Draw(dst draws.Image) {
+	if len(cmp.columns) == 0 {
+		return
+	}
+	var (
+		played   = color.NRGBA{R: 255, G: 255, B: 255, A: 192}
+		unplayed = color.NRGBA{R: 128, G: 128, B: 128, A: 128}
+	)
+	w := float64(graphWidth) / float64(len(cmp.columns))
+	for i, v := range cmp.columns {
+		clr := unplayed
+		if float64(i)/float64(len(cmp.columns)) < cmp.progress {
+			clr = played
+		}
+		s := cmp.barSprite
+		s.SetSize(w, max(1, v*waveformHeight)) // Silence is drawn as a line.
+		s.Locate(chartInfoPositionX+w*float64(i), waveformPositionY, draws.LeftMiddle)
+		s.ColorScale.ScaleWithColor(clr)
+		s.Draw(dst)
+	}
+
+	marker := cmp.barSprite
+	marker.SetSize(2, waveformHeight)
+	marker.Locate(chartInfoPositionX+graphWidth*cmp.progress, waveformPositionY, draws.CenterMiddle)
+	marker.Draw(dst)
+}
@@ -0,0 +1,145 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// StandardGamepadGUID is a mapping key for controllers which have
+// no mapping of their own but support the standard layout.
+const StandardGamepadGUID = "standard"
+
+// Gamepad inputs are named as follows:
+// "Button3" for a raw button, "Axis1+" and "Axis1-" for each direction
+// of a raw axis, and "DPadUp", "A", "LB", ... for the standard layout.
+// Raw inputs are for dance pads and arcade controllers,
+// which usually do not support the standard layout.
+var standardGamepadButtons = map[string]ebiten.StandardGamepadButton{
+	"DPadUp":    ebiten.StandardGamepadButtonLeftTop,
+	"DPadDown":  ebiten.StandardGamepadButtonLeftBottom,
+	"DPadLeft":  ebiten.StandardGamepadButtonLeftLeft,
+	"DPadRight": ebiten.StandardGamepadButtonLeftRight,
+	"A":         ebiten.StandardGamepadButtonRightBottom,
+	"B":         ebiten.StandardGamepadButtonRightRight,
+	"X":         ebiten.StandardGamepadButtonRightLeft,
+	"Y":         ebiten.StandardGamepadButtonRightTop,
+	"LB":        ebiten.StandardGamepadButtonFrontTopLeft,
+	"RB":        ebiten.StandardGamepadButtonFrontTopRight,
+	"LT":        ebiten.StandardGamepadButtonFrontBottomLeft,
+	"RT":        ebiten.StandardGamepadButtonFrontBottomRight,
+	"LS":        ebiten.StandardGamepadButtonLeftStick,
+	"RS":        ebiten.StandardGamepadButtonRightStick,
+}
+
+// An axis is regarded as pressed when tilted beyond the threshold.
+const gamepadAxisThreshold = 0.5
+
+type gamepadInputKind int
+
+const (
+	gamepadInputNone gamepadInputKind = iota
+	gamepadInputButton
+	gamepadInputAxis
+	gamepadInputStandard
+)
+
+type gamepadInput struct {
+	kind  gamepadInputKind
+	index int     // Button or axis index.
+	sign  float64 // Direction of an axis.
+}
+
+// nameToGamepadInput returns none when the name is unknown,
+// so that an unbound lane is just never pressed.
+func nameToGamepadInput(name string) gamepadInput {
+	if b, ok := standardGamepadButtons[name]; ok {
+		return gamepadInput{kind: gamepadInputStandard, index: int(b)}
+	}
+	if s, ok := strings.CutPrefix(name, "Button"); ok {
+		if i, err := strconv.Atoi(s); err == nil && i >= 0 {
+			return gamepadInput{kind: gamepadInputButton, index: i}
+		}
+	}
+	if s, ok := strings.CutPrefix(name, "Axis"); ok && len(s) >= 2 {
+		sign := 1.0
+		switch s[len(s)-1] {
+		case '+':
+		case '-':
+			sign = -1
+		default:
+			return gamepadInput{}
+		}
+		if i, err := strconv.Atoi(s[:len(s)-1]); err == nil && i >= 0 {
+			return gamepadInput{kind: gamepadInputAxis, index: i, sign: sign}
+		}
+	}
+	return gamepadInput{}
+}
+
+func (gi gamepadInput) isPressed(id ebiten.GamepadID) bool {
+	switch gi.kind {
+	case gamepadInputButton:
+		return ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton(gi.index))
+	case gamepadInputAxis:
+		v := ebiten.GamepadAxisValue(id, ebiten.GamepadAxisType(gi.index))
+		return v*gi.sign > gamepadAxisThreshold
+	case gamepadInputStandard:
+		return ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButton(gi.index))
+	}
+	return false
+}
+
+// newFetchGamepadState returns closure, as newFetchKeyboardState does.
+// Mappings are input names of lanes by controller GUID.
+// Controllers are looked up at every poll, hence they can be
+// plugged in during a play.
+func newFetchGamepadState(n int, mappings map[string][]string) func() []bool {
+	inputsMap := make(map[string][]gamepadInput, len(mappings))
+	for guid, names := range mappings {
+		inputs := make([]gamepadInput, min(n, len(names)))
+		for k := range inputs {
+			inputs[k] = nameToGamepadInput(names[k])
+		}
+		inputsMap[guid] = inputs
+	}
+
+	var ids []ebiten.GamepadID
+	return func() []bool {
+		ps := make([]bool, n)
+		ids = ebiten.AppendGamepadIDs(ids[:0])
+		for _, id := range ids {
+			inputs, ok := inputsMap[ebiten.GamepadSDLID(id)]
+			if !ok && ebiten.IsStandardGamepadLayoutAvailable(id) {
+				inputs = inputsMap[StandardGamepadGUID]
+			}
+			for k, gi := range inputs {
+				ps[k] = ps[k] || gi.isPressed(id)
+			}
+		}
+		return ps
+	}
+}
+
+// NewKeyboardWithGamepads polls gamepads along with keys.
+// A lane is pressed when either its key or its gamepad input is pressed,
+// hence gamepads go through the same keyboard states as keys.
+func NewKeyboardWithGamepads(keys []Key, mappings map[string][]string) *Keyboard {
+	kb := NewKeyboard(keys)
+	if len(mappings) == 0 {
+		return kb
+	}
+	kb.addFetch(newFetchGamepadState(len(keys), mappings))
+	return kb
+}
+
+// GamepadGUIDs returns GUIDs of connected controllers,
+// for players to find the key of their mappings.
+func GamepadGUIDs() []string {
+	var guids []string
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		guids = append(guids, ebiten.GamepadSDLID(id))
+	}
+	return guids
+}
@@ -1,6 +1,7 @@
 package input
 
 import (
+	"sync"
 	"time"
 
 	"github.com/hndada/gosu/times"
@@ -84,69 +85,123 @@ type KeyboardReader interface {
 
 // Keyboard should not require additional adjustment when offset has changed,
 // Because Keyboard cannot seek at precise position once it starts. Same goes for music.
+//
+// Polling goroutine only pushes states to the ring, and the game loop
+// moves them to the buffer at Read. Hence the buffer is touched by the
+// game loop only, and neither side locks at polling.
 type Keyboard struct {
 	*KeyboardStateBuffer
-	// mu                 *sync.Mutex // for lock
 	fetchKeyboardState func() []bool
-	startTime          time.Time
 	period             time.Duration
-	stop               chan struct{}
+	ring               *stateRing
+
+	mu   sync.Mutex // for Listen and Stop
+	stop chan struct{}
+	done chan struct{} // Closed when polling goroutine has returned.
 }
 
 func NewKeyboard(keys []Key) *Keyboard {
+	return newKeyboard(len(keys), newFetchKeyboardState(keys))
+}
+
+func newKeyboard(keyCount int, fetch func() []bool) *Keyboard {
 	kb := &Keyboard{
 		KeyboardStateBuffer: &KeyboardStateBuffer{},
-		fetchKeyboardState:  newFetchKeyboardState(keys),
-		// mu:                 &sync.Mutex{},
-		stop: make(chan struct{}),
+		fetchKeyboardState:  fetch,
+		ring:                &stateRing{},
 	}
-	first := KeyboardState{-10 * time.Second, make([]bool, len(keys))}
+	first := KeyboardState{-10 * time.Second, make([]bool, keyCount)}
 	kb.buf = append(kb.buf, first)
 	kb.SetPollingRate(defaultPollingRate)
 	return kb
 }
 
+// addFetch merges another source of lanes into the keyboard state.
+// A lane is pressed when either source presses it.
+// It should be called before Listen.
+func (kb *Keyboard) addFetch(fetch func() []bool) {
+	fetchKeys := kb.fetchKeyboardState
+	kb.fetchKeyboardState = func() []bool {
+		ps := fetchKeys()
+		for k, p := range fetch() {
+			if k < len(ps) {
+				ps[k] = ps[k] || p
+			}
+		}
+		return ps
+	}
+}
+
+// SetPollingRate should be called before Listen.
 func (kb *Keyboard) SetPollingRate(rate float64) {
 	second := float64(time.Second) * times.PlaybackRate()
 	kb.period = time.Duration(second / rate)
 }
 
-// Listen starts polling keyboard state.
+// Listen starts polling keyboard state. It is fine to call Listen
+// again after Stop, such as resuming from pause.
 func (kb *Keyboard) Listen(startTime time.Time) {
-	kb.startTime = startTime
-	go func() {
-		for {
-			select {
-			case <-kb.stop:
-				return
-			default:
-				start := times.Now()
-				kb.poll()
-				elapsed := times.Since(start)
-				// It is fine to pass negative value to time.Sleep.
-				// It is fine not to update period by changing playback rate;
-				// It would just cause more or less of polling.
-				time.Sleep(kb.period - elapsed)
-			}
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	if kb.stop != nil {
+		return // Already listening.
+	}
+	kb.stop = make(chan struct{})
+	kb.done = make(chan struct{})
+	go kb.run(startTime, kb.period, kb.stop, kb.done)
+}
+
+// Arguments are passed by value, so that
+// the goroutine shares nothing but the ring with others.
+func (kb *Keyboard) run(startTime time.Time, period time.Duration, stop, done chan struct{}) {
+	defer close(done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+			start := times.Now()
+			kb.poll(startTime)
+			elapsed := times.Since(start)
+			// It is fine to pass negative value to Reset.
+			// It is fine not to update period by changing playback rate;
+			// It would just cause more or less of polling.
+			timer.Reset(period - elapsed)
 		}
-	}()
+	}
 }
 
-// When to use lock: read or write to shared resources.
-// A practical example would be public toilet.
-func (kb *Keyboard) poll() {
-	t := times.Since(kb.startTime)
+func (kb *Keyboard) poll(startTime time.Time) {
+	t := times.Since(startTime)
 	ps := kb.fetchKeyboardState()
-	state := KeyboardState{t, ps}
-
-	// kb.mu.Lock()
-	// defer kb.mu.Unlock()
-	kb.buf = append(kb.buf, state)
+	kb.ring.push(KeyboardState{t, ps})
 }
 
+// Stop returns after polling goroutine has returned, hence no state
+// is pushed after Stop. It is fine to call Stop when not listening.
 func (kb *Keyboard) Stop() {
-	kb.stop <- struct{}{}
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	if kb.stop == nil {
+		return
+	}
+	close(kb.stop)
+	<-kb.done
+	kb.stop, kb.done = nil, nil
+}
+
+// Read and Output should be called by a single goroutine, the game loop.
+func (kb *Keyboard) Read(now time.Duration) []KeyboardState {
+	kb.buf = kb.ring.pop(kb.buf)
+	return kb.KeyboardStateBuffer.Read(now)
+}
+
+func (kb *Keyboard) Output() []KeyboardState {
+	kb.buf = kb.ring.pop(kb.buf)
+	return kb.KeyboardStateBuffer.Output()
 }
 
-// No worry of accessing with nil pointer.
-// https://go.dev/play/p/B4Z1LwQC_jP
+// Dropped returns the number of states dropped by a full ring.
+func (kb *Keyboard) Dropped() int { return int(kb.ring.dropped.Load()) }
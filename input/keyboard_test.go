@@ -0,0 +1,87 @@
+package input
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hndada/gosu/times"
+)
+
+// Run with -race.
+func TestStateRing(t *testing.T) {
+	const n = 200000
+	var r stateRing
+	go func() {
+		for i := 0; i < n; {
+			ps := []bool{i%2 == 0}
+			if r.push(KeyboardState{time.Duration(i), ps}) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	var kss []KeyboardState
+	for len(kss) < n {
+		kss = r.pop(kss)
+		runtime.Gosched()
+	}
+	for i, ks := range kss {
+		if ks.Time != time.Duration(i) || ks.KeysPressed[0] != (i%2 == 0) {
+			t.Fatalf("state %d: got %v", i, ks)
+		}
+	}
+}
+
+// Listen and Stop are repeated as in pausing and resuming,
+// while the game loop keeps reading. Run with -race.
+func TestKeyboardListenStop(t *testing.T) {
+	var count int // Polling goroutines never run at the same time.
+	fetch := func() []bool {
+		count++
+		return []bool{count%2 == 0, count%3 == 0}
+	}
+	kb := newKeyboard(2, fetch)
+	kb.SetPollingRate(20000)
+
+	startTime := times.Now()
+	kb.Listen(startTime)
+	var (
+		last  = -time.Hour
+		read  int
+		cycle int
+	)
+	for deadline := time.Now().Add(300 * time.Millisecond); time.Now().Before(deadline); cycle++ {
+		if cycle%20 == 0 {
+			kb.Stop()
+			kb.Stop() // Stop is fine to be called twice.
+			kb.Listen(startTime)
+			kb.Listen(startTime) // So is Listen.
+		}
+		kss := kb.Read(times.Since(startTime))
+		for _, ks := range kss[1:] {
+			if ks.Time < last {
+				t.Fatalf("time goes back: %v after %v", ks.Time, last)
+			}
+			if len(ks.KeysPressed) != 2 {
+				t.Fatalf("got %d keys, want 2", len(ks.KeysPressed))
+			}
+			last = ks.Time
+		}
+		read += len(kss) - 1
+		time.Sleep(100 * time.Microsecond)
+	}
+	kb.Stop()
+
+	if read == 0 {
+		t.Fatal("no state was read")
+	}
+	if got := len(kb.Output()); got == 0 {
+		t.Fatal("no state in output")
+	}
+	if kb.Dropped() > 0 {
+		t.Errorf("%d states dropped", kb.Dropped())
+	}
+}
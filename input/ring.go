@@ -0,0 +1,46 @@
+package input
+
+import "sync/atomic"
+
+// ringSize is enough for about 16 seconds at 250Hz polling,
+// while the game loop reads states every frame.
+const ringSize = 1 << 12
+
+// stateRing is a single-producer single-consumer queue of keyboard states.
+// The producer owns tail and the consumer owns head; each publishes
+// its index by an atomic store, so that neither side waits for the other.
+// A state written before the store of tail is visible to the consumer
+// which has loaded the tail.
+type stateRing struct {
+	states  [ringSize]KeyboardState
+	head    atomic.Uint64 // Next index to read.
+	tail    atomic.Uint64 // Next index to write.
+	dropped atomic.Uint64
+}
+
+// push is called by the producer only. A state is dropped when the ring
+// is full, which happens only when the consumer has stalled for long.
+// Since a state is a whole snapshot, dropping one loses only its time step.
+func (r *stateRing) push(s KeyboardState) bool {
+	t := r.tail.Load()
+	if t-r.head.Load() == ringSize {
+		r.dropped.Add(1)
+		return false
+	}
+	r.states[t%ringSize] = s
+	r.tail.Store(t + 1)
+	return true
+}
+
+// pop is called by the consumer only. It appends all pushed states to dst.
+func (r *stateRing) pop(dst []KeyboardState) []KeyboardState {
+	h := r.head.Load()
+	t := r.tail.Load()
+	for ; h < t; h++ {
+		i := h % ringSize
+		dst = append(dst, r.states[i])
+		r.states[i] = KeyboardState{} // Let the slice be collected.
+	}
+	r.head.Store(h)
+	return dst
+}
@@ -0,0 +1,70 @@
+package input
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+type TouchID = ebiten.TouchID
+
+// TouchSource tells which touches are on the screen and where they are.
+// Positions are in screen coordinates. It is pluggable so that
+// touches can be fed by synthetic events in tests.
+// A source is polled from the keyboard's goroutine.
+type TouchSource interface {
+	AppendTouchIDs(ids []TouchID) []TouchID
+	TouchPosition(id TouchID) (x, y float64)
+}
+
+// EbitenTouchSource reads touches from the touch screen.
+type EbitenTouchSource struct{}
+
+func (EbitenTouchSource) AppendTouchIDs(ids []TouchID) []TouchID {
+	return ebiten.AppendTouchIDs(ids)
+}
+
+func (EbitenTouchSource) TouchPosition(id TouchID) (float64, float64) {
+	x, y := ebiten.TouchPosition(id)
+	return float64(x), float64(y)
+}
+
+// TouchLane is a horizontal range of the screen bound to a lane.
+// Lanes span the whole height, so that players may touch anywhere
+// above the keys, as it is hard to keep fingers on small keys.
+type TouchLane struct {
+	MinX float64
+	MaxX float64
+}
+
+// NewTouchLanes makes lanes from center positions and widths of keys.
+func NewTouchLanes(xs, ws []float64) []TouchLane {
+	lanes := make([]TouchLane, len(xs))
+	for k, x := range xs {
+		lanes[k] = TouchLane{MinX: x - ws[k]/2, MaxX: x + ws[k]/2}
+	}
+	return lanes
+}
+
+// newFetchTouchState returns closure, as newFetchKeyboardState does.
+// A lane is pressed while any touch is in its range.
+func newFetchTouchState(src TouchSource, lanes []TouchLane) func() []bool {
+	var ids []TouchID
+	return func() []bool {
+		ps := make([]bool, len(lanes))
+		ids = src.AppendTouchIDs(ids[:0])
+		for _, id := range ids {
+			x, _ := src.TouchPosition(id)
+			for k, l := range lanes {
+				if l.MinX <= x && x < l.MaxX {
+					ps[k] = true
+					break
+				}
+			}
+		}
+		return ps
+	}
+}
+
+// AddTouchSource lets touches press lanes along with keys.
+// Touches go through the same keyboard states as keys.
+// It should be called before Listen.
+func (kb *Keyboard) AddTouchSource(src TouchSource, lanes []TouchLane) {
+	kb.addFetch(newFetchTouchState(src, lanes))
+}
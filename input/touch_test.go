@@ -0,0 +1,95 @@
+package input
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hndada/gosu/times"
+)
+
+// syntheticTouches is a touch source fed by the test.
+type syntheticTouches struct {
+	mu sync.Mutex
+	xs map[TouchID]float64
+}
+
+func (st *syntheticTouches) set(id TouchID, x float64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.xs == nil {
+		st.xs = make(map[TouchID]float64)
+	}
+	st.xs[id] = x
+}
+
+func (st *syntheticTouches) release(id TouchID) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.xs, id)
+}
+
+func (st *syntheticTouches) AppendTouchIDs(ids []TouchID) []TouchID {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id := range st.xs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (st *syntheticTouches) TouchPosition(id TouchID) (float64, float64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.xs[id], 0
+}
+
+func TestFetchTouchState(t *testing.T) {
+	// Four lanes of width 100, from x = 100 to 500.
+	lanes := NewTouchLanes([]float64{150, 250, 350, 450}, []float64{100, 100, 100, 100})
+	var src syntheticTouches
+	fetch := newFetchTouchState(&src, lanes)
+
+	for _, tc := range []struct {
+		name  string
+		touch func()
+		want  []bool
+	}{
+		{"none", func() {}, []bool{false, false, false, false}},
+		{"left edge", func() { src.set(1, 100) }, []bool{true, false, false, false}},
+		{"chord", func() { src.set(2, 420) }, []bool{true, false, false, true}},
+		{"slide", func() { src.set(1, 260) }, []bool{false, true, false, true}},
+		{"outside", func() { src.set(2, 500) }, []bool{false, true, false, false}},
+		{"release", func() { src.release(1) }, []bool{false, false, false, false}},
+	} {
+		tc.touch()
+		got := fetch()
+		for k := range tc.want {
+			if got[k] != tc.want[k] {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+// Touches are merged into keyboard states along with keys.
+func TestKeyboardTouches(t *testing.T) {
+	keys := []bool{false, true}
+	kb := newKeyboard(2, func() []bool { return append([]bool(nil), keys...) })
+	var src syntheticTouches
+	kb.AddTouchSource(&src, NewTouchLanes([]float64{50, 150}, []float64{100, 100}))
+	kb.SetPollingRate(1000)
+	src.set(1, 30)
+
+	startTime := times.Now()
+	kb.Listen(startTime)
+	time.Sleep(20 * time.Millisecond)
+	kb.Stop()
+
+	kss := kb.Read(times.Since(startTime))
+	ks := kss[len(kss)-1]
+	if !ks.KeysPressed[0] || !ks.KeysPressed[1] {
+		t.Errorf("got %v, want both lanes pressed", ks.KeysPressed)
+	}
+}
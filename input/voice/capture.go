@@ -0,0 +1,109 @@
+package voice
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+const captureSampleRate beep.SampleRate = 44100
+
+// Samples not read for this long are dropped, such as during pausing.
+const maxPendingDuration = 250 * time.Millisecond
+
+// ErrNoCaptureTool is returned on platforms which record by a command
+// line tool, when none of them is installed.
+var ErrNoCaptureTool = errors.New("no capture tool: install arecord (alsa-utils) or rec (sox)")
+
+// device records mono 16-bit samples, and calls push
+// from its own goroutine whenever samples are recorded.
+type device interface {
+	start(push func(samples [][2]float64)) error
+	close() error
+}
+
+// CaptureReader detects pitches from a microphone in real time.
+// The device never waits for the game loop, and vice versa:
+// Read takes samples recorded so far, regarding the latest one
+// as recorded at given time.
+type CaptureReader struct {
+	device device
+
+	mu      sync.Mutex
+	pending [][2]float64 // Recorded, but not read yet.
+
+	queue  *queueStreamer
+	stream *StreamReader
+}
+
+// NewCaptureReader starts recording from the default input device.
+func NewCaptureReader() (*CaptureReader, error) {
+	return newCaptureReader(newDevice(captureSampleRate))
+}
+
+func newCaptureReader(d device) (*CaptureReader, error) {
+	q := &queueStreamer{}
+	r := &CaptureReader{
+		device: d,
+		queue:  q,
+		stream: NewStreamReader(q, captureSampleRate),
+	}
+	if err := d.start(r.push); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CaptureReader) push(samples [][2]float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, samples...)
+	if over := len(r.pending) - captureSampleRate.N(maxPendingDuration); over > 0 {
+		r.pending = r.pending[:copy(r.pending, r.pending[over:])]
+	}
+}
+
+func (r *CaptureReader) Read(now time.Duration) []State {
+	r.mu.Lock()
+	r.queue.samples = append(r.queue.samples, r.pending...)
+	r.pending = r.pending[:0]
+	r.mu.Unlock()
+
+	// Recorded samples are placed so that the last one is at now.
+	available := r.stream.read + len(r.queue.samples)
+	end := captureSampleRate.D(available)
+	vss := r.stream.Read(end)
+	shift := now - end
+	for i := range vss {
+		vss[i].Time += shift
+	}
+	return vss
+}
+
+func (r *CaptureReader) Close() error { return r.device.close() }
+
+// queueStreamer streams samples which have been recorded.
+// StreamReader asks only as many samples as the queue has.
+type queueStreamer struct {
+	samples [][2]float64
+}
+
+func (q *queueStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n = copy(samples, q.samples)
+	q.samples = q.samples[:copy(q.samples, q.samples[n:])]
+	return n, true
+}
+
+func (q *queueStreamer) Err() error { return nil }
+
+// decodeS16 converts little-endian 16-bit mono samples.
+func decodeS16(dst [][2]float64, data []byte) [][2]float64 {
+	for i := 0; i+1 < len(data); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(data[i:]))) / (1 << 15)
+		dst = append(dst, [2]float64{v, v})
+	}
+	return dst
+}
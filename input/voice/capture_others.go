@@ -0,0 +1,121 @@
+//go:build !windows
+
+package voice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+// Other platforms record by a command line tool which writes raw
+// 16-bit mono samples to stdout: arecord of ALSA, or rec of SoX.
+func captureCommands(sr beep.SampleRate) [][]string {
+	rate := strconv.Itoa(int(sr))
+	return [][]string{
+		{"arecord", "-q", "-t", "raw", "-f", "S16_LE", "-c", "1", "-r", rate},
+		{"rec", "-q", "-t", "raw", "-b", "16", "-e", "signed-integer", "-c", "1", "-r", rate, "-"},
+	}
+}
+
+// A tool exits at once when it fails to open the device.
+// Recording is regarded as started when the first samples arrive.
+const captureStartTimeout = 2 * time.Second
+
+type commandDevice struct {
+	sampleRate beep.SampleRate
+	cmd        *exec.Cmd
+	done       chan struct{}
+}
+
+func newDevice(sr beep.SampleRate) device { return &commandDevice{sampleRate: sr} }
+
+// start tries tools in turn. It returns ErrNoCaptureTool when
+// none is installed, or errors of tools which failed to record.
+func (d *commandDevice) start(push func(samples [][2]float64)) error {
+	var errs []error
+	for _, args := range captureCommands(d.sampleRate) {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		err := d.startCommand(args, push)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return ErrNoCaptureTool
+	}
+	return errors.Join(errs...)
+}
+
+func (d *commandDevice) startCommand(args []string, push func(samples [][2]float64)) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	data := make([]byte, 2*d.sampleRate.N(hopDuration/2))
+	first := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(stdout, data)
+		first <- err
+	}()
+	select {
+	case err = <-first:
+	case <-time.After(captureStartTimeout):
+		err = errors.New("no samples recorded")
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return fmt.Errorf("failed to record by %s: %w", args[0], err)
+	}
+
+	push(decodeS16(nil, data))
+	d.cmd = cmd
+	d.done = make(chan struct{})
+	go d.run(stdout, data, push)
+	return nil
+}
+
+func (d *commandDevice) run(r io.Reader, data []byte, push func(samples [][2]float64)) {
+	defer close(d.done)
+	var samples [][2]float64
+	for {
+		// The chunk has even length, hence no sample is split.
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+		samples = decodeS16(samples[:0], data)
+		push(samples)
+	}
+}
+
+func (d *commandDevice) close() error {
+	if d.cmd == nil {
+		return nil
+	}
+	d.cmd.Process.Kill()
+	<-d.done
+	d.cmd.Wait() // Killed process always returns error.
+	d.cmd = nil
+	return nil
+}
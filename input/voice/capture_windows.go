@@ -0,0 +1,166 @@
+//go:build windows
+
+package voice
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/gopxl/beep"
+	"golang.org/x/sys/windows"
+)
+
+// https://learn.microsoft.com/en-us/windows/win32/multimedia/recording-waveform-audio
+var (
+	modwinmm                  = windows.NewLazyDLL("winmm.dll")
+	procWaveInOpen            = modwinmm.NewProc("waveInOpen")
+	procWaveInPrepareHeader   = modwinmm.NewProc("waveInPrepareHeader")
+	procWaveInUnprepareHeader = modwinmm.NewProc("waveInUnprepareHeader")
+	procWaveInAddBuffer       = modwinmm.NewProc("waveInAddBuffer")
+	procWaveInStart           = modwinmm.NewProc("waveInStart")
+	procWaveInReset           = modwinmm.NewProc("waveInReset")
+	procWaveInClose           = modwinmm.NewProc("waveInClose")
+)
+
+const (
+	waveMapper    = 0xFFFFFFFF
+	waveFormatPCM = 1
+	whdrDone      = 0x00000001
+)
+
+type waveFormatEx struct {
+	FormatTag      uint16
+	Channels       uint16
+	SamplesPerSec  uint32
+	AvgBytesPerSec uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+	Size           uint16
+}
+
+type waveHdr struct {
+	Data          *byte
+	BufferLength  uint32
+	BytesRecorded uint32
+	User          uintptr
+	Flags         uint32
+	Loops         uint32
+	Next          uintptr
+	Reserved      uintptr
+}
+
+// Buffers are recorded in turn. A recorded buffer is handed
+// to the device again once its samples are pushed.
+const waveInBufferCount = 4
+
+type waveInDevice struct {
+	sampleRate beep.SampleRate
+	handle     uintptr
+	hdrs       [waveInBufferCount]waveHdr
+	bufs       [waveInBufferCount][]byte
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func newDevice(sr beep.SampleRate) device { return &waveInDevice{sampleRate: sr} }
+
+func (d *waveInDevice) start(push func(samples [][2]float64)) error {
+	wf := waveFormatEx{
+		FormatTag:      waveFormatPCM,
+		Channels:       1,
+		SamplesPerSec:  uint32(d.sampleRate),
+		AvgBytesPerSec: uint32(d.sampleRate) * 2,
+		BlockAlign:     2,
+		BitsPerSample:  16,
+	}
+	// CALLBACK_NULL: recorded buffers are polled by their flags.
+	r, _, _ := procWaveInOpen.Call(uintptr(unsafe.Pointer(&d.handle)),
+		waveMapper, uintptr(unsafe.Pointer(&wf)), 0, 0, 0)
+	if r != 0 {
+		return fmt.Errorf("failed to open wave input: error %d", r)
+	}
+
+	size := 2 * d.sampleRate.N(hopDuration/2)
+	for i := range d.hdrs {
+		d.bufs[i] = make([]byte, size)
+		d.hdrs[i] = waveHdr{Data: &d.bufs[i][0], BufferLength: uint32(size)}
+		if err := d.call(procWaveInPrepareHeader, &d.hdrs[i]); err != nil {
+			d.close()
+			return err
+		}
+		if err := d.call(procWaveInAddBuffer, &d.hdrs[i]); err != nil {
+			d.close()
+			return err
+		}
+	}
+	if r, _, _ := procWaveInStart.Call(d.handle); r != 0 {
+		d.close()
+		return fmt.Errorf("failed to start wave input: error %d", r)
+	}
+
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	go d.run(push)
+	return nil
+}
+
+func (d *waveInDevice) call(proc *windows.LazyProc, hdr *waveHdr) error {
+	r, _, _ := proc.Call(d.handle, uintptr(unsafe.Pointer(hdr)), unsafe.Sizeof(*hdr))
+	if r != 0 {
+		return fmt.Errorf("failed to call %s: error %d", proc.Name, r)
+	}
+	return nil
+}
+
+func (d *waveInDevice) run(push func(samples [][2]float64)) {
+	defer close(d.done)
+	ticker := time.NewTicker(hopDuration / 4)
+	defer ticker.Stop()
+	var samples [][2]float64
+	var i int
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+		for {
+			hdr := &d.hdrs[i]
+			if atomic.LoadUint32(&hdr.Flags)&whdrDone == 0 {
+				break
+			}
+			samples = decodeS16(samples[:0], d.bufs[i][:hdr.BytesRecorded])
+			push(samples)
+			if err := d.call(procWaveInAddBuffer, hdr); err != nil {
+				return
+			}
+			i = (i + 1) % waveInBufferCount
+		}
+	}
+}
+
+func (d *waveInDevice) close() error {
+	if d.handle == 0 {
+		return nil
+	}
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+		d.stop = nil
+	}
+	// Reset marks all buffers done, so that they can be unprepared.
+	procWaveInReset.Call(d.handle)
+	for i := range d.hdrs {
+		if d.hdrs[i].Data != nil {
+			d.call(procWaveInUnprepareHeader, &d.hdrs[i])
+		}
+	}
+	r, _, _ := procWaveInClose.Call(d.handle)
+	d.handle = 0
+	if r != 0 {
+		return fmt.Errorf("failed to close wave input: error %d", r)
+	}
+	return nil
+}
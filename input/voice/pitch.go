@@ -0,0 +1,94 @@
+package voice
+
+import "math"
+
+// Voice range of human: roughly from 80Hz (E2) to 1000Hz (B5).
+const (
+	minFrequency = 80
+	maxFrequency = 1000
+)
+
+const (
+	yinThreshold = 0.15
+	minVolume    = 0.01 // Frames quieter than this in RMS are unvoiced.
+)
+
+// Detect returns fundamental frequency of the samples in Hz.
+// It is based on YIN algorithm: the first dip of cumulative mean
+// normalized difference function below the threshold is the period.
+// ok is false when the samples are silent or have no clear pitch.
+func Detect(samples []float64, sampleRate int) (freq float64, ok bool) {
+	if rms(samples) < minVolume {
+		return 0, false
+	}
+
+	minTau := sampleRate / maxFrequency
+	maxTau := sampleRate / minFrequency
+	w := len(samples) - maxTau
+	if w <= 0 || minTau < 1 {
+		return 0, false
+	}
+
+	ds := make([]float64, maxTau+1)
+	for tau := 1; tau <= maxTau; tau++ {
+		var sum float64
+		for j := 0; j < w; j++ {
+			d := samples[j] - samples[j+tau]
+			sum += d * d
+		}
+		ds[tau] = sum
+	}
+
+	// Cumulative mean normalized difference.
+	ds[0] = 1
+	var cum float64
+	for tau := 1; tau <= maxTau; tau++ {
+		cum += ds[tau]
+		if cum == 0 {
+			ds[tau] = 1
+			continue
+		}
+		ds[tau] *= float64(tau) / cum
+	}
+
+	for tau := minTau; tau < maxTau; tau++ {
+		if ds[tau] >= yinThreshold {
+			continue
+		}
+		// Walk down to the bottom of the dip.
+		for tau+1 < maxTau && ds[tau+1] < ds[tau] {
+			tau++
+		}
+		return float64(sampleRate) / interpolate(ds, tau), true
+	}
+	return 0, false
+}
+
+// interpolate finds the vertex of parabola through three points
+// around tau for sub-sample precision.
+func interpolate(ds []float64, tau int) float64 {
+	if tau < 1 || tau+1 >= len(ds) {
+		return float64(tau)
+	}
+	a, b, c := ds[tau-1], ds[tau], ds[tau+1]
+	denom := a - 2*b + c
+	if denom == 0 {
+		return float64(tau)
+	}
+	return float64(tau) + (a-c)/(2*denom)
+}
+
+func rms(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// Tone returns MIDI note number of the frequency, which may be fractional.
+// A4 (440Hz) is 69.
+func Tone(freq float64) float64 { return 69 + 12*math.Log2(freq/440) }
@@ -0,0 +1,115 @@
+package voice
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/wav"
+)
+
+// State is a pitch detected at given time.
+// Tone is MIDI note number; it is meaningful only when Voiced is true.
+type State struct {
+	Time   time.Duration
+	Tone   float64
+	Voiced bool
+}
+
+// A primary purpose of voice input is to provide pairs of {time, pitch},
+// just like KeyboardReader does with keyboard states.
+type Reader interface {
+	Read(now time.Duration) []State
+}
+
+const (
+	windowDuration = 40 * time.Millisecond // Long enough to hold two periods of 80Hz.
+	hopDuration    = 20 * time.Millisecond
+)
+
+// StreamReader detects pitches from any audio stream.
+// A microphone works as a stream as well as an audio file does.
+type StreamReader struct {
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	window     []float64 // Mono samples of the latest window.
+	buf        [][2]float64
+	read       int // The number of samples read so far.
+	done       bool
+}
+
+func NewStreamReader(s beep.Streamer, sr beep.SampleRate) *StreamReader {
+	return &StreamReader{
+		streamer:   s,
+		sampleRate: sr,
+		window:     make([]float64, 0, sr.N(windowDuration)),
+		buf:        make([][2]float64, sr.N(hopDuration)),
+	}
+}
+
+// Read detects pitches of the stream up to the given time.
+// Each state is at the end of its analysis window.
+func (r *StreamReader) Read(now time.Duration) (vss []State) {
+	target := r.sampleRate.N(now)
+	for !r.done && r.read+len(r.buf) <= target {
+		n, ok := r.streamer.Stream(r.buf)
+		if !ok || n == 0 {
+			r.done = true
+			break
+		}
+		r.read += n
+		r.push(r.buf[:n])
+
+		state := State{Time: r.sampleRate.D(r.read)}
+		if len(r.window) == cap(r.window) {
+			freq, ok := Detect(r.window, int(r.sampleRate))
+			if ok {
+				state.Tone = Tone(freq)
+				state.Voiced = true
+			}
+		}
+		vss = append(vss, state)
+	}
+	return
+}
+
+// push appends down-mixed samples to the window,
+// dropping the oldest ones when the window is full.
+func (r *StreamReader) push(samples [][2]float64) {
+	size := cap(r.window)
+	if over := len(r.window) + len(samples) - size; over > 0 {
+		over = min(over, len(r.window))
+		r.window = r.window[:copy(r.window, r.window[over:])]
+	}
+	if len(samples) > size {
+		samples = samples[len(samples)-size:]
+	}
+	for _, s := range samples {
+		r.window = append(r.window, (s[0]+s[1])/2)
+	}
+}
+
+// WAVReader is mainly for tests: a WAV file stands in for a microphone.
+type WAVReader struct {
+	*StreamReader
+	closer io.Closer
+}
+
+func NewWAVReader(r io.Reader) (*WAVReader, error) {
+	streamer, format, err := wav.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("NewWAVReader: %w", err)
+	}
+	return &WAVReader{
+		StreamReader: NewStreamReader(streamer, format.SampleRate),
+		closer:       streamer,
+	}, nil
+}
+
+func (r *WAVReader) Close() error { return r.closer.Close() }
+
+// SilentReader is used when there is no available input device.
+type SilentReader struct{}
+
+func (SilentReader) Read(now time.Duration) []State { return nil }
@@ -0,0 +1,162 @@
+package voice
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/wav"
+)
+
+// sineWAV writes a WAV file which sings given tones for a second each.
+func sineWAV(t *testing.T, tones []float64) string {
+	const sr beep.SampleRate = 44100
+	var i int
+	streamer := beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		total := sr.N(time.Second) * len(tones)
+		for n = range samples {
+			if i >= total {
+				return n, n > 0
+			}
+			tone := tones[i/sr.N(time.Second)]
+			freq := 440 * math.Pow(2, (tone-69)/12)
+			v := 0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sr))
+			samples[n] = [2]float64{v, v}
+			i++
+		}
+		return len(samples), true
+	})
+
+	name := filepath.Join(t.TempDir(), "voice.wav")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	format := beep.Format{SampleRate: sr, NumChannels: 2, Precision: 2}
+	if err := wav.Encode(f, streamer, format); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestWAVReader(t *testing.T) {
+	tones := []float64{69, 57, 64} // A4, A3, E4
+	f, err := os.Open(sineWAV(t, tones))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewWAVReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Read in small steps, as the game does at each update.
+	var vss []State
+	for now := time.Duration(0); now <= 3*time.Second; now += 16 * time.Millisecond {
+		vss = append(vss, r.Read(now)...)
+	}
+	if len(vss) == 0 {
+		t.Fatal("no states")
+	}
+
+	for _, vs := range vss {
+		// Skip windows overlapping a tone change.
+		offset := vs.Time % time.Second
+		if offset < windowDuration || vs.Time >= 3*time.Second {
+			continue
+		}
+		want := tones[vs.Time/time.Second]
+		if !vs.Voiced {
+			t.Fatalf("%v: unvoiced, want %.0f", vs.Time, want)
+		}
+		if math.Abs(vs.Tone-want) > 0.1 {
+			t.Fatalf("%v: got tone %.2f, want %.0f", vs.Time, vs.Tone, want)
+		}
+	}
+}
+
+func TestSilence(t *testing.T) {
+	samples := make([]float64, 2048)
+	if _, ok := Detect(samples, 44100); ok {
+		t.Error("silence should be unvoiced")
+	}
+}
+
+// fakeDevice records a tone of A4 whenever the test asks.
+type fakeDevice struct {
+	push func(samples [][2]float64)
+	i    int
+}
+
+func (d *fakeDevice) start(push func(samples [][2]float64)) error {
+	d.push = push
+	return nil
+}
+
+func (d *fakeDevice) record(duration time.Duration) {
+	samples := make([][2]float64, captureSampleRate.N(duration))
+	for n := range samples {
+		v := 0.5 * math.Sin(2*math.Pi*440*float64(d.i)/float64(captureSampleRate))
+		samples[n] = [2]float64{v, v}
+		d.i++
+	}
+	d.push(samples)
+}
+
+func (d *fakeDevice) close() error { return nil }
+
+func TestCaptureReader(t *testing.T) {
+	d := &fakeDevice{}
+	r, err := newCaptureReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// The game starts reading a while after the device starts.
+	const lag = 500 * time.Millisecond
+	var vss []State
+	for now := time.Duration(0); now <= time.Second; now += 16 * time.Millisecond {
+		d.record(16 * time.Millisecond)
+		got := r.Read(lag + now)
+		for _, vs := range got {
+			if vs.Time > lag+now {
+				t.Fatalf("%v: state at %v is from the future", lag+now, vs.Time)
+			}
+		}
+		vss = append(vss, got...)
+	}
+	if len(vss) == 0 {
+		t.Fatal("no states")
+	}
+	// Skip states before the first window is filled.
+	for _, vs := range vss[windowDuration/hopDuration-1:] {
+		if !vs.Voiced || math.Abs(vs.Tone-69) > 0.1 {
+			t.Fatalf("%v: got tone %.2f (voiced: %v), want 69", vs.Time, vs.Tone, vs.Voiced)
+		}
+	}
+	if last := vss[len(vss)-1].Time; last < lag+time.Second-windowDuration-hopDuration {
+		t.Errorf("last state at %v, want near %v", last, lag+time.Second)
+	}
+}
+
+// Samples not read for a long time, such as during pausing, are dropped.
+func TestCaptureReaderDropsStaleSamples(t *testing.T) {
+	d := &fakeDevice{}
+	r, err := newCaptureReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	d.record(3 * time.Second)
+	r.Read(0)
+	if n := r.stream.read + len(r.queue.samples); n > captureSampleRate.N(maxPendingDuration) {
+		t.Errorf("got %d samples, want at most %d", n, captureSampleRate.N(maxPendingDuration))
+	}
+}
@@ -1,13 +1,26 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hndada/gosu/audios"
+	"github.com/hndada/gosu/format/midi"
+	"github.com/hndada/gosu/format/osr"
 	"github.com/hndada/gosu/game"
+	"github.com/hndada/gosu/game/calibrate"
+	"github.com/hndada/gosu/game/keybind"
 	"github.com/hndada/gosu/game/play"
 	"github.com/hndada/gosu/game/selects"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/plays/drum"
 	"github.com/hndada/gosu/plays/piano"
+	"github.com/hndada/gosu/plays/sing"
 )
 
 var testPlayArgs = game.PlayArgs{
@@ -20,7 +33,54 @@ var testPlayArgs = game.PlayArgs{
 	// ReplayFilename string
 }
 
+// Play's audio is rendered to a WAV file when -render is given:
+// gosu -render out.wav -chart path/to/chart.osu -replay path/to/replay.osr
+var (
+	renderName = flag.String("render", "", "render a play's audio to the WAV file")
+	chartName  = flag.String("chart", "", "chart file to render")
+	replayName = flag.String("replay", "", "replay file to render")
+)
+
+// MIDI is converted to a piano chart when -midi is given. The chart is
+// saved next to the MIDI file: gosu -midi song.mid -keys 7 -strategy density
+var (
+	midiName     = flag.String("midi", "", "convert the MIDI file to a piano chart")
+	midiKeyCount = flag.Int("keys", 7, "key count of the converted chart")
+	midiStrategy = flag.String("strategy", midi.StrategyPitchRange, "lane mapping: pitch-range, track or density")
+)
+
+// Audio output is chosen at startup. Null output requires no audio device,
+// and record output writes what is played to recordName as it goes.
+var audioOutput = flag.String("audio", "speaker", "audio output: speaker, null or record")
+
+const recordName = "record.wav"
+
 func main() {
+	flag.Parse()
+	var recorder *audios.NullOutput
+	switch *audioOutput {
+	case "null":
+		audios.SetOutput(audios.NewNullOutput())
+	case "record":
+		f, err := os.Create(recordName)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		recorder, err = audios.NewRecorder(f)
+		if err != nil {
+			panic(err)
+		}
+		audios.SetOutput(recorder)
+	}
+
+	if *midiName != "" {
+		if err := convertMIDI(); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
 		panic(err)
@@ -31,6 +91,12 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	if *renderName != "" {
+		if err := render(g); err != nil {
+			panic(err)
+		}
+		return
+	}
 	{
 		scn, err := selects.Scene{}.New(g, nil)
 		if err != nil {
@@ -45,9 +111,88 @@ func main() {
 		}
 		g.ScenePlay = scn
 	}
+	g.SceneCalibrate = &calibrate.Scene{}
+	g.SceneKeyBinding = &keybind.Scene{}
 	g.CurrentScene = g.SceneSelect
 
 	if err := ebiten.RunGame(g); err != nil {
 		panic(err)
 	}
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func render(g *game.Game) error {
+	args := game.PlayArgs{
+		ChartFS:        os.DirFS(filepath.Dir(*chartName)),
+		ChartFilename:  filepath.Base(*chartName),
+		ReplayFS:       os.DirFS(filepath.Dir(*replayName)),
+		ReplayFilename: filepath.Base(*replayName),
+	}
+	mods, err := renderMods(args)
+	if err != nil {
+		return err
+	}
+	args.Mods = mods
+	f, err := os.Create(*renderName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return play.RenderAudio(g, args, f)
+}
+
+// Mods are chosen by the chart's mode. A converted chart is played
+// in the key count of the replay's key mod, as osu! converts it so.
+func renderMods(args game.PlayArgs) (plays.Mods, error) {
+	c, err := plays.NewChartHeaderFromFile(args.ChartFS, args.ChartFilename)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Mode {
+	case plays.ModePiano:
+		var mods piano.Mods
+		if strings.ToLower(filepath.Ext(args.ReplayFilename)) == ".osr" {
+			data, err := fs.ReadFile(args.ReplayFS, args.ReplayFilename)
+			if err != nil {
+				return nil, err
+			}
+			f, err := osr.NewFormat(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse replay file: %w", err)
+			}
+			mods.KeyCount = f.KeyCount()
+		}
+		return mods, nil
+	case plays.ModeDrum:
+		return drum.Mods{}, nil
+	case plays.ModeSing:
+		return sing.Mods{}, nil
+	}
+	return nil, fmt.Errorf("unsupported mode of chart: %s", args.ChartFilename)
+}
+
+func convertMIDI() error {
+	data, err := os.ReadFile(*midiName)
+	if err != nil {
+		return err
+	}
+	opts := midi.NewConvertOptions(*midiKeyCount)
+	opts.Strategy = *midiStrategy
+	c, format, err := piano.NewChartFromMIDI(data, opts, piano.Mods{})
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(*midiName, filepath.Ext(*midiName))
+	name := fmt.Sprintf("%s [%s].osu", base, format.Version)
+	if err := os.WriteFile(name, format.Encode(), 0644); err != nil {
+		return err
+	}
+	counts := c.NoteCounts()
+	fmt.Printf("%s: %d notes, %d long notes\n", name, counts[0], counts[1])
+	return nil
 }
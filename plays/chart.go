@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/hndada/gosu/format/osu"
+	"github.com/hndada/gosu/format/ultrastar"
 	"github.com/hndada/gosu/util"
 )
 
@@ -30,6 +31,10 @@ type Chart interface {
 	// notes
 	NoteCounts() []int
 	TotalDuration() int32 // Span()
+
+	// sections
+	Difficulties() []float64
+	Densities() []float64
 }
 
 // ChartHeader contains non-play information.
@@ -59,6 +64,14 @@ type ChartHeader struct {
 	Mode    int
 	SubMode int
 
+	// Converted is true when the chart is converted from other mode,
+	// such as osu!standard. A converted chart can be played in every mode.
+	Converted bool
+
+	// SamplesMatchPlaybackRate is true when samples should be played
+	// faster and higher along with rate mods, as music does.
+	SamplesMatchPlaybackRate bool
+
 	// Hash works as id in database.
 	// Hash is not exported to file.
 	// ChartHash [16]byte // MD5
@@ -85,6 +98,12 @@ func LoadChartFormat(fsys fs.FS, name string) (any, string, error) {
 			return nil, "", err
 		}
 		return format, hash, nil
+	case ".txt", ".TXT":
+		format, err := ultrastar.NewFormat(data)
+		if err != nil {
+			return nil, "", err
+		}
+		return format, hash, nil
 	}
 	return nil, "", fmt.Errorf("unsupported file format")
 }
@@ -101,6 +120,10 @@ func NewChartHeaderFromFile(fsys fs.FS, name string) (*ChartHeader, error) {
 		c := newChartHeaderFromOsu(format)
 		c.ChartHash = hash
 		return c, nil
+	case *ultrastar.Format:
+		c := newChartHeaderFromUltraStar(format)
+		c.ChartHash = hash
+		return c, nil
 	}
 
 	return nil, fmt.Errorf("unsupported file format")
@@ -113,6 +136,10 @@ func NewChartHeaderFromFormat(format any, hash string) *ChartHeader {
 		c := newChartHeaderFromOsu(format)
 		c.ChartHash = hash
 		return c
+	case *ultrastar.Format:
+		c := newChartHeaderFromUltraStar(format)
+		c.ChartHash = hash
+		return c
 	}
 	return nil
 }
@@ -136,6 +163,8 @@ func newChartHeaderFromOsu(format *osu.Format) (c *ChartHeader) {
 
 		PreviewTime:   int32(format.PreviewTime),
 		MusicFilename: format.AudioFilename,
+
+		SamplesMatchPlaybackRate: format.SamplesMatchPlaybackRate,
 	}
 
 	var e osu.Event
@@ -151,8 +180,14 @@ func newChartHeaderFromOsu(format *osu.Format) (c *ChartHeader) {
 	c.Mode = -1
 	switch format.Mode {
 	case osu.ModeStandard:
+		// Piano is the default mode of converted charts.
+		// Drum mode converts the chart by itself.
+		c.Mode = ModePiano
+		c.SubMode = format.ManiaKeyCount()
+		c.Converted = true
 	case osu.ModeTaiko:
 		c.Mode = ModeDrum
+		c.SubMode = 4
 	case osu.ModeCatch:
 	case osu.ModeMania:
 		c.Mode = ModePiano
@@ -161,6 +196,51 @@ func newChartHeaderFromOsu(format *osu.Format) (c *ChartHeader) {
 	return
 }
 
+// UltraStar chart has no chart name; edition goes chart name instead.
+// SubMode stands for the number of singers.
+func newChartHeaderFromUltraStar(format *ultrastar.Format) (c *ChartHeader) {
+	const unknownID = -1
+	c = &ChartHeader{
+		SetID: unknownID,
+		ID:    unknownID,
+
+		MusicName: format.Title,
+		Artist:    format.Artist,
+		ChartName: format.Edition,
+		Charter:   format.Creator,
+		CharterID: unknownID,
+		HolderID:  unknownID,
+
+		PreviewTime:        -1,
+		MusicFilename:      format.AudioFilename,
+		BackgroundFilename: format.Background,
+		VideoFilename:      format.Video,
+		VideoTimeOffset:    int32(-format.VideoGap * 1000),
+
+		Mode:    ModeSing,
+		SubMode: 1,
+	}
+	if c.ChartName == "" {
+		c.ChartName = "Sing"
+	}
+	// The song starts at START, which makes a decent preview as well.
+	switch {
+	case format.PreviewStart >= 0:
+		c.PreviewTime = int32(format.PreviewStart * 1000)
+	case format.Start > 0:
+		c.PreviewTime = int32(format.Start * 1000)
+	}
+	if c.BackgroundFilename == "" {
+		c.BackgroundFilename = format.Cover
+	}
+	for _, tag := range []string{format.Genre, format.Language} {
+		if tag != "" {
+			c.Tags = append(c.Tags, tag)
+		}
+	}
+	return
+}
+
 func (c ChartHeader) WindowTitle() string {
 	return fmt.Sprintf("gosu | %s - %s [%s] (%s) ", c.Artist, c.MusicName, c.ChartName, c.Charter)
 }
@@ -1,64 +1,74 @@
 package drum
 
-import "github.com/hndada/gosu/mode"
+import (
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+)
 
+// Drum and Piano modes have different bar drawing methods.
+// Hence, this method is defined per game mode.
 type Bar struct {
-	Floater
-	Next *Bar
-	Prev *Bar
+	position float64
 }
 
-func NewBars(transPoints []*mode.Dynamic, duration int64) (bs []*Bar) {
-	var start, end, step float64 // Next time.
+type Bars struct {
+	data  []Bar
+	index int
+}
 
-	// Bar positions before first Dynamic.
-	start = float64(transPoints[0].Time)
-	end = start
-	if end > -5000 {
-		end = -5000
-	}
-	step = transPoints[0].BeatDuration()
-	for t := start; t >= end; t -= step {
-		b := Bar{
-			Floater: Floater{
-				Time: int64(t),
-			},
-		}
-		bs = append([]*Bar{&b}, bs...)
+// Given Dynamics' index is 0 and it is fine to modify it.
+func NewBars(dys plays.Dynamics) Bars {
+	times := dys.BeatTimes()
+	bs := make([]Bar, len(times))
+	dys.Reset()
+	for i, t := range times {
+		dys.UpdateIndex(t)
+		bs[i] = Bar{position: dys.Position(t)}
 	}
+	return Bars{data: bs}
+}
 
-	// Bar positions after first Dynamic.
-	bs = bs[:len(bs)-1] // Drop for avoiding duplicattion
-	newBeatPoints := make([]*mode.Dynamic, 0)
-	for _, dy := range transPoints {
-		if dy.NewBeat {
-			newBeatPoints = append(newBeatPoints, tp)
-		}
-	}
-	for i, dy := range newBeatPoints {
-		start = float64(dy.Time)
-		if i == len(newBeatPoints)-1 {
-			end = float64(duration + 5000)
-		} else {
-			end = float64(newBeatPoints[i+1].Time)
-		}
-		step = dy.BeatDuration()
-		for t := start; t < end; t += step {
-			b := Bar{
-				Floater: Floater{
-					Time: int64(t),
-				},
-			}
-			bs = append(bs, &b)
+type BarsComponent struct {
+	bars   Bars
+	sprite draws.Sprite
+	opts   *Options
+	cursor float64
+}
+
+func NewBarsComponent(res *Resources, opts *Options, c *Chart) (cmp BarsComponent) {
+	s := draws.NewSprite(res.BarImage)
+	s.SetSize(1, opts.fieldInnerHeight)
+	s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+	cmp.sprite = s
+	cmp.bars = NewBars(c.Dynamics)
+	cmp.opts = opts
+	return
+}
+
+func (cmp *BarsComponent) Update(cursor float64) {
+	cmp.cursor = cursor
+	lowermost, _ := visibleRange(cmp.opts, cursor)
+	for i := cmp.bars.index; i < len(cmp.bars.data); i++ {
+		b := cmp.bars.data[i]
+		if b.position > lowermost {
+			break
 		}
+		// index should be updated outside of if block.
+		cmp.bars.index = i
 	}
-	var prev *Bar
-	for _, b := range bs {
-		b.Prev = prev
-		if prev != nil {
-			prev.Next = b
+}
+
+// Bars are fixed. Lane itself moves, all bars move as same amount.
+func (cmp BarsComponent) Draw(dst draws.Image) {
+	_, uppermost := visibleRange(cmp.opts, cmp.cursor)
+	for i := cmp.bars.index; i < len(cmp.bars.data); i++ {
+		b := cmp.bars.data[i]
+		if b.position > uppermost {
+			break
 		}
-		prev = b
+
+		s := cmp.sprite
+		s.Move(b.position-cmp.cursor, 0)
+		s.Draw(dst)
 	}
-	return
 }
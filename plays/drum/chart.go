@@ -1,183 +1,122 @@
 package drum
 
 import (
-	"crypto/md5"
-	"fmt"
 	"io/fs"
-	"path/filepath"
 
 	"github.com/hndada/gosu/format/osu"
-	"github.com/hndada/gosu/mode"
+	"github.com/hndada/gosu/plays"
 )
 
-const Mode = 1 // ModeDrum
-type Floater struct {
-	Time  int64
-	Speed float64
-}
-
-func (f Floater) Position(time int64) float64 {
-	return float64(f.Time-time) * f.Speed
-}
+// Drum has only one key layout: 4 keys.
+const keyCount = 4
 
+// Drum chart has three kinds of notes which are judged separately.
+// Dots are ticks in a Roll note.
 type Chart struct {
-	mode.ChartHeader
-	MD5      [16]byte
-	Dynamics []*mode.Dynamic
-	Notes    []*Note
-	Rolls    []*Note
-	Shakes   []*Note
-	Dots     []*Dot // Ticks in a Roll note.
-	Bars     []*Bar
+	Mods Mods
+	*plays.ChartHeader
+	plays.Dynamics
+	Notes  []Note
+	Rolls  []Note
+	Shakes []Note
+	Dots   []Dot
 
-	Level        float64
-	ScoreFactors [3]float64
+	BackgroundSamples []plays.BackgroundSample
 }
 
-var (
-	// TickDensity  float64 = 4
-	DotDensity   float64 = 4 // Infers how many dots per beat in Roll note.
-	ShakeDensity float64 = 3 // Infers how many shakes per beat in Shake note.
-)
+func NewChart(fsys fs.FS, name string, mods Mods) (*Chart, error) {
+	c := &Chart{
+		Mods: mods,
+	}
 
-// LoadChart takes file path as input for starting with parsing.
-// Chart data should not rely on the ChartInfo; users may have modified it.
-func LoadChart(fsys fs.FS, name string) (c *Chart, err error) {
-	var dat []byte
-	dat, err = fs.ReadFile(fsys, name)
+	format, hash, err := plays.LoadChartFormat(fsys, name)
 	if err != nil {
-		return
-	}
-	var f any
-	switch filepath.Ext(name) {
-	case ".osu":
-		f, err = osu.NewFormat(dat)
-		if err != nil {
-			return
-		}
+		return c, err
 	}
-	c = new(Chart)
-	c.ChartHeader = mode.NewChartHeader(f)
-	c.Mode = Mode
-	c.SubMode = 4
-	c.MD5 = md5.Sum(dat)
-	c.Dynamics = mode.NewDynamics(f)
-	if len(c.Dynamics) == 0 {
-		err = fmt.Errorf("no Dynamics in the chart")
-		return
+	header := plays.NewChartHeaderFromFormat(format, hash)
+	c.ChartHeader = header
+
+	if f, ok := format.(*osu.Format); ok && f.Mode == osu.ModeStandard {
+		format = f.ToTaiko()
+		c.Mode = plays.ModeDrum
+		c.SubMode = keyCount
 	}
-	mainBPM, _, _ := c.BPMs()
-	bpmScale := c.Dynamics[0].BPM / mainBPM
-	for _, dy := range c.Dynamics {
-		dy.Speed *= bpmScale
+
+	dys, err := plays.NewDynamics(format)
+	if err != nil {
+		return c, err
 	}
+	c.Dynamics = dys
+	c.BackgroundSamples = plays.NewBackgroundSamples(format)
 
-	c.Notes, c.Rolls, c.Shakes = NewNotes(f)
-	var dy *mode.Dynamic
-	for _, ns := range [][]*Note{c.Notes, c.Rolls, c.Shakes} {
-		dy = c.Dynamics[0]
-		for _, n := range ns {
-			for dy.Next != nil && n.Time >= dy.Next.Time {
-				dy = dy.Next
-			}
-			n.Speed = dy.Speed
-			bpm := ScaledBPM(dy.BPM)
-			if n.Type == Roll {
-				switch f := f.(type) {
-				case *osu.Format:
-					// speedFactor := c.Dynamics[0].BPM / 60000 * (f.SliderMultiplier * 100)
-					speed := dy.BPM * (dy.Speed / bpmScale) / 60000 * f.SliderMultiplier * 100 // Unit is osupixel / 100ms.
-					n.Duration = int64(n.length / speed)
-				}
-			}
-			switch n.Type {
-			case Roll:
-				n.Tick = int(float64(n.Duration)*bpm/60000*DotDensity+0.1) + 1
-			case Shake:
-				n.Tick = int(float64(n.Duration)*bpm/60000*ShakeDensity+0.1) + 1
-			}
-		}
+	switch format := format.(type) {
+	case *osu.Format:
+		c.Notes, c.Rolls, c.Shakes = newNotesFromOsu(format, dys)
 	}
 	c.Dots = NewDots(c.Rolls)
-	c.Bars = NewBars(c.Dynamics, c.Duration())
-	dy = c.Dynamics[0]
-	for _, b := range c.Bars {
-		for dy.Next != nil && b.Time >= dy.Next.Time {
-			dy = dy.Next
+	c.setPositions()
+	return c, nil
+}
+
+// Position calculation is based on Dynamics.
+// Farther note has larger position.
+func (c *Chart) setPositions() {
+	dys := c.Dynamics
+	for _, ns := range [][]Note{c.Notes, c.Rolls, c.Shakes} {
+		dys.Reset()
+		for i, n := range ns {
+			dys.UpdateIndex(n.Time)
+			ns[i].position = dys.Position(n.Time)
+			ns[i].tailPosition = dys.Position(n.Time + n.Duration)
 		}
-		b.Speed = dy.Speed
 	}
-	c.Level, c.ScoreFactors = mode.Level(c)
-	return
+	dys.Reset()
+	for i, d := range c.Dots {
+		dys.UpdateIndex(d.Time)
+		c.Dots[i].position = dys.Position(d.Time)
+	}
+	dys.Reset()
 }
 
-const (
-	MaxScaledBPM = 280 // 256
-	MinScaledBPM = 60  // 128
-)
+// NoteCounts returns counts of Normal notes, Rolls, and Shakes.
+func (c Chart) NoteCounts() []int {
+	return []int{len(c.Notes), len(c.Rolls), len(c.Shakes)}
+}
 
-func (c Chart) Duration() (last int64) {
-	for _, ns := range [][]*Note{c.Notes, c.Rolls, c.Shakes} {
+func (c Chart) TotalDuration() int32 {
+	var last int32
+	for _, ns := range [][]Note{c.Notes, c.Rolls, c.Shakes} {
 		if len(ns) == 0 {
 			continue
 		}
 		n := ns[len(ns)-1]
-		if last2 := n.Time + n.Duration; last < last2 {
-			last = last2
+		if t := n.Time + n.Duration; last < t {
+			last = t
 		}
 	}
-	return
-}
-func (c Chart) NoteCounts() (vs []int) {
-	vs = make([]int, 3)
-	for _, n := range c.Notes {
-		vs[n.Type]++
-	}
-	return
-}
-func (c Chart) BPMs() (main, min, max float64) {
-	return mode.BPMs(c.Dynamics, c.Duration())
+	return last
 }
 
-// It is proved that all BPMs are set into [min, max) by v*2 or v/2 if 2 * min >= max.
+const (
+	maxScaledBPM = 280
+	minScaledBPM = 60
+)
+
+// ScaledBPM returns BPM which is in the range of [60, 280].
+// All BPMs are set into the range by v*2 or v/2, since 2 * min < max.
+// It is used for the period of animations such as dancer.
 func ScaledBPM(bpm float64) float64 {
 	if bpm < 0 {
 		bpm = -bpm
 	}
-	switch {
-	case bpm > MaxScaledBPM:
-		for bpm > MaxScaledBPM {
-			bpm /= 2
-		}
-	case bpm >= MinScaledBPM:
-		return bpm
-	case bpm < MinScaledBPM:
-		for bpm < MinScaledBPM {
-			bpm *= 2
-		}
+	if bpm == 0 {
+		return minScaledBPM
+	}
+	for bpm > maxScaledBPM {
+		bpm /= 2
+	}
+	for bpm < minScaledBPM {
+		bpm *= 2
 	}
 	return bpm
 }
-
-// func LoadChartInfo(cpath string) (info plays.ChartInfo, err error) {
-// 	c, err := LoadChart(cpath)
-// 	if err != nil {
-// 		return
-// 	}
-// 	// mode := plays.ModeDrum
-// 	main, min, max := c.BPMs()
-// 	info = plays.ChartInfo{
-// 		Path: cpath,
-// 		// Mods:       mods,
-// 		ChartHeader: c.ChartHeader,
-// 		// Mode:        mode,
-// 		// SubMode:     0,
-// 		Level:      c.Level,
-// 		Duration:   c.Duration(),
-// 		NoteCounts: c.NoteCounts(),
-// 		MainBPM:    main,
-// 		MinBPM:     min,
-// 		MaxBPM:     max,
-// 	}
-// 	return
-// }
@@ -0,0 +1,72 @@
+package drum
+
+import (
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+)
+
+// Notes and bars are drawn when they are in the range of
+// [-positionMargin, screenSizeX + positionMargin].
+const positionMargin = 100
+
+// visibleRange returns the range of positions which are visible at the cursor.
+func visibleRange(opts *Options, cursor float64) (lowermost, uppermost float64) {
+	lowermost = cursor - opts.HitPositionX - positionMargin
+	uppermost = cursor + opts.screenSizeX - opts.HitPositionX + positionMargin
+	return
+}
+
+type Components struct {
+	stage    StageComponent
+	bars     BarsComponent
+	rolls    RollsComponent
+	notes    NotesComponent
+	shake    ShakeComponent
+	judgment JudgmentComponent
+	keys     KeysComponent
+	dancer   DancerComponent
+	combo    plays.ComboComponent
+	score    plays.ScoreComponent
+}
+
+func NewComponents(res *Resources, opts *Options, c *Chart) (cmps Components) {
+	cmps.stage = NewStageComponent(res, opts)
+	cmps.bars = NewBarsComponent(res, opts, c)
+	cmps.rolls = NewRollsComponent(res, opts, c)
+	cmps.notes = NewNotesComponent(res, opts, c)
+	cmps.shake = NewShakeComponent(res, opts)
+	cmps.judgment = NewJudgmentComponent(res, opts)
+	cmps.keys = NewKeysComponent(res, opts)
+	cmps.dancer = NewDancerComponent(res, opts)
+	cmps.combo = plays.NewComboComponent(res.ComboImages, &opts.Combo)
+	cmps.score = plays.NewScoreComponent(res.ScoreImages, &opts.Score)
+	return
+}
+
+func (cmps *Components) Update(ka plays.KeyboardAction, dys plays.Dynamics, s Scorer) {
+	cursor := dys.Cursor(ka.Time)
+	d := dys.Current()
+	cmps.stage.Update(d.Highlight)
+	cmps.bars.Update(cursor)
+	cmps.rolls.Update(cursor)
+	cmps.notes.Update(ka.Time, cursor, d.BPM)
+	cmps.shake.Update(ka.Time, s)
+	cmps.judgment.Update(s.judgmentKind, s.isBig)
+	cmps.keys.Update(ka)
+	cmps.dancer.Update(ka.Time, d, s)
+	cmps.combo.Update(s.Combo)
+	cmps.score.Update(s.Score)
+}
+
+func (cmps Components) Draw(dst draws.Image) {
+	cmps.stage.Draw(dst)
+	cmps.bars.Draw(dst)
+	cmps.judgment.Draw(dst)
+	cmps.shake.Draw(dst)
+	cmps.rolls.Draw(dst)
+	cmps.notes.Draw(dst)
+	cmps.keys.Draw(dst)
+	cmps.dancer.Draw(dst)
+	cmps.combo.Draw(dst)
+	cmps.score.Draw(dst)
+}
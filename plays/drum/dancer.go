@@ -0,0 +1,60 @@
+package drum
+
+import (
+	"time"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+)
+
+// DancerComponent draws a dancer which reacts to the play.
+// The dancer dances to the beat: its animation period is synced with BPM.
+type DancerComponent struct {
+	anims     [4]draws.Animation
+	state     int
+	stateEnd  int32 // It extends when notes are continuously missed.
+	lastCombo int
+}
+
+func NewDancerComponent(res *Resources, opts *Options) (cmp DancerComponent) {
+	for i, frames := range res.DancerFramesList {
+		a := draws.NewAnimation(frames, 1000)
+		a.Scale(opts.DancerImageScale)
+		a.Locate(opts.DancerPositionX, opts.DancerPositionY, draws.CenterMiddle)
+		cmp.anims[i] = a
+	}
+	return
+}
+
+func (cmp *DancerComponent) Update(now int32, d plays.Dynamic, s Scorer) {
+	beat := 60000 / ScaledBPM(d.BPM)
+	for i := range cmp.anims {
+		cmp.anims[i].Period = time.Duration(4*beat) * time.Millisecond
+	}
+
+	state := cmp.state
+	switch {
+	case s.judgmentKind == miss:
+		state = dancerNo
+		cmp.stateEnd = now + int32(4*beat)
+	case s.Combo != cmp.lastCombo && s.Combo > 0 && s.Combo%50 == 0:
+		state = dancerYes
+		cmp.stateEnd = now + int32(beat)
+	case now >= cmp.stateEnd, cmp.state == dancerNo && s.judgmentKind <= good:
+		if d.Highlight {
+			state = dancerHigh
+		} else {
+			state = dancerIdle
+		}
+	}
+	cmp.lastCombo = s.Combo
+
+	if cmp.state != state {
+		cmp.state = state
+		cmp.anims[state].Reset()
+	}
+}
+
+func (cmp DancerComponent) Draw(dst draws.Image) {
+	cmp.anims[cmp.state].Draw(dst)
+}
@@ -0,0 +1,58 @@
+package drum
+
+import (
+	"time"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/tween"
+)
+
+type JudgmentComponent struct {
+	anims [3][2]draws.Animation
+	kind  plays.JudgmentKind
+	size  NoteSize
+	tween tween.Tween
+}
+
+func NewJudgmentComponent(res *Resources, opts *Options) (cmp JudgmentComponent) {
+	for jk, framesList := range res.JudgmentFramesList {
+		for size, frames := range framesList {
+			a := draws.NewAnimation(frames, 40)
+			a.Scale(opts.JudgmentImageScale)
+			a.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+			cmp.anims[jk][size] = a
+		}
+	}
+
+	tw := tween.Tween{MaxLoop: 1}
+	tw.Add(1.20, -0.20, 60*time.Millisecond, tween.EaseLinear)
+	tw.Add(1.00, +0.00, 190*time.Millisecond, tween.EaseLinear)
+	tw.Stop() // To make sure judgment is invisible at the beginning.
+	cmp.tween = tw
+	return
+}
+
+func (cmp *JudgmentComponent) Update(jk plays.JudgmentKind, big bool) {
+	if jk <= miss {
+		cmp.kind = jk
+		cmp.size = Regular
+		if big {
+			cmp.size = Big
+		}
+		cmp.anims[jk][cmp.size].Reset()
+		cmp.tween.Start()
+	}
+	if !cmp.tween.IsFinished() {
+		cmp.tween.Update()
+	}
+}
+
+func (cmp JudgmentComponent) Draw(dst draws.Image) {
+	if cmp.tween.IsFinished() {
+		return
+	}
+	a := cmp.anims[cmp.kind][cmp.size]
+	a.Scale(cmp.tween.Value())
+	a.Draw(dst)
+}
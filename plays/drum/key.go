@@ -0,0 +1,65 @@
+package drum
+
+import (
+	"time"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/times"
+)
+
+// KeysComponent draws a drum at the left side of the field.
+// Key sprites are overlapped at each side.
+type KeysComponent struct {
+	fieldSprite draws.Sprite
+	keysSprite  [keyCount]draws.Sprite
+	startTimes  [keyCount]time.Time
+	minDuration time.Duration
+}
+
+func NewKeysComponent(res *Resources, opts *Options) (cmp KeysComponent) {
+	var w float64 // width of a half of the drum
+	for k, img := range res.KeyImages {
+		s := draws.NewSprite(img)
+		if img.IsEmpty() {
+			continue
+		}
+		s.Scale(opts.fieldInnerHeight / s.H())
+		w = s.W()
+		cmp.keysSprite[k] = s
+	}
+	for k := range cmp.keysSprite {
+		x := 0.0
+		if k >= keyCount/2 {
+			x = w
+		}
+		cmp.keysSprite[k].Locate(x, opts.FieldPositionY, draws.LeftMiddle)
+	}
+
+	s := draws.NewSprite(res.KeyFieldImage)
+	s.SetSize(2*w, opts.FieldHeight)
+	s.Locate(0, opts.FieldPositionY, draws.LeftMiddle)
+	s.ColorScale.Scale(1, 1, 1, opts.FieldOpacity)
+	cmp.fieldSprite = s
+
+	cmp.minDuration = 75 * time.Millisecond
+	return
+}
+
+func (cmp *KeysComponent) Update(ka plays.KeyboardAction) {
+	for k, a := range ka.KeysAction {
+		if a == plays.Hit {
+			cmp.startTimes[k] = times.Now()
+		}
+	}
+}
+
+// Draw keys for a while even if the hit is brief.
+func (cmp KeysComponent) Draw(dst draws.Image) {
+	cmp.fieldSprite.Draw(dst)
+	for k, s := range cmp.keysSprite {
+		if times.Since(cmp.startTimes[k]) <= cmp.minDuration {
+			s.Draw(dst)
+		}
+	}
+}
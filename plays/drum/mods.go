@@ -0,0 +1,18 @@
+package drum
+
+import "github.com/hndada/gosu/plays"
+
+type Mods struct {
+}
+
+// Drum has 2 + 1 Judgments: Cool, Good and Miss.
+// Todo: find the best value for dotHitWindow.
+func (Mods) DefaultJudgments() []plays.Judgment {
+	return []plays.Judgment{
+		{Window: 25, Weight: 1},
+		{Window: 60, Weight: 0.5},
+		{Window: 100, Weight: 0},
+	}
+}
+
+const dotHitWindow = 35
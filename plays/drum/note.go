@@ -1,137 +1,263 @@
 package drum
 
 import (
-	"math"
 	"sort"
+	"time"
 
+	"github.com/hndada/gosu/draws"
 	"github.com/hndada/gosu/format/osu"
-	"github.com/hndada/gosu/mode"
+	"github.com/hndada/gosu/plays"
 )
 
-// Drum note has 3 components: Color, Size, Type(Note, Roll, Shake).
-// Piano note has 2 components: Key, Type(Note, Head, Tail).
+// Drum note has 3 components: Kind, Color and Size.
+// Piano note has 2 components: Kind and Key.
+type NoteKind int
+
 const (
-	Normal = iota
+	Normal NoteKind = iota
 	Roll
 	Shake
 )
+
+type NoteColor int
+
 const (
-	ColorNone = iota - 1
-	Red       // aka Don.
-	Blue      // aka Kat.
-	Yellow    // For Roll.
-	Purple    // For Shake.
+	Red    NoteColor = iota // aka Don.
+	Blue                    // aka Kat.
+	Yellow                  // For Roll.
+	Purple                  // For Shake.
 )
+
+type NoteSize int
+
 const (
-	SizeNone = iota - 1
-	Regular
+	Regular NoteSize = iota
 	Big
 )
 
+const (
+	dotDensity   = 4 // The number of dots per beat in Roll.
+	shakeDensity = 3 // The number of shakes per beat required in Shake.
+)
+
 type Note struct {
-	Floater
-	Type     int
-	Color    int
-	Size     int
-	Duration int64
-	length   float64 // For compatibility with osu!.
-	Tick     int     // The number of ticks in Roll or Shake.
-	mode.Sample
-
-	scored  bool
-	HitTick int // The number of ticks being hit.
-	Next    *Note
-	Prev    *Note
+	Time     int32
+	Kind     NoteKind
+	Color    NoteColor
+	Size     NoteSize
+	Duration int32
+	Tick     int // The number of ticks in Roll or Shake.
+	Sample   plays.Sample
+
+	position     float64
+	tailPosition float64 // For drawing Roll's body.
+	scored       bool
+	hitTick      int // The number of ticks being hit in Shake.
 }
 
-func NewNote(f any) (n *Note) {
-	switch f := f.(type) {
-	case osu.HitObject:
-		n = &Note{
-			Sample: mode.NewSample(f),
-		}
-		n.Time = int64(f.Time)
-		switch {
-		case f.NoteType&osu.HitTypeSlider != 0:
-			n.Type = Roll
-			n.length = f.SliderLength() // For calculating duration of Roll.
-			n.Color = Yellow
-		case f.NoteType&osu.HitTypeSpinner != 0:
-			n.Type = Shake
-			n.Duration = int64(f.EndTime) - n.Time
-			n.Color = Purple
-		default:
-			n.Type = Normal
-			if osu.IsDon(f) {
-				n.Color = Red
-			} else {
-				n.Color = Blue
-			}
-		}
-		if osu.IsBig(f) {
-			n.Size = Big
+func newNoteFromOsu(f osu.HitObject, d plays.Dynamic, mainBPM, multiplier float64) Note {
+	n := Note{
+		Time:   int32(f.Time),
+		Sample: plays.NewSample(f),
+	}
+	switch {
+	case f.NoteType&osu.HitTypeSlider != 0:
+		n.Kind = Roll
+		n.Color = Yellow
+		// Speed of Dynamic is BPM ratio multiplied by beat scale.
+		beatScale := d.Speed * mainBPM / d.BPM
+		speed := (d.BPM / 60000) * beatScale * (multiplier * 100)
+		n.Duration = int32(f.SliderDuration(speed))
+		n.Tick = tickCount(n.Duration, d.BPM, dotDensity)
+	case f.NoteType&osu.HitTypeSpinner != 0:
+		n.Kind = Shake
+		n.Color = Purple
+		n.Duration = int32(f.EndTime) - n.Time
+		n.Tick = tickCount(n.Duration, d.BPM, shakeDensity)
+	default:
+		n.Kind = Normal
+		if f.IsDon() {
+			n.Color = Red
 		} else {
-			n.Size = Regular
+			n.Color = Blue
 		}
 	}
-	return
+	if f.IsBig() {
+		n.Size = Big
+	}
+	return n
 }
 
-func NewNotes(f any) (notes, rolls, shakes []*Note) {
-	switch f := f.(type) {
-	case *osu.Format:
-		notes = make([]*Note, 0, len(f.HitObjects)*2)
-		for _, ho := range f.HitObjects {
-			n := NewNote(ho)
-			switch n.Type {
-			case Normal:
-				notes = append(notes, n)
-			case Roll:
-				rolls = append(rolls, n)
-			case Shake:
-				shakes = append(shakes, n)
-			}
-		}
-	}
-	// Sort notes only with their time.
-	// Order of notes at the same time might be intentional for gimmicks.
-	sort.SliceStable(notes, func(i, j int) bool {
-		return notes[i].Time < notes[j].Time
-	})
-	sort.SliceStable(rolls, func(i, j int) bool {
-		return rolls[i].Time < rolls[j].Time
-	})
-	sort.SliceStable(shakes, func(i, j int) bool {
-		return shakes[i].Time < shakes[j].Time
-	})
-	prevs := make([]*Note, 3)
-	for kind, ns := range [][]*Note{notes, rolls, shakes} {
-		for _, n := range ns {
-			prev := prevs[kind]
-			n.Prev = prev
-			if prev != nil {
-				prev.Next = n
-			}
-			prevs[kind] = n
+func tickCount(duration int32, bpm, density float64) int {
+	beats := float64(duration) * ScaledBPM(bpm) / 60000
+	return int(beats*density+0.1) + 1
+}
+
+// Sort notes only with their time.
+// Order of notes at the same time might be intentional for gimmicks.
+func newNotesFromOsu(f *osu.Format, dys plays.Dynamics) (notes, rolls, shakes []Note) {
+	mainBPM := dys.Dynamics()[0].BPM
+	multiplier := f.SliderMultiplier
+
+	hos := make([]osu.HitObject, len(f.HitObjects))
+	copy(hos, f.HitObjects)
+	sort.SliceStable(hos, func(i, j int) bool { return hos[i].Time < hos[j].Time })
+
+	dys.Reset()
+	for _, ho := range hos {
+		d := dys.UpdateIndex(int32(ho.Time))
+		ho.HitSample = f.ResolveHitSample(ho)
+		n := newNoteFromOsu(ho, d, mainBPM, multiplier)
+		switch n.Kind {
+		case Normal:
+			notes = append(notes, n)
+		case Roll:
+			rolls = append(rolls, n)
+		case Shake:
+			shakes = append(shakes, n)
 		}
 	}
+	dys.Reset()
 	return
 }
 
+// Weight is a worth of the note in score and difficulty.
+// Shake is apparently easier than Roll, since it is free from beat.
 func (n Note) Weight() float64 {
-	switch n.Type {
+	switch n.Kind {
 	case Normal:
-		switch n.Size {
-		case Regular:
-			return 1.0
-		case Big:
-			return 1.0 // 1.1
-		}
+		return 1
 	case Shake:
-		// Shake is apparently easier than Roll, since it is free from beat.
-		// https://www.desmos.com/calculator/nsogcrebx9
-		return math.Pow(float64(32*n.Tick), 0.75) / 32 // 32 comes from 8 * 4.
+		return float64(n.Tick) * shakeTickWeight
 	}
 	return 0
 }
-func (d Dot) Weight() float64 { return 0.125 } // Assumes 8 ticks worth one normal note.
+
+// Assumes 8 ticks worth one Normal note.
+const (
+	dotWeight       = 0.125
+	shakeTickWeight = 0.1
+)
+
+const (
+	dotReady = iota
+	dotHit
+	dotMiss
+)
+
+// No consider situations that multiple rolls are overlapped.
+type Dot struct {
+	Time     int32
+	position float64
+	scored   int
+}
+
+func NewDots(rolls []Note) []Dot {
+	var ds []Dot
+	for _, n := range rolls {
+		var step float64
+		if n.Tick >= 2 {
+			step = float64(n.Duration) / float64(n.Tick-1)
+		}
+		for tick := 0; tick < n.Tick; tick++ {
+			t := n.Time + int32(step*float64(tick))
+			ds = append(ds, Dot{Time: t})
+		}
+	}
+	return ds
+}
+
+// NotesComponent draws Normal notes, heads of Rolls and Shakes.
+// Shakes go first, then Rolls, then Normal notes so that
+// Normal notes are drawn on the top.
+type NotesComponent struct {
+	notesList    [3][]Note
+	lowests      [3]int
+	colorsSprite [4][2]draws.Sprite
+	overlayAnims [2]draws.Animation
+	opts         *Options
+	now          int32
+	cursor       float64
+}
+
+func NewNotesComponent(res *Resources, opts *Options, c *Chart) (cmp NotesComponent) {
+	cmp.notesList = [3][]Note{c.Shakes, c.Rolls, c.Notes}
+	for color, clr := range opts.NoteColors {
+		for size := range cmp.colorsSprite[color] {
+			s := draws.NewSprite(res.NoteImage)
+			h := opts.noteHeight(NoteSize(size))
+			s.SetSize(h, h)
+			s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+			s.ColorScale.ScaleWithColor(clr)
+			cmp.colorsSprite[color][size] = s
+		}
+	}
+	for size, frames := range res.OverlayFramesList {
+		a := draws.NewAnimation(frames, 400)
+		h := opts.noteHeight(NoteSize(size))
+		a.SetSize(h, h)
+		a.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+		cmp.overlayAnims[size] = a
+	}
+	cmp.opts = opts
+	return
+}
+
+// Overlay animation is synced with the beat.
+func (cmp *NotesComponent) Update(now int32, cursor, bpm float64) {
+	cmp.now = now
+	cmp.cursor = cursor
+	period := 2 * 60000 / ScaledBPM(bpm)
+	for size := range cmp.overlayAnims {
+		cmp.overlayAnims[size].Period = time.Duration(period) * time.Millisecond
+	}
+
+	lowermost, _ := visibleRange(cmp.opts, cursor)
+	for i, ns := range cmp.notesList {
+		for ni := cmp.lowests[i]; ni < len(ns); ni++ {
+			if ns[ni].tailPosition > lowermost {
+				break
+			}
+			cmp.lowests[i] = ni
+		}
+	}
+}
+
+func (cmp NotesComponent) Draw(dst draws.Image) {
+	_, uppermost := visibleRange(cmp.opts, cmp.cursor)
+	for i, ns := range cmp.notesList {
+		var nis []int
+		for ni := cmp.lowests[i]; ni < len(ns); ni++ {
+			if ns[ni].position > uppermost {
+				break
+			}
+			nis = append(nis, ni)
+		}
+
+		// Make farther notes overlapped by nearer notes.
+		sort.Sort(sort.Reverse(sort.IntSlice(nis)))
+
+		for _, ni := range nis {
+			n := ns[ni]
+			switch n.Kind {
+			case Normal:
+				if n.scored {
+					continue
+				}
+			case Shake:
+				// Shake stays at the hit position; ShakeComponent draws it.
+				if n.Time <= cmp.now {
+					continue
+				}
+			}
+			x := n.position - cmp.cursor
+			s := cmp.colorsSprite[n.Color][n.Size]
+			s.Move(x, 0)
+			s.Draw(dst)
+
+			a := cmp.overlayAnims[n.Size]
+			a.Move(x, 0)
+			a.Draw(dst)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package drum
+
+import (
+	"image/color"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// Drum stage is horizontal: notes flow from right to left.
+type Options struct {
+	screenSizeX float64
+	screenSizeY float64
+	SpeedScale  float64
+
+	// Keys are arranged as: Blue, Red, Red, Blue.
+	KeyMappings []string
+
+	FieldOpacity    float32
+	FieldPositionY  float64
+	FieldHeight     float64
+	FieldInnerScale float64 // Ratio of inner field, where keys are drawn.
+	HitPositionX    float64
+
+	bigNoteHeight     float64 // derived
+	regularNoteHeight float64 // derived
+	fieldInnerHeight  float64 // derived
+
+	NoteColors         [4]color.NRGBA // Red, Blue, Yellow, Purple
+	DotImageScale      float64
+	ShakeImageScale    float64
+	JudgmentImageScale float64
+	DancerImageScale   float64
+	DancerPositionX    float64
+	DancerPositionY    float64
+	Combo              plays.ComboOptions
+	Score              plays.ScoreOptions
+}
+
+func NewOptions() *Options {
+	opts := &Options{
+		SpeedScale:  1.0,
+		KeyMappings: []string{"D", "F", "J", "K"},
+
+		FieldOpacity:    0.7,
+		FieldPositionY:  0.4115 * plays.ScreenSizeY,
+		FieldHeight:     0.26 * plays.ScreenSizeY,
+		FieldInnerScale: 0.95,
+		HitPositionX:    0.1875 * plays.ScreenSizeX,
+
+		NoteColors: [4]color.NRGBA{
+			{235, 69, 44, 255},   // Red
+			{68, 141, 171, 255},  // Blue
+			{230, 170, 0, 255},   // Yellow
+			{150, 100, 200, 255}, // Purple
+		},
+		DotImageScale:      0.5,
+		ShakeImageScale:    4,
+		JudgmentImageScale: 0.75,
+		DancerImageScale:   0.75,
+		DancerPositionX:    0.1 * plays.ScreenSizeX,
+		DancerPositionY:    0.175 * plays.ScreenSizeY,
+		Combo: plays.ComboOptions{
+			ImageScale: 0.75,
+			// Combo is drawn at the center of key field.
+			PositionX: 0.0625 * plays.ScreenSizeX,
+			DigitGap:  -1,
+			PositionY: 0.4115 * plays.ScreenSizeY,
+			IsPersist: true,
+			Bounce:    0.08,
+		},
+		Score: plays.ScoreOptions{
+			ImageScale: 0.65,
+			DigitGap:   0,
+		},
+	}
+
+	opts.SetDerived()
+	return opts
+}
+
+func (opts *Options) SetDerived() {
+	opts.screenSizeX = plays.ScreenSizeX
+	opts.screenSizeY = plays.ScreenSizeY
+
+	opts.bigNoteHeight = opts.FieldHeight * 0.725
+	opts.regularNoteHeight = opts.bigNoteHeight * 0.65
+	opts.fieldInnerHeight = opts.FieldHeight * opts.FieldInnerScale
+}
+
+func (opts Options) noteHeight(size NoteSize) float64 {
+	if size == Big {
+		return opts.bigNoteHeight
+	}
+	return opts.regularNoteHeight
+}
@@ -0,0 +1,171 @@
+package drum
+
+import (
+	"fmt"
+	"image/color"
+	"io/fs"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+)
+
+// Stage state. Field and hint look different at highlight (kiai) time.
+const (
+	idle = iota
+	high
+)
+
+// Dancer state.
+const (
+	dancerIdle = iota
+	dancerYes
+	dancerNo
+	dancerHigh
+)
+
+// [2] at last dimension stands for a size in most cases.
+type Resources struct {
+	FieldImages        [2]draws.Image // idle, high
+	HintImages         [2]draws.Image // idle, high
+	BarImage           draws.Image    // generated
+	NoteImage          draws.Image
+	RollHeadImage      draws.Image // generated by flipping RollTailImage
+	RollTailImage      draws.Image
+	RollBodyImage      draws.Image
+	DotImage           draws.Image
+	OverlayFramesList  [2]draws.Frames
+	KeyImages          [keyCount]draws.Image // generated by flipping in and out
+	KeyFieldImage      draws.Image           // generated
+	JudgmentFramesList [3][2]draws.Frames
+	DancerFramesList   [4]draws.Frames
+	DrumSounds         [2][2][]byte // color, size
+	ComboImages        []draws.Image
+	ScoreImages        []draws.Image
+}
+
+// newImageXFlipped returns a horizontally flipped image.
+func newImageXFlipped(src draws.Image) draws.Image {
+	if src.IsEmpty() {
+		return src
+	}
+	w, h := src.Size().Values()
+	dst := draws.CreateImage(w, h)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(-1, 1)
+	op.GeoM.Translate(w, 0)
+	dst.DrawImage(src.Image, op)
+	return dst
+}
+
+func loadStageImages(fsys fs.FS, kind string) [2]draws.Image {
+	var imgs [2]draws.Image
+	for i, name := range []string{"idle", "high"} {
+		fname := fmt.Sprintf("drum/stage/%s-%s.png", kind, name)
+		imgs[i] = draws.NewImageFromFile(fsys, fname)
+	}
+	// Use idle one when there is no high one.
+	if imgs[high].IsEmpty() {
+		imgs[high] = imgs[idle]
+	}
+	return imgs
+}
+
+func loadBarImage() draws.Image {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.White)
+	return img
+}
+
+func loadNoteImage(fsys fs.FS, name string) draws.Image {
+	fname := fmt.Sprintf("drum/note/%s.png", name)
+	return draws.NewImageFromFile(fsys, fname)
+}
+
+func loadOverlayFramesList(fsys fs.FS) [2]draws.Frames {
+	var framesList [2]draws.Frames
+	for size, name := range []string{"overlay", "overlay-big"} {
+		fname := fmt.Sprintf("drum/note/%s.png", name)
+		framesList[size] = draws.NewFramesFromFile(fsys, fname)
+	}
+	return framesList
+}
+
+// Key images are overlapped at each side.
+func loadKeyImages(fsys fs.FS) [keyCount]draws.Image {
+	in := draws.NewImageFromFile(fsys, "drum/key/in.png")
+	out := draws.NewImageFromFile(fsys, "drum/key/out.png")
+	return [keyCount]draws.Image{
+		newImageXFlipped(out),
+		in,
+		newImageXFlipped(in),
+		out,
+	}
+}
+
+func loadKeyFieldImage() draws.Image {
+	img := draws.CreateImage(1, 1)
+	img.Fill(color.Black)
+	return img
+}
+
+func loadJudgmentFramesList(fsys fs.FS) [3][2]draws.Frames {
+	var framesList [3][2]draws.Frames
+	for jk, name := range []string{"cool", "good", "miss"} {
+		for size, suffix := range []string{"", "-big"} {
+			// There is no big version of miss.
+			if name == "miss" {
+				suffix = ""
+			}
+			fname := fmt.Sprintf("drum/judgment/%s%s.png", name, suffix)
+			framesList[jk][size] = draws.NewFramesFromFile(fsys, fname)
+		}
+	}
+	return framesList
+}
+
+func loadDancerFramesList(fsys fs.FS) [4]draws.Frames {
+	var framesList [4]draws.Frames
+	for i, name := range []string{"idle", "yes", "no", "high"} {
+		fname := fmt.Sprintf("drum/dancer/%s.png", name)
+		framesList[i] = draws.NewFramesFromFile(fsys, fname)
+	}
+	return framesList
+}
+
+func loadDrumSounds(fsys fs.FS) [2][2][]byte {
+	var sounds [2][2][]byte
+	for c, cname := range []string{"red", "blue"} {
+		for size, suffix := range []string{"", "-big"} {
+			fname := drumSoundFilename(cname, suffix)
+			sounds[c][size], _ = fs.ReadFile(fsys, fname)
+		}
+	}
+	return sounds
+}
+
+func drumSoundFilename(cname, suffix string) string {
+	return fmt.Sprintf("drum/sound/%s%s.wav", cname, suffix)
+}
+
+func NewResources(fsys fs.FS) *Resources {
+	tail := loadNoteImage(fsys, "end")
+	return &Resources{
+		FieldImages:        loadStageImages(fsys, "field"),
+		HintImages:         loadStageImages(fsys, "hint"),
+		BarImage:           loadBarImage(),
+		NoteImage:          loadNoteImage(fsys, "note"),
+		RollHeadImage:      newImageXFlipped(tail),
+		RollTailImage:      tail,
+		RollBodyImage:      loadNoteImage(fsys, "mid"),
+		DotImage:           loadNoteImage(fsys, "dot"),
+		OverlayFramesList:  loadOverlayFramesList(fsys),
+		KeyImages:          loadKeyImages(fsys),
+		KeyFieldImage:      loadKeyFieldImage(),
+		JudgmentFramesList: loadJudgmentFramesList(fsys),
+		DancerFramesList:   loadDancerFramesList(fsys),
+		DrumSounds:         loadDrumSounds(fsys),
+		ComboImages:        plays.LoadComboImages(fsys),
+		ScoreImages:        plays.LoadScoreImages(fsys),
+	}
+}
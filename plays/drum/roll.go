@@ -0,0 +1,131 @@
+package drum
+
+import (
+	"image/color"
+
+	"github.com/hndada/gosu/draws"
+)
+
+// RollsComponent draws bodies of Rolls and their dots.
+// Heads of Rolls are drawn by NotesComponent.
+type RollsComponent struct {
+	rolls       []Note
+	dots        []Dot
+	lowest      int
+	lowestDot   int
+	headSprites [2]draws.Sprite
+	tailSprites [2]draws.Sprite
+	bodySprites [2]draws.Sprite
+	dotSprite   draws.Sprite
+	opts        *Options
+	cursor      float64
+}
+
+func NewRollsComponent(res *Resources, opts *Options, c *Chart) (cmp RollsComponent) {
+	clr := opts.NoteColors[Yellow]
+	for size := range cmp.bodySprites {
+		h := opts.noteHeight(NoteSize(size))
+		x, y := opts.HitPositionX, opts.FieldPositionY
+
+		head := draws.NewSprite(res.RollHeadImage)
+		head.SetSize(h/2, h)
+		head.Locate(x, y, draws.RightMiddle)
+		head.ColorScale.ScaleWithColor(clr)
+		cmp.headSprites[size] = head
+
+		tail := draws.NewSprite(res.RollTailImage)
+		tail.SetSize(h/2, h)
+		tail.Locate(x, y, draws.LeftMiddle)
+		tail.ColorScale.ScaleWithColor(clr)
+		cmp.tailSprites[size] = tail
+
+		body := draws.NewSprite(res.RollBodyImage)
+		body.SetSize(1, h)
+		body.Locate(x, y, draws.LeftMiddle)
+		body.ColorScale.ScaleWithColor(clr)
+		cmp.bodySprites[size] = body
+	}
+
+	s := draws.NewSprite(res.DotImage)
+	s.Scale(opts.DotImageScale)
+	s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+	cmp.dotSprite = s
+
+	cmp.rolls = c.Rolls
+	cmp.dots = c.Dots
+	cmp.opts = opts
+	return
+}
+
+func (cmp *RollsComponent) Update(cursor float64) {
+	cmp.cursor = cursor
+	lowermost, _ := visibleRange(cmp.opts, cursor)
+	for i := cmp.lowest; i < len(cmp.rolls); i++ {
+		if cmp.rolls[i].tailPosition > lowermost {
+			break
+		}
+		cmp.lowest = i
+	}
+	for i := cmp.lowestDot; i < len(cmp.dots); i++ {
+		if cmp.dots[i].position > lowermost {
+			break
+		}
+		cmp.lowestDot = i
+	}
+}
+
+// Farther Rolls are drawn first.
+func (cmp RollsComponent) Draw(dst draws.Image) {
+	_, uppermost := visibleRange(cmp.opts, cmp.cursor)
+	last := cmp.lowest
+	for last < len(cmp.rolls) && cmp.rolls[last].position <= uppermost {
+		last++
+	}
+	for i := last - 1; i >= cmp.lowest; i-- {
+		cmp.drawRoll(dst, cmp.rolls[i])
+	}
+
+	last = cmp.lowestDot
+	for last < len(cmp.dots) && cmp.dots[last].position <= uppermost {
+		last++
+	}
+	for i := last - 1; i >= cmp.lowestDot; i-- {
+		cmp.drawDot(dst, cmp.dots[i])
+	}
+}
+
+func (cmp RollsComponent) drawRoll(dst draws.Image, n Note) {
+	headX := n.position - cmp.cursor
+	tailX := n.tailPosition - cmp.cursor
+
+	body := cmp.bodySprites[n.Size]
+	length := tailX - headX
+	if length < 0 {
+		length = 0
+	}
+	body.SetSize(length, body.H())
+	body.Move(headX, 0)
+	body.Draw(dst)
+
+	head := cmp.headSprites[n.Size]
+	head.Move(headX, 0)
+	head.Draw(dst)
+
+	tail := cmp.tailSprites[n.Size]
+	tail.Move(tailX, 0)
+	tail.Draw(dst)
+}
+
+// Hit dots disappear, and missed dots go red and larger.
+func (cmp RollsComponent) drawDot(dst draws.Image, d Dot) {
+	s := cmp.dotSprite
+	switch d.scored {
+	case dotHit:
+		return
+	case dotMiss:
+		s.ColorScale.ScaleWithColor(color.NRGBA{255, 0, 0, 255})
+		s.Scale(1.5)
+	}
+	s.Move(d.position-cmp.cursor, 0)
+	s.Draw(dst)
+}
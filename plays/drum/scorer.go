@@ -0,0 +1,352 @@
+package drum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// There are three kinds of factors: Flow, Acc, and Extra.
+// Flow drops to zero when Miss, and recovers when Cool and Good.
+// Acc is simply the weight of the judgment.
+// Extra comes from Rolls and Shakes, which are made of ticks.
+const (
+	flow = iota
+	acc
+	extra
+)
+
+const (
+	cool plays.JudgmentKind = iota
+	good
+	miss
+	blank
+)
+
+const (
+	tickHit = iota
+	tickDrop
+)
+
+// Keys are arranged as: Blue, Red, Red, Blue.
+// Outer keys are for Blue, and inner keys are for Red.
+var colorsKeys = [2][2]int{{1, 2}, {0, 3}}
+
+const (
+	colorNone NoteColor = -1
+	sizeNone  NoteSize  = -1
+)
+
+// A Big note requires both keys of its color to be hit within maxBigHitDuration.
+// When a Big note is hit with only one key, the note gives half the accuracy.
+// Flow is not affected by hitting Big note with one key.
+// In other word, to get Cool at Big note, you have to hit it Cool with both sides.
+const maxBigHitDuration = 25
+
+type Scorer struct {
+	notes  []Note
+	dots   []Dot
+	shakes []Note
+	plays.Judgments
+	PartialCounts []int // Counts of Big notes hit with one key.
+	TickCounts    [2]int
+
+	noteFocus  int
+	dotFocus   int
+	shakeFocus int
+
+	keysLastHitTime [keyCount]int32
+	colorsHitSize   [2]NoteSize
+	stagedKind      plays.JudgmentKind // Big note waiting for the other key.
+	stagedTime      int32
+	shakeWaitColor  NoteColor
+
+	// Following fields are for components.
+	judgmentKind plays.JudgmentKind
+	isBig        bool
+	isShakeClear bool
+
+	Combo      int
+	units      [3]float64
+	flowFactor float64
+	Score      float64
+}
+
+const maxFlowFactor = 50
+
+// If a chart has not enough Shake and Roll, Extra score shrinks,
+// and the margin goes to Flow and Acc.
+func NewScorer(c *Chart, mods Mods) (s Scorer) {
+	s.notes = c.Notes
+	s.dots = c.Dots
+	s.shakes = c.Shakes
+	js := mods.DefaultJudgments()
+	s.Judgments = plays.NewJudgments(js)
+	s.PartialCounts = make([]int, len(js))
+
+	for k := range s.keysLastHitTime {
+		s.keysLastHitTime[k] = -1 << 30
+	}
+	s.stagedKind = blank
+	s.shakeWaitColor = colorNone
+
+	noteCount := len(s.notes)
+	tickCount := len(s.dots)
+	for _, n := range s.shakes {
+		tickCount += n.Tick
+	}
+
+	var extraRatio float64
+	if tickCount > 0 {
+		extraRatio = 0.1
+		if noteCount == 0 {
+			extraRatio = 1
+		}
+	}
+	if noteCount > 0 {
+		unit := 1e6 * (1 - extraRatio) / float64(noteCount)
+		s.units[flow] = unit * 0.7
+		s.units[acc] = unit * 0.3
+	}
+	if tickCount > 0 {
+		s.units[extra] = 1e6 * extraRatio / float64(tickCount)
+	}
+	s.flowFactor = maxFlowFactor
+
+	// Accumulating floating-point numbers may result in imprecise values.
+	// To ensure that the maximum score is attainable,
+	// we initialize the score with a small value in advance.
+	s.Score = 0.01
+	return
+}
+
+func (s *Scorer) update(ka plays.KeyboardAction) {
+	s.judgmentKind = blank
+	s.isBig = false
+	s.isShakeClear = false
+
+	s.setColorsHitSize(ka)
+	s.flushStaged(ka.Time)
+	s.updateNotes(ka.Time)
+	s.updateDots(ka.Time)
+	s.updateShakes(ka.Time)
+}
+
+func (s *Scorer) setColorsHitSize(ka plays.KeyboardAction) {
+	for k, a := range ka.KeysAction {
+		if a == plays.Hit {
+			s.keysLastHitTime[k] = ka.Time
+		}
+	}
+	for c, keys := range colorsKeys {
+		s.colorsHitSize[c] = sizeNone
+		k0, k1 := keys[0], keys[1]
+		if ka.KeysAction[k0] != plays.Hit && ka.KeysAction[k1] != plays.Hit {
+			continue
+		}
+		if ka.Time-s.keysLastHitTime[k0] < maxBigHitDuration &&
+			ka.Time-s.keysLastHitTime[k1] < maxBigHitDuration {
+			s.colorsHitSize[c] = Big
+		} else {
+			s.colorsHitSize[c] = Regular
+		}
+	}
+}
+
+func (s Scorer) isColorHit(c NoteColor) bool {
+	if c != Red && c != Blue {
+		return false
+	}
+	return s.colorsHitSize[c] != sizeNone
+}
+
+func (s Scorer) isOtherColorHit(c NoteColor) bool {
+	switch c {
+	case Red:
+		return s.isColorHit(Blue)
+	case Blue:
+		return s.isColorHit(Red)
+	}
+	return false
+}
+
+func (s Scorer) isAnyColorHit() bool { return s.isColorHit(Red) || s.isColorHit(Blue) }
+
+// A hit of two keys should not be used for another Big note.
+func (s *Scorer) resetKeysLastHitTime(c NoteColor) {
+	for _, k := range colorsKeys[c] {
+		s.keysLastHitTime[k] = -1 << 30
+	}
+}
+
+// flushStaged marks the staged Big note as hit with one key.
+func (s *Scorer) flushStaged(now int32) {
+	if s.stagedKind == blank {
+		return
+	}
+	n := s.notes[s.noteFocus]
+	if s.isOtherColorHit(n.Color) ||
+		now-s.stagedTime > maxBigHitDuration ||
+		s.IsTooLate(n.Time-now) {
+		s.resetKeysLastHitTime(n.Color)
+		s.markNote(s.stagedKind, false)
+	}
+}
+
+func (s *Scorer) updateNotes(now int32) {
+	for s.noteFocus < len(s.notes) {
+		n := s.notes[s.noteFocus]
+		if e := n.Time - now; s.IsTooLate(e) {
+			s.markNote(miss, false)
+		} else {
+			break
+		}
+	}
+	if s.noteFocus >= len(s.notes) {
+		return
+	}
+
+	n := s.notes[s.noteFocus]
+	e := n.Time - now
+	if s.IsTooEarly(e) {
+		return
+	}
+	if s.isOtherColorHit(n.Color) {
+		s.markNote(miss, false)
+		return
+	}
+	if !s.isColorHit(n.Color) {
+		return
+	}
+
+	jk := s.Evaluate(e)
+	if n.Size == Big && s.colorsHitSize[n.Color] != Big {
+		// Wait for the other key.
+		if s.stagedKind == blank {
+			s.stagedKind = jk
+			s.stagedTime = now
+		}
+		return
+	}
+	if n.Size == Big {
+		s.resetKeysLastHitTime(n.Color)
+	}
+	s.markNote(jk, n.Size == Big)
+}
+
+func (s *Scorer) markNote(jk plays.JudgmentKind, big bool) {
+	n := &s.notes[s.noteFocus]
+	j := s.Judgments.Judgments[jk]
+
+	weight := j.Weight
+	isPartial := n.Size == Big && !big && jk != miss
+	if isPartial {
+		weight /= 2
+		s.PartialCounts[jk]++
+	}
+
+	if jk == miss {
+		s.Combo = 0
+		s.flowFactor = 0
+	} else {
+		s.Combo++
+		s.flowFactor = min(s.flowFactor+1, maxFlowFactor)
+		s.Score += s.units[flow] * s.flowFactor / maxFlowFactor
+	}
+	s.Score += s.units[acc] * weight
+
+	n.scored = true
+	s.Judgments.Counts[jk]++
+	s.noteFocus++
+	s.stagedKind = blank
+
+	s.judgmentKind = jk
+	s.isBig = big
+}
+
+// Rolls affect only Extra score.
+// A hit can score only one dot.
+func (s *Scorer) updateDots(now int32) {
+	for s.dotFocus < len(s.dots) {
+		d := &s.dots[s.dotFocus]
+		e := d.Time - now
+		switch {
+		case e < -dotHitWindow:
+			d.scored = dotMiss
+			s.TickCounts[tickDrop]++
+			s.dotFocus++
+		case e < dotHitWindow && s.isAnyColorHit():
+			d.scored = dotHit
+			s.TickCounts[tickHit]++
+			s.Score += s.units[extra]
+			s.dotFocus++
+			return
+		default:
+			return
+		}
+	}
+}
+
+// Shakes affect only Extra score.
+// Red and Blue should be hit alternately.
+func (s *Scorer) updateShakes(now int32) {
+	if s.shakeFocus >= len(s.shakes) {
+		return
+	}
+	n := &s.shakes[s.shakeFocus]
+	if now < n.Time {
+		return
+	}
+	if now > n.Time+n.Duration {
+		s.TickCounts[tickDrop] += n.Tick - n.hitTick
+		s.flushShake()
+		return
+	}
+
+	for _, c := range []NoteColor{Red, Blue} {
+		if !s.isColorHit(c) {
+			continue
+		}
+		if s.shakeWaitColor != colorNone && s.shakeWaitColor != c {
+			continue
+		}
+		n.hitTick++
+		s.TickCounts[tickHit]++
+		s.Score += s.units[extra]
+		s.shakeWaitColor = []NoteColor{Blue, Red}[c]
+		break
+	}
+	if n.hitTick >= n.Tick {
+		s.isShakeClear = true
+		s.flushShake()
+	}
+}
+
+func (s *Scorer) flushShake() {
+	s.shakes[s.shakeFocus].scored = true
+	s.shakeFocus++
+	s.shakeWaitColor = colorNone
+}
+
+// stagedShake returns the Shake which is being played or to be played.
+func (s Scorer) stagedShake() (Note, bool) {
+	if s.shakeFocus >= len(s.shakes) {
+		return Note{}, false
+	}
+	return s.shakes[s.shakeFocus], true
+}
+
+func (s Scorer) DebugString() string {
+	var b strings.Builder
+	f := fmt.Fprintf
+
+	f(&b, "Score: %.0f \n", s.Score)
+	f(&b, "Combo: %d\n", s.Combo)
+	f(&b, "Flow: %.0f/%.0f\n", s.flowFactor, float64(maxFlowFactor))
+	f(&b, "Judgment counts: %v\n", s.Judgments.Counts)
+	f(&b, "Partial counts: %v\n", s.PartialCounts)
+	f(&b, "Tick counts: %v\n", s.TickCounts)
+	f(&b, "\n")
+	return b.String()
+}
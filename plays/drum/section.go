@@ -0,0 +1,69 @@
+package drum
+
+import (
+	"sort"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// Big note requires a bit more strain than Regular one.
+const bigStrain = 0.1
+
+// Difficulties returns strain of each section.
+// Shake gives uniform strain over its duration.
+// Mods may change the duration of chart.
+func (c Chart) Difficulties() []float64 {
+	ds := make([]float64, plays.SectionCount(c.TotalDuration()))
+	index := func(t int32) int { return min(plays.SectionIndex(t), len(ds)-1) }
+
+	for _, n := range c.Notes {
+		strain := n.Weight()
+		if n.Size == Big {
+			strain += bigStrain
+		}
+		ds[index(n.Time)] += strain
+	}
+	for _, d := range c.Dots {
+		ds[index(d.Time)] += dotWeight
+	}
+	for _, n := range c.Shakes {
+		first, last := index(n.Time), index(n.Time+n.Duration)
+		strain := n.Weight() / float64(last-first+1)
+		for i := first; i <= last; i++ {
+			ds[i] += strain
+		}
+	}
+	return ds
+}
+
+// hitTimes returns times of Normal notes and dots.
+func (c Chart) hitTimes() []int32 {
+	ts := make([]int32, 0, len(c.Notes)+len(c.Dots))
+	for _, n := range c.Notes {
+		ts = append(ts, n.Time)
+	}
+	for _, d := range c.Dots {
+		ts = append(ts, d.Time)
+	}
+	return ts
+}
+
+// Densities returns notes per second of each section.
+func (c Chart) Densities() []float64 {
+	return plays.Densities(c.hitTimes(), c.TotalDuration())
+}
+
+func (c Chart) PeakNPS() float64 { return plays.Peak(c.Densities()) }
+
+func (c Chart) DrainTime() int32 {
+	var ts []int32
+	for _, ns := range [][]Note{c.Notes, c.Rolls, c.Shakes} {
+		for _, n := range ns {
+			ts = append(ts, n.Time, n.Time+n.Duration)
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return plays.DrainTime(ts)
+}
+
+func (c Chart) Level() float64 { return plays.Level(c.Difficulties()) }
@@ -0,0 +1,80 @@
+package drum
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/tween"
+)
+
+// ShakeComponent draws a Shake being played at the hit position.
+// Inner circle grows as the Shake is hit, and the outer circle
+// fades out with enlarging when the Shake is cleared.
+type ShakeComponent struct {
+	outerSprite draws.Sprite
+	innerSprite draws.Sprite
+	staged      Note
+	isStaged    bool
+	now         int32
+	clearTween  tween.Tween
+}
+
+func NewShakeComponent(res *Resources, opts *Options) (cmp ShakeComponent) {
+	h := opts.ShakeImageScale * opts.regularNoteHeight
+	x, y := opts.HitPositionX, opts.FieldPositionY
+
+	outer := draws.NewSprite(res.NoteImage)
+	outer.SetSize(h, h)
+	outer.Locate(x, y, draws.CenterMiddle)
+	outer.ColorScale.ScaleWithColor(color.NRGBA{255, 255, 255, 128})
+	cmp.outerSprite = outer
+
+	inner := draws.NewSprite(res.NoteImage)
+	inner.SetSize(h, h)
+	inner.Locate(x, y, draws.CenterMiddle)
+	inner.ColorScale.ScaleWithColor(opts.NoteColors[Purple])
+	cmp.innerSprite = inner
+
+	tw := tween.Tween{MaxLoop: 1}
+	tw.Add(1.00, +0.25, 200*time.Millisecond, tween.EaseLinear)
+	tw.Stop()
+	cmp.clearTween = tw
+	return
+}
+
+func (cmp *ShakeComponent) Update(now int32, s Scorer) {
+	cmp.now = now
+	cmp.staged, cmp.isStaged = s.stagedShake()
+	if s.isShakeClear {
+		cmp.clearTween.Start()
+	}
+	if !cmp.clearTween.IsFinished() {
+		cmp.clearTween.Update()
+	}
+}
+
+func (cmp ShakeComponent) Draw(dst draws.Image) {
+	if !cmp.clearTween.IsFinished() {
+		scale := cmp.clearTween.Value()
+		alpha := float32(1.25-scale) * 4
+		s := cmp.outerSprite
+		s.Scale(scale)
+		s.ColorScale.Scale(alpha, alpha, alpha, alpha)
+		s.Draw(dst)
+	}
+
+	n := cmp.staged
+	if !cmp.isStaged || n.Time > cmp.now {
+		return
+	}
+	cmp.outerSprite.Draw(dst)
+
+	progress := 1.0
+	if n.Tick > 0 {
+		progress = float64(n.hitTick) / float64(n.Tick)
+	}
+	s := cmp.innerSprite
+	s.Scale(progress)
+	s.Draw(dst)
+}
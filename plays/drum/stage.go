@@ -0,0 +1,45 @@
+package drum
+
+import "github.com/hndada/gosu/draws"
+
+// StageComponent draws field and hint.
+// Both go highlighted at highlight (kiai) time.
+type StageComponent struct {
+	fieldSprites [2]draws.Sprite
+	hintSprites  [2]draws.Sprite
+	highlight    bool
+}
+
+func NewStageComponent(res *Resources, opts *Options) (cmp StageComponent) {
+	for i, img := range res.FieldImages {
+		s := draws.NewSprite(img)
+		s.SetSize(opts.screenSizeX, opts.FieldHeight)
+		s.Locate(0, opts.FieldPositionY, draws.LeftMiddle)
+		s.ColorScale.Scale(1, 1, 1, opts.FieldOpacity)
+		cmp.fieldSprites[i] = s
+	}
+
+	// Hint is a bit larger than a regular note.
+	// Size of idle hint is used for both.
+	h := 1.2 * opts.regularNoteHeight
+	for i, img := range res.HintImages {
+		s := draws.NewSprite(img)
+		s.SetSize(h, h)
+		s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+		cmp.hintSprites[i] = s
+	}
+	return
+}
+
+func (cmp *StageComponent) Update(highlight bool) {
+	cmp.highlight = highlight
+}
+
+func (cmp StageComponent) Draw(dst draws.Image) {
+	state := idle
+	if cmp.highlight {
+		state = high
+	}
+	cmp.fieldSprites[state].Draw(dst)
+	cmp.hintSprites[state].Draw(dst)
+}
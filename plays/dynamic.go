@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"github.com/hndada/gosu/format/osu"
+	"github.com/hndada/gosu/format/ultrastar"
 )
 
 // int32 is enough for dealing with scene time in millisecond.
@@ -43,6 +44,10 @@ type Dynamics struct {
 	span       int32 // total duration of the chart
 	SpeedScale float64
 	// Reach      float64
+
+	// VisualOffset compensates display latency.
+	// Positive value makes notes drawn earlier.
+	VisualOffset int32
 }
 
 func NewDynamics(chart any) (Dynamics, error) {
@@ -52,6 +57,9 @@ func NewDynamics(chart any) (Dynamics, error) {
 	case *osu.Format:
 		ds = newDynamicListFromOsu(chart)
 		span = int32(chart.Duration())
+	case *ultrastar.Format:
+		ds = newDynamicListFromUltraStar(chart)
+		span = int32(chart.Duration())
 	}
 
 	if len(ds) == 0 {
@@ -113,6 +121,19 @@ func newDynamicListFromOsu(f *osu.Format) []Dynamic {
 	return ds
 }
 
+// UltraStar chart has only one BPM through the whole song.
+// A beat in UltraStar is a quarter of a beat in common sense.
+func newDynamicListFromUltraStar(f *ultrastar.Format) []Dynamic {
+	return []Dynamic{{
+		Time:    int32(f.Gap),
+		BPM:     f.BPM,
+		Speed:   1,
+		Meter:   4,
+		NewBeat: true,
+		Volume:  1,
+	}}
+}
+
 func (dys Dynamics) Dynamics() []Dynamic { return dys.data }
 
 // BPM with longest duration will be main BPM.
@@ -248,6 +269,11 @@ func (dys Dynamics) Position(t int32) float64 {
 	return dy.Position + dys.Speed()*float64(t-dy.Time)
 }
 
+// Cursor returns the position to be drawn at the given time.
+func (dys Dynamics) Cursor(t int32) float64 {
+	return dys.Position(t + dys.VisualOffset)
+}
+
 // NoteExposureDuration returns duration of note exposure:
 // the time that a note is visible on the screen.
 
@@ -61,3 +61,18 @@ func (js Judgments) Evaluate(e int32) JudgmentKind {
 	}
 	return js.blank
 }
+
+// Accuracy returns the weighted ratio of judgments.
+// It returns 0 when nothing has been judged yet.
+func (js Judgments) Accuracy() float64 {
+	var sum float64
+	var total int
+	for kind, count := range js.Counts {
+		sum += js.Judgments[kind].Weight * float64(count)
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / float64(total)
+}
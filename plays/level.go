@@ -1,5 +1,7 @@
 package plays
 
+import "sort"
+
 func LinearInterpolate(xs, ys []float64) func(float64) float64 {
 	return func(x float64) float64 {
 		// No out of index panic.
@@ -25,3 +27,18 @@ func WeightedSum(vs []float64, decayFactor float64) float64 {
 	}
 	return sum
 }
+
+// LevelDecayFactor is for summing up section difficulties
+// in descending order. Hard parts of a chart mostly decide the level.
+const LevelDecayFactor = 0.95
+
+// levelScale makes levels in a human-friendly range.
+const levelScale = 0.02
+
+// Level returns a level of a chart from its section difficulties.
+func Level(difficulties []float64) float64 {
+	ds := make([]float64, len(difficulties))
+	copy(ds, difficulties)
+	sort.Sort(sort.Reverse(sort.Float64Slice(ds)))
+	return WeightedSum(ds, LevelDecayFactor) * levelScale
+}
@@ -3,7 +3,10 @@ package piano
 import (
 	"io/fs"
 
+	"github.com/hndada/gosu/format/midi"
+	"github.com/hndada/gosu/format/osu"
 	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/util"
 )
 
 // Todo: make fields unexported?
@@ -11,6 +14,7 @@ type Chart struct {
 	Mods Mods
 	*plays.ChartHeader
 	plays.Dynamics
+	BackgroundSamples []plays.BackgroundSample
 	Notes
 	// KeyCount int
 }
@@ -24,15 +28,46 @@ func NewChart(fsys fs.FS, name string, mods Mods) (*Chart, error) {
 	if err != nil {
 		return c, err
 	}
+	return newChartFromFormat(format, hash, mods)
+}
+
+// NewChartFromMIDI converts MIDI to a chart, for prototyping charts
+// from existing arrangements. The converted chart can be saved
+// as .osu by encoding the format returned together.
+func NewChartFromMIDI(data []byte, opts midi.ConvertOptions, mods Mods) (*Chart, *osu.Format, error) {
+	mf, err := midi.NewFormat(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	format, err := mf.ToMania(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := newChartFromFormat(format, util.MD5(format.Encode()), mods)
+	return c, format, err
+}
+
+func newChartFromFormat(format any, hash string, mods Mods) (*Chart, error) {
+	c := &Chart{
+		Mods: mods,
+	}
 	header := plays.NewChartHeaderFromFormat(format, hash)
 	c.ChartHeader = header
 	// c.KeyCount = c.SubMode
 
+	if f, ok := format.(*osu.Format); ok && f.Mode == osu.ModeStandard {
+		if mods.KeyCount > 0 {
+			c.SubMode = mods.KeyCount
+		}
+		format = f.ToMania(c.SubMode)
+	}
+
 	dys, err := plays.NewDynamics(format)
 	if err != nil {
 		return c, err
 	}
 	c.Dynamics = dys
+	c.BackgroundSamples = plays.NewBackgroundSamples(format)
 
 	keyCount := c.SubMode
 	c.Notes = NewNotes(keyCount, format, dys)
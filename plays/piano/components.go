@@ -40,7 +40,7 @@ func NewComponents(res *Resources, opts *Options, c *Chart) (cmps Components) {
 }
 
 func (cmps *Components) Update(ka plays.KeyboardAction, dys plays.Dynamics, s Scorer) any {
-	cursor := dys.Position(ka.Time)
+	cursor := dys.Cursor(ka.Time)
 	cmps.field.Update()
 	cmps.bars.Update(cursor)
 	cmps.hint.Update()
@@ -3,7 +3,8 @@ package piano
 import "github.com/hndada/gosu/draws"
 
 type FieldComponent struct {
-	sprite draws.Sprite
+	sprite      draws.Sprite
+	lineSprites []draws.Sprite
 }
 
 func NewFieldComponent(res *Resources, opts *Options, keyCount int) (cmp FieldComponent) {
@@ -12,6 +13,24 @@ func NewFieldComponent(res *Resources, opts *Options, keyCount int) (cmp FieldCo
 	s.Locate(opts.StagePositionX, 0, draws.CenterTop)
 	s.ColorScale.Scale(1, 1, 1, opts.FieldOpacity)
 	cmp.sprite = s
+
+	// Column lines are drawn at the edges of lanes, down to keys.
+	xs := opts.keyPositionXsMap[keyCount]
+	ws := opts.keyWidthsMap[keyCount]
+	for i, w := range opts.columnLineWidths {
+		if w <= 0 || i > keyCount {
+			continue
+		}
+		x := opts.StagePositionX - opts.StageWidths[keyCount]/2
+		if i > 0 {
+			x = xs[i-1] + ws[i-1]/2
+		}
+		s := draws.NewSprite(res.BarImage)
+		s.SetSize(w, opts.KeyPositionY)
+		s.Locate(x, 0, draws.CenterTop)
+		s.ColorScale.ScaleWithColor(opts.columnLineColor)
+		cmp.lineSprites = append(cmp.lineSprites, s)
+	}
 	return
 }
 
@@ -21,4 +40,7 @@ func (cmp *FieldComponent) Update() {
 
 func (cmp FieldComponent) Draw(dst draws.Image) {
 	cmp.sprite.Draw(dst)
+	for _, s := range cmp.lineSprites {
+		s.Draw(dst)
+	}
 }
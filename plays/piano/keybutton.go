@@ -19,8 +19,9 @@ func NewKeyButtonsComponent(res *Resources, opts *Options, keyCount int) (cmp Ke
 	cmp.keysSprites = make([][2]draws.Sprite, keyCount)
 	ws := opts.keyWidthsMap[keyCount]
 	xs := opts.keyPositionXsMap[keyCount]
+	keysImages := res.keysKeyButtonsImages(keyCount)
 	for k := range cmp.keysSprites {
-		for i, img := range res.KeyButtonsImages {
+		for i, img := range keysImages[k] {
 			s := draws.NewSprite(img)
 			s.SetSize(ws[k], opts.keyButtonHeight)
 			s.Locate(xs[k], opts.KeyPositionY, draws.CenterTop)
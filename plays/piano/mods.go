@@ -3,6 +3,9 @@ package piano
 import "github.com/hndada/gosu/plays"
 
 type Mods struct {
+	// KeyCount is used when the chart is converted from osu!standard.
+	// Zero goes to the key count which osu! would choose.
+	KeyCount int
 }
 
 // Alternative names of Mods:
@@ -24,10 +24,11 @@ type Note struct {
 	Key    int
 	Sample plays.Sample
 
-	position float64 // Scaled x or y value.
-	next     int     // For updating staged notes.
-	prev     int     // For accessing to Head from Tail.
-	scored   bool
+	position   float64 // Scaled x or y value.
+	next       int     // For updating staged notes.
+	prev       int     // For accessing to Head from Tail.
+	scored     bool
+	autoPlayed bool // Keysound has been played without a hit.
 }
 
 // The length of the returned slice is 1 or 2.
@@ -62,12 +63,28 @@ type Notes struct {
 	// none         int   // index of none value. It is same as len(notes).
 }
 
+// isKeysounded reports whether most notes have their own keysounds.
+func (ns Notes) isKeysounded() bool {
+	var total, keysounds int
+	for _, n := range ns.data {
+		if n.Kind == Tail {
+			continue
+		}
+		total++
+		if n.Sample.Keysound {
+			keysounds++
+		}
+	}
+	return total > 0 && 2*keysounds >= total
+}
+
 func NewNotes(keyCount int, format plays.ChartFormat, dys plays.Dynamics) Notes {
 	var ns []Note
 	switch format := format.(type) {
 	case *osu.Format:
 		ns = make([]Note, 0, len(format.HitObjects)*2)
 		for _, ho := range format.HitObjects {
+			ho.HitSample = format.ResolveHitSample(ho)
 			ns = append(ns, newNoteFromOsu(ho, keyCount)...)
 		}
 		// keyCount = int(format.CircleSize)
@@ -146,9 +163,10 @@ type NotesComponent struct {
 }
 
 func NewNotesComponent(res *Resources, opts *Options, c *Chart) (cmp NotesComponent) {
+	keysFramesList, imported := res.keysNotesFramesList(c.keyCount)
 	cmp.keysAnims = make([][4]draws.Animation, c.keyCount)
 	for k := range cmp.keysAnims {
-		for nk, frames := range res.NotesFramesList {
+		for nk, frames := range keysFramesList[k] {
 			a := draws.NewAnimation(frames, 400)
 			w := opts.keyWidthsMap[c.keyCount][k]
 			h := opts.NoteHeight
@@ -171,9 +189,9 @@ func NewNotesComponent(res *Resources, opts *Options, c *Chart) (cmp NotesCompon
 	for i, n := range c.data {
 		d := cd(n.Time)
 		// d := c.Dynamics.UpdateIndex(n.Time)
-		// if n.Sample.Filename == "" {
-		// 	c.notes.data[i].Sample.Filename = res.defaultSampleName
-		// }
+		if len(n.Sample.Filenames) == 0 {
+			c.data[i].Sample.Filenames = plays.DefaultSample.Filenames
+		}
 		if n.Sample.Volume == 0 {
 			c.data[i].Sample.Volume = d.Volume
 		}
@@ -207,6 +225,10 @@ func NewNotesComponent(res *Resources, opts *Options, c *Chart) (cmp NotesCompon
 	cmp.keysColor = make([]color.NRGBA, c.keyCount)
 	order := opts.KeyOrders[c.keyCount]
 	for k := range cmp.keysColor {
+		if imported {
+			cmp.keysColor[k] = color.NRGBA{255, 255, 255, 255}
+			continue
+		}
 		cmp.keysColor[k] = opts.NoteColors[order[k]]
 	}
 	cmp.keysHolding = make([]bool, c.keyCount)
@@ -1,8 +1,11 @@
 package piano
 
 import (
+	"fmt"
 	"image/color"
+	"maps"
 
+	"github.com/hndada/gosu/input"
 	"github.com/hndada/gosu/plays"
 )
 
@@ -22,6 +25,7 @@ type Options struct {
 	// The name should expose such information; e.g., XxxsMap
 	// Unless the name itself contains the information.
 	KeyMappings     map[int][]string
+	GamepadMappings map[string]map[int][]string // By controller GUID, then by key count.
 	KeyOrders       map[int][]KeyKind
 	KeyScratchModes map[int]ScratchMode
 	KeyKindWidths   [4]float64
@@ -31,6 +35,14 @@ type Options struct {
 	keyButtonHeight  float64           // derived
 	keyPositionXsMap map[int][]float64 // derived
 
+	// Lines at both edges of lanes; only skins have them.
+	columnLineWidths []float64
+	columnLineColor  color.NRGBA
+
+	// Skins are values imported from osu! skins by key count.
+	// They are applied at each play, and are not saved.
+	skins map[int]skinOptions
+
 	FieldOpacity   float32
 	BarHeight      float64
 	HintHeight     float64
@@ -98,6 +110,14 @@ func NewOptions() *Options {
 			9:  {"A", "S", "D", "F", "Space", "J", "K", "L", "Semicolon"},
 			10: {"A", "S", "D", "F", "V", "N", "J", "K", "L", "Semicolon"},
 		},
+		// Dance pads usually send arrows as D-pad.
+		GamepadMappings: map[string]map[int][]string{
+			input.StandardGamepadGUID: {
+				4: {"DPadLeft", "DPadDown", "DPadUp", "DPadRight"},
+				6: {"LB", "DPadLeft", "DPadDown", "A", "B", "RB"},
+				7: {"LB", "DPadLeft", "DPadDown", "X", "A", "B", "RB"},
+			},
+		},
 		KeyOrders: map[int][]KeyKind{
 			1:  {Mid},
 			2:  {One, One},
@@ -174,8 +194,95 @@ func (opts *Options) SetDerived() {
 	for keyCount := 1; keyCount <= 10; keyCount++ {
 		ws := opts.keyWidths(keyCount)
 		opts.keyWidthsMap[keyCount] = ws
-		opts.keyPositionXsMap[keyCount] = opts.keyPositionXs(keyCount, ws)
+		opts.keyPositionXsMap[keyCount] = opts.keyPositionXs(keyCount, ws, nil)
+	}
+}
+
+// skinOptions are values of a skin for a key count.
+// Zero values of scales and JudgmentPositionY go to the defaults.
+type skinOptions struct {
+	keyWidths           []float64
+	keySpacings         []float64 // Gaps between lanes.
+	columnLineWidths    []float64
+	columnLineColor     color.NRGBA
+	keyPositionY        float64
+	judgmentPositionY   float64
+	backlightColors     [4]color.NRGBA // Zero alpha goes to the default.
+	hitLightImageScale  float64
+	holdLightImageScale float64
+	judgmentImageScale  float64
+}
+
+// forKeyCount returns a copy of options with the skin
+// of the key count applied, for playing the key count.
+func (opts Options) forKeyCount(keyCount int) *Options {
+	sk, ok := opts.skins[keyCount]
+	if !ok {
+		return &opts
+	}
+	var w float64
+	for _, kw := range sk.keyWidths {
+		w += kw
+	}
+	for _, ks := range sk.keySpacings {
+		w += ks
+	}
+	opts.StageWidths = maps.Clone(opts.StageWidths)
+	opts.StageWidths[keyCount] = w
+	opts.columnLineWidths = sk.columnLineWidths
+	opts.columnLineColor = sk.columnLineColor
+	opts.KeyPositionY = sk.keyPositionY
+	if sk.judgmentPositionY > 0 {
+		opts.JudgmentPositionY = sk.judgmentPositionY
+	}
+	for kind, c := range sk.backlightColors {
+		if c.A > 0 {
+			opts.BacklightColors[kind] = c
+		}
 	}
+	if sk.hitLightImageScale > 0 {
+		opts.HitLightImageScale = sk.hitLightImageScale
+	}
+	if sk.holdLightImageScale > 0 {
+		opts.HoldLightImageScale = sk.holdLightImageScale
+	}
+	if sk.judgmentImageScale > 0 {
+		opts.JudgmentImageScale = sk.judgmentImageScale
+	}
+
+	opts.SetDerived()
+	ws := sk.keyWidths
+	opts.keyWidthsMap[keyCount] = ws
+	opts.keyPositionXsMap[keyCount] = opts.keyPositionXs(keyCount, ws, sk.keySpacings)
+	return &opts
+}
+
+// KeyLayout returns center positions and widths of keys,
+// for drawing the stage out of a play such as in key binding,
+// and for mapping touches to lanes.
+func (opts Options) KeyLayout(keyCount int) (xs, ws []float64) {
+	o := opts.forKeyCount(keyCount)
+	return o.keyPositionXsMap[keyCount], o.keyWidthsMap[keyCount]
+}
+
+// SetKeyMapping validates key names before replacing the mapping.
+// Each key should be known and be bound to only one lane.
+func (opts *Options) SetKeyMapping(keyCount int, names []string) error {
+	if len(names) != keyCount {
+		return fmt.Errorf("%d keys for %d lanes", len(names), keyCount)
+	}
+	bound := make(map[input.Key]int)
+	for k, key := range input.NamesToKeys(names) {
+		if key == input.KeyNone {
+			return fmt.Errorf("lane %d: unknown key %q", k+1, names[k])
+		}
+		if k2, ok := bound[key]; ok {
+			return fmt.Errorf("lane %d: %s is already bound to lane %d", k+1, names[k], k2+1)
+		}
+		bound[key] = k
+	}
+	opts.KeyMappings[keyCount] = append([]string(nil), names...)
+	return nil
 }
 
 // I'm personally proud of this code.
@@ -221,13 +328,17 @@ func (opts Options) keyWidths(keyCount int) []float64 {
 	return keysW
 }
 
-func (opts Options) keyPositionXs(keyCount int, ws []float64) []float64 {
+// Spacings are gaps between lanes, which may be nil.
+func (opts Options) keyPositionXs(keyCount int, ws, spacings []float64) []float64 {
 	keysX := make([]float64, keyCount)
 	x := opts.StagePositionX - opts.StageWidths[keyCount]/2
 	for k, w := range ws {
 		x += w / 2
 		keysX[k] = x
 		x += w / 2
+		if k < len(spacings) {
+			x += spacings[k]
+		}
 	}
 	return keysX
 }
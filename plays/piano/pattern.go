@@ -0,0 +1,187 @@
+package piano
+
+import (
+	"sort"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// Pattern is a label of a section, which players use
+// for picking training charts by pattern type.
+type Pattern int
+
+const (
+	PatternNone Pattern = iota
+	Stream
+	Jumpstream
+	Handstream
+	Jack
+	Chordjack
+	LongNote
+	Technical // SV-heavy
+)
+
+var patternNames = [...]string{"", "stream", "jumpstream", "handstream",
+	"jack", "chordjack", "longnote", "technical"}
+
+func (p Pattern) String() string { return patternNames[p] }
+
+// Thresholds for classifying a section.
+const (
+	minRowsPerSection   = 4    // Sparse sections have no pattern.
+	longNoteRatioBound  = 0.5  // Ratio of Heads to hit notes.
+	jackRatioBound      = 0.5  // Ratio of rows sharing a key with the previous row.
+	chordjackRatioBound = 0.5  // Ratio of chord rows among jack sections.
+	handRatioBound      = 0.25 // Ratio of rows with 3 or more notes.
+	jumpRatioBound      = 0.25 // Ratio of rows with 2 notes.
+	minSpeedChanges     = 2    // The number of speed changes in a technical section.
+	minPatternShare     = 0.2  // Share of a pattern to be a tag.
+	maxPatternTags      = 3
+)
+
+// row is a set of hit notes at the same time.
+type row struct {
+	time int32
+	keys []bool
+	size int
+	head int // the number of Heads
+}
+
+func (c Chart) rows() []row {
+	var rs []row
+	for _, n := range c.Notes.data {
+		if n.Kind != Normal && n.Kind != Head {
+			continue
+		}
+		if len(rs) == 0 || rs[len(rs)-1].time != n.Time {
+			rs = append(rs, row{time: n.Time, keys: make([]bool, c.keyCount)})
+		}
+		r := &rs[len(rs)-1]
+		r.keys[n.Key] = true
+		r.size++
+		if n.Kind == Head {
+			r.head++
+		}
+	}
+	return rs
+}
+
+// Patterns returns the pattern of each section.
+func (c Chart) Patterns() []Pattern {
+	ps := make([]Pattern, plays.SectionCount(c.TotalDuration()))
+	rs := c.rows()
+	speedChanges := c.speedChanges(len(ps))
+
+	sectionIndex := func(t int32) int { return min(plays.SectionIndex(t), len(ps)-1) }
+
+	var prev row // Jack is also counted across sections.
+	for i := 0; i < len(rs); {
+		si := sectionIndex(rs[i].time)
+		var rows, notes, heads, jumps, hands, jacks, chordjacks int
+		for ; i < len(rs) && sectionIndex(rs[i].time) == si; i++ {
+			r := rs[i]
+			rows++
+			notes += r.size
+			heads += r.head
+			switch {
+			case r.size == 2:
+				jumps++
+			case r.size >= 3:
+				hands++
+			}
+			if isJack(prev, r) {
+				jacks++
+				if r.size >= 2 {
+					chordjacks++
+				}
+			}
+			prev = r
+		}
+
+		ratio := func(v, total int) float64 { return float64(v) / float64(total) }
+		switch {
+		case speedChanges[si] >= minSpeedChanges:
+			ps[si] = Technical
+		case rows < minRowsPerSection:
+			ps[si] = PatternNone
+		case ratio(heads, notes) >= longNoteRatioBound:
+			ps[si] = LongNote
+		case ratio(jacks, rows) >= jackRatioBound:
+			if ratio(chordjacks, jacks) >= chordjackRatioBound {
+				ps[si] = Chordjack
+			} else {
+				ps[si] = Jack
+			}
+		case ratio(hands, rows) >= handRatioBound:
+			ps[si] = Handstream
+		case ratio(jumps, rows) >= jumpRatioBound:
+			ps[si] = Jumpstream
+		default:
+			ps[si] = Stream
+		}
+	}
+	return ps
+}
+
+func isJack(prev, r row) bool {
+	for k, ok := range prev.keys {
+		if ok && r.keys[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// speedChanges returns the number of speed changes in each section.
+func (c Chart) speedChanges(count int) []int {
+	cs := make([]int, count)
+	ds := c.Dynamics.Dynamics()
+	for i := 1; i < len(ds); i++ {
+		if ds[i].Speed == ds[i-1].Speed {
+			continue
+		}
+		si := plays.SectionIndex(ds[i].Time)
+		if si >= count {
+			continue
+		}
+		cs[si]++
+	}
+	return cs
+}
+
+// PatternTags returns names of main patterns of the chart,
+// in descending order of their shares.
+func (c Chart) PatternTags() []string {
+	counts := make(map[Pattern]int)
+	var total int
+	for _, p := range c.Patterns() {
+		if p == PatternNone {
+			continue
+		}
+		counts[p]++
+		total++
+	}
+
+	ps := make([]Pattern, 0, len(counts))
+	for p, count := range counts {
+		if float64(count)/float64(total) < minPatternShare {
+			continue
+		}
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		if counts[ps[i]] == counts[ps[j]] {
+			return ps[i] < ps[j]
+		}
+		return counts[ps[i]] > counts[ps[j]]
+	})
+	if len(ps) > maxPatternTags {
+		ps = ps[:maxPatternTags]
+	}
+
+	tags := make([]string, len(ps))
+	for i, p := range ps {
+		tags[i] = p.String()
+	}
+	return tags
+}
@@ -20,6 +20,13 @@ type Play struct {
 }
 
 func NewPlay(res *Resources, opts *Options, c *Chart, mods Mods, sp *audios.SoundPlayer) (*Play, error) {
+	// Skins are applied by the key count of the chart.
+	res = res.forKeyCount(c.keyCount)
+	opts = opts.forKeyCount(c.keyCount)
+	if err := addSamples(res, c, sp); err != nil {
+		return nil, err
+	}
+
 	return &Play{
 		Resources: res,
 		Options:   opts,
@@ -32,12 +39,41 @@ func NewPlay(res *Resources, opts *Options, c *Chart, mods Mods, sp *audios.Soun
 	}, nil
 }
 
+// Skin default samples are used when the chart has no file of the name.
+func addSamples(res *Resources, c *Chart, sp *audios.SoundPlayer) error {
+	for name, data := range res.DefaultSamples {
+		if sp.Has(name) {
+			continue
+		}
+		if err := sp.Add(data, name); err != nil {
+			return fmt.Errorf("failed to add default sample: %w", err)
+		}
+	}
+	if !sp.Has(plays.DefaultSampleFilename) && len(res.HitSound) > 0 {
+		if err := sp.Add(res.HitSound, plays.DefaultSampleFilename); err != nil {
+			return fmt.Errorf("failed to add hit sound: %w", err)
+		}
+	}
+	ns := c.Notes.data
+	for i, n := range ns {
+		ns[i].Sample.Filenames = plays.ResolveSampleFilenames(n.Sample.Filenames, sp.Has)
+	}
+	return nil
+}
+
+// Play is finished when a while has passed after the last note.
+const finishWait = 2000
+
+// Update returns Scorer when the play is finished.
 func (p *Play) Update(now int32, kas []plays.KeyboardAction) any {
 	for _, ka := range kas {
 		// fmt.Printf("ka: %v\n", ka)
 		p.Scorer.update(ka)
 		p.Components.Update(ka, p.Dynamics, p.Scorer)
 	}
+	if now > p.TotalDuration()+finishWait {
+		return p.Scorer
+	}
 	return nil
 }
 
@@ -0,0 +1,63 @@
+package piano
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hndada/gosu/audios"
+	"github.com/hndada/gosu/format/osu"
+	"github.com/hndada/gosu/plays"
+	"github.com/hndada/gosu/resources"
+)
+
+// Soft sample set, with skin's samples. The second note has a clap.
+const testSampleChart = `osu file format v14
+
+[General]
+Mode: 3
+
+[Difficulty]
+CircleSize:4
+OverallDifficulty:8
+
+[TimingPoints]
+0,500,4,2,0,100,1,0
+
+[HitObjects]
+64,192,1000,1,0,0:0:0:0:
+192,192,1500,1,8,0:0:0:0:
+`
+
+// Additions are played along with the normal sound,
+// each resolved to the skin default sample.
+func TestAddSamplesAdditions(t *testing.T) {
+	format, err := osu.NewFormat([]byte(testSampleChart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newChartFromFormat(format, "", Mods{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &Resources{
+		DefaultSamples: plays.LoadDefaultSamples(resources.DefaultFS, plays.SkinSampleDir),
+	}
+	scale := 1.0
+	sp := audios.NewSoundPlayer(&scale)
+	if err := addSamples(res, c, &sp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"sample/soft-hitnormal.wav"},
+		{"sample/soft-hitnormal.wav", "sample/soft-hitclap.wav"},
+	}
+	if len(c.Notes.data) != len(want) {
+		t.Fatalf("got %d notes, want %d", len(c.Notes.data), len(want))
+	}
+	for i, n := range c.Notes.data {
+		if !slices.Equal(n.Sample.Filenames, want[i]) {
+			t.Errorf("note %d: got %v, want %v", i, n.Sample.Filenames, want[i])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package piano
+
+import (
+	"time"
+
+	"github.com/hndada/gosu/audios"
+	"github.com/hndada/gosu/input"
+	"github.com/hndada/gosu/plays"
+)
+
+// RenderSamples feeds the replay to a scorer, so that samples are
+// played to sp as in a play, along with background samples.
+// It is for rendering audio offline: advance is called with chart time
+// every millisecond before anything is played at the time, so that
+// an offline mixer can catch up.
+func RenderSamples(res *Resources, c *Chart, mods Mods, replay plays.Replay, sp *audios.SoundPlayer, advance func(now int32) error) error {
+	if err := addSamples(res, c, sp); err != nil {
+		return err
+	}
+	s := NewScorer(&c.Notes, mods, sp)
+
+	var bi int
+	last := input.KeyboardState{KeysPressed: make([]bool, c.Notes.keyCount)}
+	for now := int32(0); now <= c.TotalDuration()+finishWait; now++ {
+		if err := advance(now); err != nil {
+			return err
+		}
+		for ; bi < len(c.BackgroundSamples) && c.BackgroundSamples[bi].Time <= now; bi++ {
+			bs := c.BackgroundSamples[bi]
+			for _, name := range bs.Filenames {
+				sp.PlayWithVolume(name, bs.Volume)
+			}
+		}
+
+		// Replay is polled every millisecond as keyboard is.
+		d := time.Duration(now) * time.Millisecond
+		kss := replay.Read(d)[1:]
+		if len(kss) == 0 {
+			kss = []input.KeyboardState{{Time: d, KeysPressed: last.KeysPressed}}
+		}
+		kss = append([]input.KeyboardState{last}, kss...)
+		for _, ka := range plays.KeyboardActions(kss) {
+			s.update(ka)
+		}
+		last = kss[len(kss)-1]
+	}
+	return nil
+}
@@ -24,9 +24,73 @@ type Resources struct {
 	HitLightsFrames    draws.Frames
 	HoldLightsFrames   draws.Frames
 	JudgmentFramesList [4]draws.Frames
-	HitSound           []byte
-	ComboImages        []draws.Image // 10
-	ScoreImages        []draws.Image // 13
+	HitSound           []byte            // Used when default samples miss the normal sound.
+	DefaultSamples     map[string][]byte // Skin default samples by name
+	ComboImages        []draws.Image     // 10
+	ScoreImages        []draws.Image     // 13
+
+	// Skins are images imported from osu! skins by key count.
+	// Key counts without them use the images above.
+	skins map[int]skinResources
+}
+
+// Images by lane are drawn in their own colors, unlike default ones.
+// Empty images go to the defaults.
+type skinResources struct {
+	notesFramesList    [][4]draws.Frames // By lane
+	keyButtonsImages   [][2]draws.Image  // By lane
+	hintImage          draws.Image
+	hitLightsFrames    draws.Frames
+	holdLightsFrames   draws.Frames
+	judgmentFramesList [4]draws.Frames
+}
+
+// forKeyCount returns a copy of resources with the skin
+// of the key count applied, for playing the key count.
+func (res Resources) forKeyCount(keyCount int) *Resources {
+	sk, ok := res.skins[keyCount]
+	if !ok {
+		return &res
+	}
+	if !sk.hintImage.IsEmpty() {
+		res.HintImage = sk.hintImage
+	}
+	if len(sk.hitLightsFrames) > 0 {
+		res.HitLightsFrames = sk.hitLightsFrames
+	}
+	if len(sk.holdLightsFrames) > 0 {
+		res.HoldLightsFrames = sk.holdLightsFrames
+	}
+	for i, frames := range sk.judgmentFramesList {
+		if len(frames) > 0 {
+			res.JudgmentFramesList[i] = frames
+		}
+	}
+	return &res
+}
+
+// keysNotesFramesList returns note frames of each lane, and whether
+// they are imported. Imported images are drawn in their own colors.
+func (res Resources) keysNotesFramesList(keyCount int) ([][4]draws.Frames, bool) {
+	if fl := res.skins[keyCount].notesFramesList; fl != nil {
+		return fl, true
+	}
+	fl := make([][4]draws.Frames, keyCount)
+	for k := range fl {
+		fl[k] = res.NotesFramesList
+	}
+	return fl, false
+}
+
+func (res Resources) keysKeyButtonsImages(keyCount int) [][2]draws.Image {
+	if imgs := res.skins[keyCount].keyButtonsImages; imgs != nil {
+		return imgs
+	}
+	imgs := make([][2]draws.Image, keyCount)
+	for k := range imgs {
+		imgs[k] = res.KeyButtonsImages
+	}
+	return imgs
 }
 
 func loadFieldImage() draws.Image {
@@ -105,6 +169,7 @@ func NewResources(fsys fs.FS) *Resources {
 		HoldLightsFrames:   loadHoldLightFrames(fsys),
 		JudgmentFramesList: loadJudgmentFramesList(fsys),
 		HitSound:           loadHitSound(fsys),
+		DefaultSamples:     plays.LoadDefaultSamples(fsys, plays.SkinSampleDir),
 		ComboImages:        plays.LoadComboImages(fsys),
 		ScoreImages:        plays.LoadScoreImages(fsys),
 	}
@@ -39,6 +39,7 @@ type Scorer struct {
 	Score            float64
 
 	samplePlayer *audios.SoundPlayer
+	keysounded   bool
 }
 
 func NewScorer(ns *Notes, mods Mods, sp *audios.SoundPlayer) (s Scorer) {
@@ -57,6 +58,7 @@ func NewScorer(ns *Notes, mods Mods, sp *audios.SoundPlayer) (s Scorer) {
 	s.Score = 0.01
 
 	s.samplePlayer = sp
+	s.keysounded = ns.isKeysounded()
 	return
 }
 
@@ -67,6 +69,9 @@ func (s *Scorer) update(ka plays.KeyboardAction) {
 		s.keysJudgmentKind[k] = blank
 	}
 
+	if s.keysounded {
+		s.autoPlayKeysounds(ka.Time)
+	}
 	s.markKeysUntouchedNote(ka.Time)
 
 	for k, ni := range s.notes.keysFocus {
@@ -75,8 +80,7 @@ func (s *Scorer) update(ka plays.KeyboardAction) {
 		}
 		n := s.notes.data[ni]
 		if ka.KeysAction[k] == plays.Hit {
-			// s.playSample(n.Sample)
-			// s.sampleBuffer = append(s.sampleBuffer, n.Sample)
+			s.playSample(k, n)
 		}
 		if n.scored {
 			continue
@@ -84,12 +88,59 @@ func (s *Scorer) update(ka plays.KeyboardAction) {
 		e := n.Time - ka.Time
 		if jk := s.judge(n.Kind, e, ka.KeysAction[k]); jk != blank {
 			s.markNote(ni, jk)
+			// Keysound of a long note is cut when released early.
+			if s.keysounded && s.samplePlayer != nil && n.Kind == Tail && jk == miss && ka.KeysAction[k] == plays.Released {
+				s.samplePlayer.StopChannel(k)
+			}
 		}
 	}
 }
 
-func (s Scorer) playSample(smp plays.Sample) {
-	s.samplePlayer.PlayWithVolume(smp.Filename, smp.Volume)
+// A ghost tap plays the sample of the focused note as well.
+// Tail has no sample sound.
+func (s Scorer) playSample(k int, n Note) {
+	if s.samplePlayer == nil || n.Kind == Tail {
+		return
+	}
+	if !s.keysounded {
+		for _, name := range n.Sample.Filenames {
+			s.samplePlayer.PlayWithVolume(name, n.Sample.Volume)
+		}
+		return
+	}
+	// A late hit does not replay the keysound which has been auto-played.
+	if n.autoPlayed {
+		return
+	}
+	s.playKeysound(k, n.Sample)
+}
+
+// Keysound chokes the previous one on the same key.
+func (s Scorer) playKeysound(k int, smp plays.Sample) {
+	for _, name := range smp.Filenames {
+		s.samplePlayer.PlayOnChannel(k, name, smp.Volume)
+	}
+}
+
+// autoPlayKeysounds plays keysounds of notes which have not been hit
+// by their time, so that the music would not break by misses.
+func (s *Scorer) autoPlayKeysounds(now int32) {
+	if s.samplePlayer == nil {
+		return
+	}
+	for k, ni := range s.notes.keysFocus {
+		for ; ni >= 0 && ni < len(s.notes.data); ni = s.notes.data[ni].next {
+			n := &s.notes.data[ni]
+			if n.Time > now {
+				break
+			}
+			if n.scored || n.autoPlayed || n.Kind == Tail {
+				continue
+			}
+			s.playKeysound(k, n.Sample)
+			n.autoPlayed = true
+		}
+	}
 }
 
 // marks the untouched note as missed.
@@ -0,0 +1,96 @@
+package piano
+
+import "github.com/hndada/gosu/plays"
+
+// Strain is for calculating difficulty.
+// Jack (repeated notes in the same key) gets more strain as the gap is shorter.
+var jackStrain = plays.LinearInterpolate([]float64{0, 200}, []float64{1.5, 0})
+
+// Short long note does not require much strain to release,
+// while long one requires to keep holding the key.
+var tailStrain = plays.LinearInterpolate(
+	[]float64{0, 50, 200, 800}, []float64{0.4, 0.1, 0.1, 0.7})
+
+// Chord adds strain for each additional note pressed at the same time.
+const chordStrain = 0.1
+
+// noteStrain returns the strain of the note at index ni.
+// Body is not counted, since it is not a part of Notes.data.
+func (ns Notes) noteStrain(ni int) float64 {
+	n := ns.data[ni]
+	if n.Kind == Tail {
+		head := ns.data[n.prev]
+		return tailStrain(float64(n.Time - head.Time))
+	}
+
+	strain := 1.0
+	if n.prev != -1 {
+		gap := float64(n.Time - ns.data[n.prev].Time)
+		strain += jackStrain(gap)
+	}
+	return strain
+}
+
+// Difficulties returns strain of each section.
+func (c Chart) Difficulties() []float64 {
+	ds := make([]float64, plays.SectionCount(c.TotalDuration()))
+	ns := c.Notes.data
+	for ni, n := range ns {
+		i := plays.SectionIndex(n.Time)
+		if i >= len(ds) {
+			i = len(ds) - 1
+		}
+		strain := c.Notes.noteStrain(ni)
+
+		// Notes are sorted by time, then key.
+		// Counting previous notes is enough for chord.
+		for pi := ni - 1; pi >= 0 && ns[pi].Time == n.Time; pi-- {
+			if ns[pi].Kind != Tail {
+				strain += chordStrain
+			}
+		}
+		ds[i] += strain
+	}
+	return ds
+}
+
+// hitTimes returns times of notes which require hitting a key.
+func (c Chart) hitTimes() []int32 {
+	ts := make([]int32, 0, len(c.Notes.data))
+	for _, n := range c.Notes.data {
+		if n.Kind == Normal || n.Kind == Head {
+			ts = append(ts, n.Time)
+		}
+	}
+	return ts
+}
+
+// Densities returns notes per second of each section.
+// Tails are not counted.
+func (c Chart) Densities() []float64 {
+	return plays.Densities(c.hitTimes(), c.TotalDuration())
+}
+
+func (c Chart) PeakNPS() float64 { return plays.Peak(c.Densities()) }
+
+// Tail should be considered in drain time, since
+// holding a long note is a part of playing.
+func (c Chart) DrainTime() int32 {
+	ts := make([]int32, len(c.Notes.data))
+	for i, n := range c.Notes.data {
+		ts[i] = n.Time
+	}
+	return plays.DrainTime(ts)
+}
+
+// LongNoteRatio returns the ratio of long notes to all notes.
+func (c Chart) LongNoteRatio() float64 {
+	counts := c.NoteCounts()
+	total := counts[0] + counts[1]
+	if total == 0 {
+		return 0
+	}
+	return float64(counts[1]) / float64(total)
+}
+
+func (c Chart) Level() float64 { return plays.Level(c.Difficulties()) }
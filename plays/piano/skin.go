@@ -0,0 +1,225 @@
+package piano
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/format/osu"
+	"github.com/hndada/gosu/plays"
+)
+
+// osu! lays out mania stages on a screen of 480 pixels high.
+// Images without @2x suffix are in the same scale.
+const osuScreenSizeY = 480
+
+// ImportOsuSkin reads skin.ini at the root of fsys, then applies
+// its [Mania] sections to resources and options by key count:
+// lane widths, images, hit position and so on. They take effect
+// when the key count is played. Images missing in the skin stay as they are.
+// Hit sound samples at the root replace skin default samples of any key count.
+func ImportOsuSkin(fsys fs.FS, res *Resources, opts *Options) error {
+	data, err := fs.ReadFile(fsys, "skin.ini")
+	if err != nil {
+		return err
+	}
+	skin, err := osu.NewSkin(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse skin.ini: %w", err)
+	}
+
+	ld := newOsuSkinLoader(fsys)
+	if res.skins == nil {
+		res.skins = make(map[int]skinResources)
+	}
+	if opts.skins == nil {
+		opts.skins = make(map[int]skinOptions)
+	}
+	for kc, m := range skin.Manias {
+		if kc < 1 || kc > 10 {
+			continue // Piano supports up to 10 keys.
+		}
+		sr, so := ld.skin(m, res, opts)
+		res.skins[kc] = sr
+		opts.skins[kc] = so
+	}
+
+	if res.DefaultSamples == nil {
+		res.DefaultSamples = make(map[string][]byte)
+	}
+	for name, data := range plays.LoadDefaultSamples(fsys, ".") {
+		res.DefaultSamples[name] = data
+	}
+	return nil
+}
+
+// skin converts a [Mania] section in osu!'s scale to gosu's.
+func (ld osuSkinLoader) skin(m osu.ManiaSkin, res *Resources, opts *Options) (sr skinResources, so skinOptions) {
+	scale := float64(plays.ScreenSizeY) / osuScreenSizeY
+	scaled := func(vs []float64) []float64 {
+		vs2 := make([]float64, len(vs))
+		for i, v := range vs {
+			vs2[i] = v * scale
+		}
+		return vs2
+	}
+	so.keyWidths = scaled(m.ColumnWidth)
+	so.keySpacings = scaled(m.ColumnSpacing)
+	so.columnLineWidths = scaled(m.ColumnLineWidth)
+	so.columnLineColor = m.ColourColumnLine
+	so.keyPositionY = m.HitPosition * scale
+	so.judgmentPositionY = m.ScorePosition * scale
+
+	// Backlights are colored by key kind; the first lane of each kind goes.
+	for k, kind := range opts.KeyOrder(m.Keys) {
+		if so.backlightColors[kind].A > 0 || k >= len(m.ColourLights) || m.ColourLights[k].A == 0 {
+			continue
+		}
+		c := m.ColourLights[k]
+		c.A = opts.BacklightColors[kind].A
+		so.backlightColors[kind] = c
+	}
+
+	// Default images are tinted by note colors, while skin's are not.
+	if fl, ok := ld.keysNotesFramesList(m, res.NotesFramesList); ok {
+		sr.notesFramesList = fl
+	}
+	sr.keyButtonsImages = ld.keysKeyButtonsImages(m, res.KeyButtonsImages)
+	if frames, _ := ld.frames(orDefault(m.StageHint, "mania-stage-hint")); len(frames) > 0 {
+		sr.hintImage = frames[0]
+	}
+	if frames, s := ld.frames(orDefault(m.LightingN, "lightingN")); len(frames) > 0 {
+		sr.hitLightsFrames = frames
+		so.hitLightImageScale = s * scale
+	}
+	if frames, s := ld.frames(orDefault(m.LightingL, "lightingL")); len(frames) > 0 {
+		sr.holdLightsFrames = frames
+		so.holdLightImageScale = s * scale
+	}
+
+	// Judgments: kool, cool, good, miss.
+	for i, hit := range []string{"Hit300g", "Hit300", "Hit200", "Hit0"} {
+		frames, s := ld.frames(orDefault(m.Hits[hit], "mania-"+strings.ToLower(hit)))
+		if len(frames) == 0 {
+			continue
+		}
+		sr.judgmentFramesList[i] = frames
+		so.judgmentImageScale = s * scale
+	}
+	return
+}
+
+// osuSkinLoader caches images, since lanes of different
+// key counts usually share the same images.
+type osuSkinLoader struct {
+	fsys   fs.FS
+	cache  map[string]draws.Frames
+	scales map[string]float64
+}
+
+func newOsuSkinLoader(fsys fs.FS) osuSkinLoader {
+	return osuSkinLoader{
+		fsys:   fsys,
+		cache:  make(map[string]draws.Frames),
+		scales: make(map[string]float64),
+	}
+}
+
+// frames returns images of the name, along with their scale to
+// osu!'s screen. Animations are numbered with hyphen, such as
+// lightingN-0.png. Images with @2x suffix go first, as osu! does.
+func (ld osuSkinLoader) frames(name string) (draws.Frames, float64) {
+	if frames, ok := ld.cache[name]; ok {
+		return frames, ld.scales[name]
+	}
+	for _, res := range []struct {
+		suffix string
+		scale  float64
+	}{{"@2x", 0.5}, {"", 1}} {
+		var frames draws.Frames
+		for i := 0; ; i++ {
+			fname := fmt.Sprintf("%s-%d%s.png", name, i, res.suffix)
+			img := draws.NewImageFromFile(ld.fsys, fname)
+			if img.IsEmpty() {
+				break
+			}
+			frames = append(frames, img)
+		}
+		if len(frames) == 0 {
+			img := draws.NewImageFromFile(ld.fsys, name+res.suffix+".png")
+			if !img.IsEmpty() {
+				frames = draws.Frames{img}
+			}
+		}
+		if len(frames) > 0 {
+			ld.cache[name] = frames
+			ld.scales[name] = res.scale
+			return frames, res.scale
+		}
+	}
+	ld.cache[name] = nil
+	return nil, 0
+}
+
+// Missing Tail falls back to Head, and missing Head falls back to Normal.
+// It reports false when the skin has no note image at all.
+func (ld osuSkinLoader) keysNotesFramesList(m osu.ManiaSkin, base [4]draws.Frames) ([][4]draws.Frames, bool) {
+	fl := make([][4]draws.Frames, m.Keys)
+	var found bool
+	for k := range fl {
+		d := "mania-note" + osuColumnKind(m.Keys, k)
+		fl[k] = base
+		if frames, _ := ld.frames(orDefault(m.NoteImages[k], d)); len(frames) > 0 {
+			found = true
+			fl[k][Normal] = frames
+			fl[k][Head] = frames
+			fl[k][Tail] = frames
+		}
+		if frames, _ := ld.frames(orDefault(m.NoteImagesH[k], d+"H")); len(frames) > 0 {
+			fl[k][Head] = frames
+			fl[k][Tail] = frames
+		}
+		if frames, _ := ld.frames(orDefault(m.NoteImagesT[k], d+"T")); len(frames) > 0 {
+			fl[k][Tail] = frames
+		}
+		if frames, _ := ld.frames(orDefault(m.NoteImagesL[k], d+"L")); len(frames) > 0 {
+			fl[k][Body] = frames
+		}
+	}
+	return fl, found
+}
+
+func (ld osuSkinLoader) keysKeyButtonsImages(m osu.ManiaSkin, base [2]draws.Image) [][2]draws.Image {
+	imgs := make([][2]draws.Image, m.Keys)
+	for k := range imgs {
+		d := "mania-key" + osuColumnKind(m.Keys, k)
+		imgs[k] = base
+		if frames, _ := ld.frames(orDefault(m.KeyImages[k], d)); len(frames) > 0 {
+			imgs[k][0] = frames[0]
+		}
+		if frames, _ := ld.frames(orDefault(m.KeyImagesD[k], d+"D")); len(frames) > 0 {
+			imgs[k][1] = frames[0]
+		}
+	}
+	return imgs
+}
+
+// osuColumnKind returns the suffix of default images of the column:
+// 1 and 2 take turns from both sides, and the center of odd keys is S.
+func osuColumnKind(keyCount, k int) string {
+	if keyCount%2 == 1 && k == keyCount/2 {
+		return "S"
+	}
+	if k >= keyCount/2 {
+		k = keyCount - 1 - k // Mirrored
+	}
+	return []string{"1", "2"}[k%2]
+}
+
+func orDefault(name, defaultName string) string {
+	if name == "" {
+		return defaultName
+	}
+	return name
+}
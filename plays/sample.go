@@ -1,14 +1,105 @@
 package plays
 
-import "github.com/hndada/gosu/format/osu"
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
 
+	"github.com/hndada/gosu/format/osu"
+)
+
+// Sample has names of sound files which are played together.
+// Keysound is true when the chart gives its own sound file to the note,
+// instead of hitsounds made of sample sets.
 type Sample struct {
-	Filename string
-	Volume   float64
+	Filenames []string
+	Volume    float64
+	Keysound  bool
+}
+
+var DefaultSample = Sample{Filenames: []string{DefaultSampleFilename}, Volume: 1.0}
+
+// Skin default samples are named after osu!'s: <sampleSet>-hit<hitSound>.wav.
+// SkinSampleDir is prefixed to their names, so that a name with it refers
+// to the skin's, while a name without it refers to the chart's.
+// Resources ship all of them in SkinSampleDir, and an osu! skin may
+// replace them. DefaultSampleFilename is used when a note has no sample.
+const (
+	SkinSampleDir         = "sample/"
+	DefaultSampleFilename = SkinSampleDir + "normal-hitnormal.wav"
+)
+
+var (
+	defaultSampleSetNames = []string{"normal", "soft", "drum"}
+	defaultHitSoundNames  = []string{"normal", "whistle", "finish", "clap"}
+)
+
+// LoadDefaultSamples loads skin default samples in dir.
+// Keys of the returned map are names with SkinSampleDir,
+// regardless of dir. Missing samples are skipped.
+func LoadDefaultSamples(fsys fs.FS, dir string) map[string][]byte {
+	samples := make(map[string][]byte)
+	for _, set := range defaultSampleSetNames {
+		for _, sound := range defaultHitSoundNames {
+			name := fmt.Sprintf("%s-hit%s.wav", set, sound)
+			data, err := fs.ReadFile(fsys, path.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			samples[SkinSampleDir+name] = data
+		}
+	}
+	return samples
+}
+
+// ResolveSampleFilenames replaces names which are not available by has.
+// A chart's sample falls back to the skin's of the same sample set and
+// hit sound, regardless of custom index. Then, missing normal sound
+// falls back to DefaultSampleFilename, while missing additions are skipped.
+func ResolveSampleFilenames(names []string, has func(string) bool) []string {
+	resolved := make([]string, 0, len(names))
+	for _, name := range names {
+		if has(name) {
+			resolved = append(resolved, name)
+			continue
+		}
+		skinName, isNormal := skinSampleFilename(name)
+		switch {
+		case skinName != "" && has(skinName):
+			resolved = append(resolved, skinName)
+		case isNormal && has(DefaultSampleFilename):
+			resolved = append(resolved, DefaultSampleFilename)
+		}
+	}
+	return resolved
 }
 
-var DefaultSample = Sample{Filename: "", Volume: 1.0}
+// skinSampleFilename returns the skin's sample name of given sample name.
+// It returns an empty string when the name is not of hit sound.
+func skinSampleFilename(name string) (skinName string, isNormal bool) {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	base = strings.TrimRight(base, "0123456789") // Drop custom index.
+	set, sound, ok := strings.Cut(base, "-hit")
+	if !ok {
+		return "", false
+	}
+	for _, set2 := range defaultSampleSetNames {
+		if set != set2 {
+			continue
+		}
+		for _, sound2 := range defaultHitSoundNames {
+			if sound == sound2 {
+				return SkinSampleDir + base + ".wav", sound == "normal"
+			}
+		}
+	}
+	return "", false
+}
 
+// NewSample should be given resolved data of the format,
+// such as osu.HitObject with resolved HitSample.
 func NewSample(f any) (s Sample) {
 	switch f := f.(type) {
 	case osu.HitObject:
@@ -18,8 +109,43 @@ func NewSample(f any) (s Sample) {
 }
 
 func newSampleFromOsu(f osu.HitObject) (s Sample) {
+	names := f.SampleFilenames()
+	if f.UsesSkinSamples() {
+		for i, name := range names {
+			names[i] = SkinSampleDir + name
+		}
+	}
 	return Sample{
-		Filename: f.SampleFilename(),
-		Volume:   float64(f.HitSample.Volume) / 100,
+		Filenames: names,
+		Volume:    float64(f.HitSample.Volume) / 100,
+		Keysound:  f.HitSample.Filename != "",
+	}
+}
+
+// BackgroundSample is played at its time regardless of the player's input,
+// such as osu! storyboard samples and BMS background channels.
+type BackgroundSample struct {
+	Time int32
+	Sample
+}
+
+// NewBackgroundSamples returns background samples sorted by time.
+func NewBackgroundSamples(format any) (bss []BackgroundSample) {
+	switch format := format.(type) {
+	case *osu.Format:
+		for _, e := range format.Samples() {
+			// Paths in osu! may be written with backslashes.
+			name := strings.ReplaceAll(e.Filename, `\`, "/")
+			bss = append(bss, BackgroundSample{
+				Time: int32(e.StartTime),
+				Sample: Sample{
+					Filenames: []string{name},
+					Volume:    float64(e.Volume) / 100,
+					Keysound:  true,
+				},
+			})
+		}
 	}
+	sort.SliceStable(bss, func(i, j int) bool { return bss[i].Time < bss[j].Time })
+	return
 }
@@ -0,0 +1,39 @@
+package plays
+
+import (
+	"testing"
+
+	"github.com/hndada/gosu/resources"
+)
+
+// Chart samples missing in the chart resolve to the skin's
+// of the same sample set and hit sound, which resources ship.
+func TestResolveSampleFilenames(t *testing.T) {
+	samples := LoadDefaultSamples(resources.DefaultFS, SkinSampleDir)
+	if n := len(defaultSampleSetNames) * len(defaultHitSoundNames); len(samples) != n {
+		t.Errorf("got %d default samples, want %d", len(samples), n)
+	}
+	chart := map[string]bool{"soft-hitclap.wav": true}
+	has := func(name string) bool { return chart[name] || samples[name] != nil }
+
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"soft-hitclap.wav", "soft-hitclap.wav"}, // The chart's own
+		{"soft-hitnormal2.wav", "sample/soft-hitnormal.wav"},
+		{"soft-hitwhistle.wav", "sample/soft-hitwhistle.wav"},
+		{"drum-hitclap.wav", "sample/drum-hitclap.wav"},
+		{"drum-hitfinish3.wav", "sample/drum-hitfinish.wav"},
+		{"sample/drum-hitnormal.wav", "sample/drum-hitnormal.wav"},
+		{"custom.wav", ""}, // Keysound missing is skipped.
+	} {
+		got := ResolveSampleFilenames([]string{tc.name}, has)
+		switch {
+		case tc.want == "" && len(got) != 0:
+			t.Errorf("%s: got %v, want none", tc.name, got)
+		case tc.want != "" && (len(got) != 1 || got[0] != tc.want):
+			t.Errorf("%s: got %v, want %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package plays
+
+// Section is a time bucket for per-section statistics,
+// such as difficulty (strain) and density (notes per second).
+// 800ms is same as the section duration of drum mode's difficulty.
+const SectionDuration = 800
+
+// A gap between two notes longer than BreakDuration is considered
+// as a break. Break is not counted to drain time.
+const BreakDuration = 5000
+
+// SectionCount returns the number of sections which cover the whole duration.
+func SectionCount(duration int32) int {
+	if duration < 0 {
+		return 1
+	}
+	return int(duration/SectionDuration) + 1
+}
+
+// SectionIndex returns index of section which the given time belongs to.
+// Times before zero are put into the first section.
+func SectionIndex(t int32) int {
+	if t < 0 {
+		return 0
+	}
+	return int(t / SectionDuration)
+}
+
+// Densities returns notes per second of each section.
+// Given times are supposed to be sorted.
+func Densities(times []int32, duration int32) []float64 {
+	ds := make([]float64, SectionCount(duration))
+	for _, t := range times {
+		i := SectionIndex(t)
+		if i >= len(ds) {
+			i = len(ds) - 1
+		}
+		ds[i]++
+	}
+
+	// Converts counts per section to counts per second.
+	const scale = 1000.0 / SectionDuration
+	for i := range ds {
+		ds[i] *= scale
+	}
+	return ds
+}
+
+// Peak returns the largest value of sections.
+func Peak(vs []float64) (peak float64) {
+	for _, v := range vs {
+		if peak < v {
+			peak = v
+		}
+	}
+	return
+}
+
+// DrainTime returns the duration of playing, excluding breaks.
+// Given times are supposed to be sorted.
+func DrainTime(times []int32) (drain int32) {
+	for i := 1; i < len(times); i++ {
+		gap := times[i] - times[i-1]
+		if gap > BreakDuration {
+			continue
+		}
+		drain += gap
+	}
+	return
+}
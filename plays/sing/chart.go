@@ -0,0 +1,70 @@
+package sing
+
+import (
+	"io/fs"
+
+	"github.com/hndada/gosu/format/ultrastar"
+	"github.com/hndada/gosu/plays"
+)
+
+// Sing chart consists of lines, and each line consists of notes.
+// A line is displayed at once, just like a line of lyrics in karaoke.
+type Chart struct {
+	Mods Mods
+	*plays.ChartHeader
+	plays.Dynamics
+	Notes []Note
+	Lines []Line
+}
+
+func NewChart(fsys fs.FS, name string, mods Mods) (*Chart, error) {
+	c := &Chart{
+		Mods: mods,
+	}
+
+	format, hash, err := plays.LoadChartFormat(fsys, name)
+	if err != nil {
+		return c, err
+	}
+	header := plays.NewChartHeaderFromFormat(format, hash)
+	c.ChartHeader = header
+
+	dys, err := plays.NewDynamics(format)
+	if err != nil {
+		return c, err
+	}
+	c.Dynamics = dys
+
+	switch format := format.(type) {
+	case *ultrastar.Format:
+		c.Notes, c.Lines = newNotesFromUltraStar(format)
+	}
+	return c, nil
+}
+
+// NoteCounts returns counts of Normal, Golden, Freestyle and Rap notes.
+// Golden Rap notes are counted as Rap notes.
+func (c Chart) NoteCounts() []int {
+	counts := make([]int, 4)
+	for _, n := range c.Notes {
+		switch n.Kind {
+		case Normal:
+			counts[0]++
+		case Golden:
+			counts[1]++
+		case Freestyle:
+			counts[2]++
+		case Rap, RapGolden:
+			counts[3]++
+		}
+	}
+	return counts
+}
+
+func (c Chart) TotalDuration() int32 {
+	if len(c.Notes) == 0 {
+		return 0
+	}
+	n := c.Notes[len(c.Notes)-1]
+	return n.Time + n.Duration
+}
@@ -0,0 +1,58 @@
+package sing
+
+import (
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/input/voice"
+	"github.com/hndada/gosu/plays"
+)
+
+type Components struct {
+	stage  StageComponent
+	notes  NotesComponent
+	voice  VoiceComponent
+	lyrics LyricsComponent
+	combo  plays.ComboComponent
+	score  plays.ScoreComponent
+}
+
+func NewComponents(res *Resources, opts *Options, c *Chart) (cmps Components) {
+	cmps.stage = NewStageComponent(res, opts)
+	cmps.notes = NewNotesComponent(res, opts, c)
+	cmps.voice = NewVoiceComponent(res, opts)
+	cmps.lyrics = NewLyricsComponent(opts, c)
+	cmps.combo = plays.NewComboComponent(res.ComboImages, &opts.Combo)
+	cmps.score = plays.NewScoreComponent(res.ScoreImages, &opts.Score)
+	return
+}
+
+// The line stays until the next line begins, so that
+// a player can read the whole line in advance.
+func (cmps *Components) Update(now int32, vss []voice.State, s Scorer) {
+	li := s.lineFocus
+	if li < len(s.lines) && li > 0 && now < s.lines[li].Time-lineLeadTime {
+		li--
+	}
+	var l Line
+	if li < len(s.lines) {
+		l = s.lines[li]
+	}
+	target, _ := s.stagedNote()
+
+	cmps.notes.Update(now, li)
+	cmps.voice.Update(now, vss, target, l)
+	cmps.lyrics.Update(now, li)
+	cmps.combo.Update(s.Combo)
+	cmps.score.Update(s.Score)
+}
+
+// A line shows up lineLeadTime earlier than its first note.
+const lineLeadTime = 1000
+
+func (cmps Components) Draw(dst draws.Image) {
+	cmps.stage.Draw(dst)
+	cmps.notes.Draw(dst)
+	cmps.voice.Draw(dst)
+	cmps.lyrics.Draw(dst)
+	cmps.combo.Draw(dst)
+	cmps.score.Draw(dst)
+}
@@ -0,0 +1,82 @@
+package sing
+
+import (
+	"strings"
+
+	"github.com/hndada/gosu/draws"
+)
+
+// LyricsComponent draws the current line and the next line.
+// Sung syllables of the current line are drawn in a different color.
+type LyricsComponent struct {
+	notes     []Note
+	lines     []Line
+	opts      *Options
+	face      draws.FaceOptions
+	lineIndex int
+	sung      string
+	unsung    string
+}
+
+func NewLyricsComponent(opts *Options, c *Chart) (cmp LyricsComponent) {
+	cmp.notes = c.Notes
+	cmp.lines = c.Lines
+	cmp.opts = opts
+	cmp.face = draws.NewFaceOptions()
+	cmp.face.Size = opts.LyricsFontSize
+	return
+}
+
+func (cmp *LyricsComponent) Update(now int32, lineIndex int) {
+	cmp.lineIndex = lineIndex
+	cmp.sung, cmp.unsung = "", ""
+	if lineIndex >= len(cmp.lines) {
+		return
+	}
+
+	var sung, unsung strings.Builder
+	l := cmp.lines[lineIndex]
+	for _, n := range cmp.notes[l.First:l.Last] {
+		if n.Time <= now {
+			sung.WriteString(n.Syllable)
+		} else {
+			unsung.WriteString(n.Syllable)
+		}
+	}
+	cmp.sung = strings.TrimLeft(sung.String(), " ")
+	cmp.unsung = unsung.String()
+	if cmp.sung == "" {
+		cmp.unsung = strings.TrimLeft(cmp.unsung, " ")
+	}
+}
+
+func (cmp LyricsComponent) newText(txt string, clr int) draws.Text {
+	t := draws.NewText(txt)
+	t.SetFace(cmp.face)
+	t.Box.Size = t.Size() // Box keeps the size measured with the default face.
+	t.ColorScale.ScaleWithColor(cmp.opts.LyricsColors[clr])
+	return t
+}
+
+// Whole line is centered, then sung and unsung parts are drawn side by side.
+func (cmp LyricsComponent) Draw(dst draws.Image) {
+	const (
+		unsungColor = iota
+		sungColor
+	)
+	y := cmp.opts.LyricsPositionY
+	sung := cmp.newText(cmp.sung, sungColor)
+	unsung := cmp.newText(cmp.unsung, unsungColor)
+	x := (cmp.opts.screenSizeX - sung.W() - unsung.W()) / 2
+	sung.Locate(x, y, draws.LeftMiddle)
+	sung.Draw(dst)
+	unsung.Locate(x+sung.W(), y, draws.LeftMiddle)
+	unsung.Draw(dst)
+
+	if next := cmp.lineIndex + 1; next < len(cmp.lines) {
+		t := cmp.newText(cmp.lines[next].Text, unsungColor)
+		t.ColorScale.ScaleAlpha(0.6)
+		t.Locate(cmp.opts.screenSizeX/2, y+1.5*cmp.opts.LyricsFontSize, draws.CenterMiddle)
+		t.Draw(dst)
+	}
+}
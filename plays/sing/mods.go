@@ -0,0 +1,8 @@
+package sing
+
+type Mods struct {
+}
+
+// Voice is in tune when it is within toneTolerance semitones
+// from the note tone, regardless of octave.
+const toneTolerance = 1.0
@@ -0,0 +1,201 @@
+package sing
+
+import (
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/format/ultrastar"
+)
+
+type NoteKind int
+
+const (
+	Normal    NoteKind = iota
+	Golden             // Worth twice as much as Normal.
+	Freestyle          // Not scored.
+	Rap                // Scored regardless of pitch.
+	RapGolden
+)
+
+func (k NoteKind) isGolden() bool { return k == Golden || k == RapGolden }
+func (k NoteKind) isRap() bool    { return k == Rap || k == RapGolden }
+
+type Note struct {
+	Time     int32
+	Duration int32
+	Kind     NoteKind
+	Tone     int // MIDI note number.
+	Syllable string
+	Line     int // Index of the line which the note belongs to.
+
+	frames    int // The number of voice states during the note.
+	hitFrames int // The number of voice states in tune.
+	scored    bool
+}
+
+// Line has notes in the range of [First, Last).
+// Tone is the center tone of the line, which is used for drawing.
+type Line struct {
+	Time    int32
+	EndTime int32
+	First   int
+	Last    int
+	Text    string
+	Tone    float64
+}
+
+// Notes out of the range from START to END are not sung,
+// since the song is played only in the range.
+func newNotesFromUltraStar(f *ultrastar.Format) (notes []Note, lines []Line) {
+	inRange := func(fn ultrastar.Note) bool {
+		if f.Time(fn.Beat) < f.Start*1000 {
+			return false
+		}
+		return f.End <= 0 || f.Time(fn.Beat+fn.Length) <= f.End
+	}
+	for _, fl := range f.Lines {
+		var fns []ultrastar.Note
+		for _, fn := range fl.Notes {
+			if inRange(fn) {
+				fns = append(fns, fn)
+			}
+		}
+		if len(fns) == 0 {
+			continue
+		}
+		l := Line{
+			Time:    int32(f.Time(fns[0].Beat)),
+			EndTime: int32(f.Time(fl.EndBeat)),
+			First:   len(notes),
+			Text:    ultrastar.Line{Notes: fns}.Text(),
+		}
+
+		var toneSum, toneCount int
+		for _, fn := range fns {
+			n := Note{
+				Time:     int32(f.Time(fn.Beat)),
+				Duration: int32(f.Time(fn.Beat+fn.Length)) - int32(f.Time(fn.Beat)),
+				Kind:     newNoteKind(fn.Type),
+				Tone:     fn.Pitch + ultrastar.MIDIOffset,
+				Syllable: fn.Syllable,
+				Line:     len(lines),
+			}
+			if n.Kind != Freestyle && !n.Kind.isRap() {
+				toneSum += n.Tone
+				toneCount++
+			}
+			notes = append(notes, n)
+		}
+		l.Last = len(notes)
+		if toneCount > 0 {
+			l.Tone = float64(toneSum) / float64(toneCount)
+		} else if len(lines) > 0 {
+			l.Tone = lines[len(lines)-1].Tone
+		} else {
+			l.Tone = ultrastar.MIDIOffset
+		}
+		if l.EndTime < l.Time {
+			last := notes[len(notes)-1]
+			l.EndTime = last.Time + last.Duration
+		}
+		lines = append(lines, l)
+	}
+	return
+}
+
+func newNoteKind(t ultrastar.NoteType) NoteKind {
+	switch t {
+	case ultrastar.NoteGolden:
+		return Golden
+	case ultrastar.NoteFreestyle:
+		return Freestyle
+	case ultrastar.NoteRap:
+		return Rap
+	case ultrastar.NoteRapGolden:
+		return RapGolden
+	}
+	return Normal
+}
+
+// Weight is proportional to the duration of the note,
+// since a longer note takes more voice states.
+func (n Note) Weight() float64 {
+	w := float64(n.Duration) / 1000
+	switch {
+	case n.Kind == Freestyle:
+		return 0
+	case n.Kind.isGolden():
+		return 2 * w
+	}
+	return w
+}
+
+// hitRatio returns the ratio of voice states in tune.
+func (n Note) hitRatio() float64 {
+	if n.frames == 0 {
+		return 0
+	}
+	return float64(n.hitFrames) / float64(n.frames)
+}
+
+// NotesComponent draws pitch bars of the current and the next line.
+// Bars flow from right to left, and the voice is drawn at the hit position.
+type NotesComponent struct {
+	notes       []Note
+	lines       []Line
+	kindsSprite [4]draws.Sprite // Normal, Golden, Freestyle, Rap
+	opts        *Options
+	now         int32
+	lineIndex   int
+}
+
+func NewNotesComponent(res *Resources, opts *Options, c *Chart) (cmp NotesComponent) {
+	cmp.notes = c.Notes
+	cmp.lines = c.Lines
+	for kind, clr := range opts.NoteColors {
+		s := draws.NewSprite(res.NoteImage)
+		s.SetSize(1, opts.noteHeight)
+		s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.LeftMiddle)
+		s.ColorScale.ScaleWithColor(clr)
+		cmp.kindsSprite[kind] = s
+	}
+	cmp.opts = opts
+	return
+}
+
+func (cmp *NotesComponent) Update(now int32, lineIndex int) {
+	cmp.now = now
+	cmp.lineIndex = lineIndex
+}
+
+// Sung notes are drawn fainter when they are sung poorly.
+func (cmp NotesComponent) Draw(dst draws.Image) {
+	last := min(cmp.lineIndex+2, len(cmp.lines))
+	for _, l := range cmp.lines[min(cmp.lineIndex, last):last] {
+		for _, n := range cmp.notes[l.First:l.Last] {
+			x := cmp.opts.x(n.Time - cmp.now)
+			w := cmp.opts.x(n.Time+n.Duration-cmp.now) - x
+			if !cmp.opts.isVisible(x, w) {
+				continue
+			}
+
+			s := cmp.kindsSprite[n.Kind.colorIndex()]
+			s.SetSize(w, cmp.opts.noteHeight)
+			s.Move(x, cmp.opts.toneY(float64(n.Tone), l.Tone))
+			if n.scored {
+				s.ColorScale.ScaleAlpha(0.3 + 0.7*float32(n.hitRatio()))
+			}
+			s.Draw(dst)
+		}
+	}
+}
+
+func (k NoteKind) colorIndex() int {
+	switch {
+	case k == Freestyle:
+		return 2
+	case k.isRap():
+		return 3
+	case k.isGolden():
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package sing
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// Sing stage is horizontal: pitch bars flow from right to left.
+// Higher tone is drawn upper.
+type Options struct {
+	screenSizeX float64
+	screenSizeY float64
+	SpeedScale  float64
+
+	FieldOpacity   float32
+	FieldPositionY float64
+	FieldHeight    float64
+	HitPositionX   float64
+	ToneRange      int // The number of semitones the field covers.
+
+	noteHeight float64 // derived
+
+	NoteColors      [4]color.NRGBA // Normal, Golden, Freestyle, Rap
+	VoiceColor      color.NRGBA
+	VoiceSize       float64
+	LyricsFontSize  float64
+	LyricsPositionY float64
+	LyricsColors    [2]color.NRGBA // Unsung, Sung
+	Combo           plays.ComboOptions
+	Score           plays.ScoreOptions
+}
+
+// baseSpeed is in logical pixel per millisecond.
+const baseSpeed = 0.3
+
+func NewOptions() *Options {
+	opts := &Options{
+		SpeedScale: 1.0,
+
+		FieldOpacity:   0.5,
+		FieldPositionY: 0.4 * plays.ScreenSizeY,
+		FieldHeight:    0.5 * plays.ScreenSizeY,
+		HitPositionX:   0.25 * plays.ScreenSizeX,
+		ToneRange:      16,
+
+		NoteColors: [4]color.NRGBA{
+			{80, 160, 255, 255},  // Normal
+			{255, 200, 40, 255},  // Golden
+			{160, 160, 160, 160}, // Freestyle
+			{120, 220, 120, 255}, // Rap
+		},
+		VoiceColor:      color.NRGBA{255, 255, 255, 255},
+		VoiceSize:       16,
+		LyricsFontSize:  32,
+		LyricsPositionY: 0.8 * plays.ScreenSizeY,
+		LyricsColors: [2]color.NRGBA{
+			{255, 255, 255, 255}, // Unsung
+			{80, 160, 255, 255},  // Sung
+		},
+		Combo: plays.ComboOptions{
+			ImageScale: 0.75,
+			PositionX:  0.1 * plays.ScreenSizeX,
+			DigitGap:   -1,
+			PositionY:  0.4 * plays.ScreenSizeY,
+			IsPersist:  true,
+			Bounce:     0.08,
+		},
+		Score: plays.ScoreOptions{
+			ImageScale: 0.65,
+			DigitGap:   0,
+		},
+	}
+
+	opts.SetDerived()
+	return opts
+}
+
+func (opts *Options) SetDerived() {
+	opts.screenSizeX = plays.ScreenSizeX
+	opts.screenSizeY = plays.ScreenSizeY
+	opts.noteHeight = opts.FieldHeight / float64(opts.ToneRange)
+}
+
+// x returns x position relative to the hit position.
+func (opts Options) x(timeDiff int32) float64 {
+	return float64(timeDiff) * baseSpeed * opts.SpeedScale
+}
+
+func (opts Options) isVisible(x, w float64) bool {
+	left := opts.HitPositionX + x
+	return left+w >= 0 && left <= opts.screenSizeX
+}
+
+// toneY returns y position relative to the field center.
+// Tones out of the field are folded by octave.
+func (opts Options) toneY(tone, center float64) float64 {
+	half := float64(opts.ToneRange) / 2
+	d := tone - center
+	for d > half {
+		d -= 12
+	}
+	for d < -half {
+		d += 12
+	}
+	return -d * opts.noteHeight
+}
+
+// nearestOctave moves voice tone to the octave nearest to the target,
+// since a singer may sing a song in a different octave.
+func nearestOctave(tone, target float64) float64 {
+	return tone - 12*math.Round((tone-target)/12)
+}
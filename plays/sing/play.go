@@ -0,0 +1,72 @@
+package sing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/input/voice"
+	"github.com/hndada/gosu/plays"
+)
+
+// Struct Play goes a part of ScenePlay.
+// Sing is played with voice, not with keyboard.
+type Play struct {
+	*Resources
+	*Options
+	*Chart
+	Scorer
+	Components
+	voice voice.Reader
+}
+
+func NewPlay(res *Resources, opts *Options, c *Chart, mods Mods, vr voice.Reader) (*Play, error) {
+	if len(c.Notes) == 0 {
+		return nil, fmt.Errorf("no notes in the chart")
+	}
+	return &Play{
+		Resources:  res,
+		Options:    opts,
+		Chart:      c,
+		Scorer:     NewScorer(c, mods),
+		Components: NewComponents(res, opts, c),
+		voice:      vr,
+	}, nil
+}
+
+// Play is finished when a while has passed after the last note.
+const finishWait = 2000
+
+// Update returns Scorer when the play is finished.
+// Keyboard actions are ignored.
+func (p *Play) Update(now int32, _ []plays.KeyboardAction) any {
+	vss := p.voice.Read(time.Duration(now) * time.Millisecond)
+	for _, vs := range vss {
+		p.Scorer.update(vs)
+	}
+	p.Scorer.flush(now)
+	p.Dynamics.UpdateIndex(now)
+	p.Components.Update(now+p.Dynamics.VisualOffset, vss, p.Scorer)
+	if now > p.TotalDuration()+finishWait {
+		return p.Scorer
+	}
+	return nil
+}
+
+func (p *Play) SetSpeedScale(newScale float64) {
+	p.SpeedScale = newScale
+}
+
+func (p Play) Draw(dst draws.Image) {
+	p.Components.Draw(dst)
+}
+
+func (p Play) DebugString() string {
+	var b strings.Builder
+	f := fmt.Fprintf
+
+	f(&b, p.Scorer.DebugString())
+	f(&b, "Speed scale (PageUp/Down): x%.2f\n", p.SpeedScale)
+	return b.String()
+}
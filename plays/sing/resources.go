@@ -0,0 +1,41 @@
+package sing
+
+import (
+	"fmt"
+	"image/color"
+	"io/fs"
+
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/plays"
+)
+
+type Resources struct {
+	FieldImage  draws.Image
+	HintImage   draws.Image
+	NoteImage   draws.Image
+	VoiceImage  draws.Image
+	ComboImages []draws.Image
+	ScoreImages []draws.Image
+}
+
+// loadImage generates a plain image when there is no image file in the skin.
+func loadImage(fsys fs.FS, name string, clr color.Color) draws.Image {
+	fname := fmt.Sprintf("sing/%s.png", name)
+	img := draws.NewImageFromFile(fsys, fname)
+	if img.IsEmpty() {
+		img = draws.CreateImage(1, 1)
+		img.Fill(clr)
+	}
+	return img
+}
+
+func NewResources(fsys fs.FS) *Resources {
+	return &Resources{
+		FieldImage:  loadImage(fsys, "field", color.Black),
+		HintImage:   loadImage(fsys, "hint", color.White),
+		NoteImage:   loadImage(fsys, "note", color.White),
+		VoiceImage:  loadImage(fsys, "voice", color.White),
+		ComboImages: plays.LoadComboImages(fsys),
+		ScoreImages: plays.LoadScoreImages(fsys),
+	}
+}
@@ -0,0 +1,182 @@
+package sing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hndada/gosu/input/voice"
+)
+
+// There are two kinds of factors: Tune and Bonus.
+// Tune is proportional to the ratio of voice states in tune.
+// Bonus is given at the end of each line by the line's hit ratio.
+const (
+	tune = iota
+	bonus
+)
+
+// A note is counted in combo when its hit ratio is over comboThreshold.
+const comboThreshold = 0.5
+
+type Scorer struct {
+	notes []Note
+	lines []Line
+
+	noteFocus int
+	lineFocus int
+
+	// Following fields are for components.
+	voice      voice.State
+	lineRatios []float64
+
+	Combo      int
+	weightSum  float64
+	hitSum     float64
+	units      [2]float64
+	lineWeight []float64
+	Score      float64
+}
+
+func NewScorer(c *Chart, mods Mods) (s Scorer) {
+	s.notes = c.Notes
+	s.lines = c.Lines
+	s.lineRatios = make([]float64, len(c.Lines))
+	s.lineWeight = make([]float64, len(c.Lines))
+
+	var total float64
+	var lineCount int
+	for _, n := range s.notes {
+		w := n.Weight()
+		total += w
+		s.lineWeight[n.Line] += w
+	}
+	for _, w := range s.lineWeight {
+		if w > 0 {
+			lineCount++
+		}
+	}
+	if total > 0 {
+		s.units[tune] = 0.9 * 1e6 / total
+	}
+	if lineCount > 0 {
+		s.units[bonus] = 0.1 * 1e6 / float64(lineCount)
+	}
+
+	// Accumulating floating-point numbers may result in imprecise values.
+	s.Score = 0.01
+	return
+}
+
+func (s *Scorer) update(vs voice.State) {
+	now := int32(vs.Time.Milliseconds())
+	s.voice = vs
+	s.flush(now)
+	if s.noteFocus >= len(s.notes) {
+		return
+	}
+
+	n := &s.notes[s.noteFocus]
+	if now < n.Time {
+		return
+	}
+	n.frames++
+	if s.isInTune(*n, vs) {
+		n.hitFrames++
+	}
+}
+
+func (s Scorer) isInTune(n Note, vs voice.State) bool {
+	if !vs.Voiced {
+		return false
+	}
+	if n.Kind.isRap() {
+		return true
+	}
+	return toneDistance(vs.Tone, float64(n.Tone)) <= toneTolerance
+}
+
+// toneDistance returns the distance of two tones regardless of octave.
+func toneDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 12)
+	return min(d, 12-d)
+}
+
+// flush marks notes and lines which have ended.
+func (s *Scorer) flush(now int32) {
+	for s.noteFocus < len(s.notes) {
+		n := s.notes[s.noteFocus]
+		if now < n.Time+n.Duration {
+			break
+		}
+		s.markNote()
+	}
+	for s.lineFocus < len(s.lines) {
+		l := s.lines[s.lineFocus]
+		if s.noteFocus < l.Last {
+			break
+		}
+		s.markLine()
+	}
+}
+
+func (s *Scorer) markNote() {
+	n := &s.notes[s.noteFocus]
+	ratio := n.hitRatio()
+	w := n.Weight()
+	if w > 0 {
+		if ratio >= comboThreshold {
+			s.Combo++
+		} else {
+			s.Combo = 0
+		}
+	}
+	s.weightSum += w
+	s.hitSum += w * ratio
+	s.Score += s.units[tune] * w * ratio
+	s.lineRatios[n.Line] += w * ratio
+
+	n.scored = true
+	s.noteFocus++
+}
+
+func (s *Scorer) markLine() {
+	li := s.lineFocus
+	if w := s.lineWeight[li]; w > 0 {
+		s.lineRatios[li] /= w
+		s.Score += s.units[bonus] * s.lineRatios[li]
+	}
+	s.lineFocus++
+}
+
+// Accuracy returns the weighted ratio of voice states in tune.
+func (s Scorer) Accuracy() float64 {
+	if s.weightSum == 0 {
+		return 0
+	}
+	return s.hitSum / s.weightSum
+}
+
+// stagedNote returns the note which is being sung or to be sung.
+func (s Scorer) stagedNote() (Note, bool) {
+	if s.noteFocus >= len(s.notes) {
+		return Note{}, false
+	}
+	return s.notes[s.noteFocus], true
+}
+
+func (s Scorer) DebugString() string {
+	var b strings.Builder
+	f := fmt.Fprintf
+
+	f(&b, "Score: %.0f \n", s.Score)
+	f(&b, "Combo: %d\n", s.Combo)
+	f(&b, "Accuracy: %.2f%%\n", s.Accuracy()*100)
+	if s.voice.Voiced {
+		f(&b, "Voice tone: %.1f\n", s.voice.Tone)
+	} else {
+		f(&b, "Voice tone: -\n")
+	}
+	f(&b, "\n")
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package sing
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hndada/gosu/plays"
+)
+
+// A leap between two notes requires more strain.
+// The strain grows with the interval in semitones.
+const leapStrain = 0.05
+
+// Difficulties returns strain of each section.
+// Strain comes from syllables and leaps between tones.
+func (c Chart) Difficulties() []float64 {
+	ds := make([]float64, plays.SectionCount(c.TotalDuration()))
+	index := func(t int32) int { return min(plays.SectionIndex(t), len(ds)-1) }
+
+	prev := -1
+	for i, n := range c.Notes {
+		if n.Kind == Freestyle {
+			continue
+		}
+		strain := 1.0
+		if prev >= 0 && !n.Kind.isRap() && c.Notes[prev].Line == n.Line {
+			interval := math.Abs(float64(n.Tone - c.Notes[prev].Tone))
+			strain += leapStrain * interval
+		}
+		if n.Kind.isGolden() {
+			strain *= 1.2
+		}
+		ds[index(n.Time)] += strain
+		if !n.Kind.isRap() {
+			prev = i
+		}
+	}
+	return ds
+}
+
+// syllableTimes returns times of scored notes.
+func (c Chart) syllableTimes() []int32 {
+	ts := make([]int32, 0, len(c.Notes))
+	for _, n := range c.Notes {
+		if n.Kind == Freestyle {
+			continue
+		}
+		ts = append(ts, n.Time)
+	}
+	return ts
+}
+
+// Densities returns syllables per second of each section.
+func (c Chart) Densities() []float64 {
+	return plays.Densities(c.syllableTimes(), c.TotalDuration())
+}
+
+func (c Chart) PeakNPS() float64 { return plays.Peak(c.Densities()) }
+
+func (c Chart) DrainTime() int32 {
+	ts := make([]int32, 0, 2*len(c.Notes))
+	for _, n := range c.Notes {
+		ts = append(ts, n.Time, n.Time+n.Duration)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return plays.DrainTime(ts)
+}
+
+func (c Chart) Level() float64 { return plays.Level(c.Difficulties()) }
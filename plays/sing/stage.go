@@ -0,0 +1,32 @@
+package sing
+
+import "github.com/hndada/gosu/draws"
+
+// StageComponent draws field and hint.
+// Hint is a vertical line at the hit position.
+type StageComponent struct {
+	fieldSprite draws.Sprite
+	hintSprite  draws.Sprite
+}
+
+func NewStageComponent(res *Resources, opts *Options) (cmp StageComponent) {
+	{
+		s := draws.NewSprite(res.FieldImage)
+		s.SetSize(opts.screenSizeX, opts.FieldHeight)
+		s.Locate(0, opts.FieldPositionY, draws.LeftMiddle)
+		s.ColorScale.Scale(1, 1, 1, opts.FieldOpacity)
+		cmp.fieldSprite = s
+	}
+	{
+		s := draws.NewSprite(res.HintImage)
+		s.SetSize(2, opts.FieldHeight)
+		s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+		cmp.hintSprite = s
+	}
+	return
+}
+
+func (cmp StageComponent) Draw(dst draws.Image) {
+	cmp.fieldSprite.Draw(dst)
+	cmp.hintSprite.Draw(dst)
+}
@@ -0,0 +1,69 @@
+package sing
+
+import (
+	"github.com/hndada/gosu/draws"
+	"github.com/hndada/gosu/input/voice"
+)
+
+// Voice trail lasts for trailDuration, flowing left along with notes.
+const trailDuration = 1000
+
+type voicePoint struct {
+	time int32
+	y    float64
+}
+
+// VoiceComponent draws the voice at the hit position,
+// as well as the trail of the recent voice.
+type VoiceComponent struct {
+	sprite draws.Sprite
+	opts   *Options
+	now    int32
+	points []voicePoint
+}
+
+func NewVoiceComponent(res *Resources, opts *Options) (cmp VoiceComponent) {
+	s := draws.NewSprite(res.VoiceImage)
+	s.SetSize(opts.VoiceSize, opts.VoiceSize)
+	s.Locate(opts.HitPositionX, opts.FieldPositionY, draws.CenterMiddle)
+	s.ColorScale.ScaleWithColor(opts.VoiceColor)
+	cmp.sprite = s
+	cmp.opts = opts
+	return
+}
+
+// Voice tone is drawn at the octave nearest to the staged note.
+func (cmp *VoiceComponent) Update(now int32, vss []voice.State, target Note, l Line) {
+	cmp.now = now
+	for _, vs := range vss {
+		if !vs.Voiced {
+			continue
+		}
+		tone := nearestOctave(vs.Tone, float64(target.Tone))
+		cmp.points = append(cmp.points, voicePoint{
+			time: int32(vs.Time.Milliseconds()),
+			y:    cmp.opts.toneY(tone, l.Tone),
+		})
+	}
+
+	var i int
+	for i < len(cmp.points) && now-cmp.points[i].time > trailDuration {
+		i++
+	}
+	cmp.points = cmp.points[i:]
+}
+
+// The latest point is drawn in full size, and older ones get smaller.
+func (cmp VoiceComponent) Draw(dst draws.Image) {
+	for _, p := range cmp.points {
+		age := float64(cmp.now-p.time) / trailDuration
+		if age < 0 {
+			age = 0
+		}
+		s := cmp.sprite
+		s.Scale(1 - 0.7*age)
+		s.Move(cmp.opts.x(p.time-cmp.now), p.y)
+		s.ColorScale.ScaleAlpha(float32(1 - age))
+		s.Draw(dst)
+	}
+}
@@ -17,7 +17,9 @@ func (h *KeyNumberHandler[T]) Handle() {
 		return
 	}
 
-	h.SoundPlayer.Play(ctrl.SoundFilename)
+	if h.SoundPlayer != nil {
+		h.SoundPlayer.Play(ctrl.SoundFilename)
+	}
 	switch ctrl.Type {
 	case Decrease:
 		h.Decrease()
@@ -39,7 +41,9 @@ func (h *KeyBoolHandler) Handle() {
 		return
 	}
 
-	h.SoundPlayer.Play(ctrl.SoundFilename)
+	if h.SoundPlayer != nil {
+		h.SoundPlayer.Play(ctrl.SoundFilename)
+	}
 	h.Toggle()
 }
 
@@ -44,10 +44,6 @@ func (ml *MouseListener) updateCursor() {
 	cp := draws.NewXY(input.MouseCursorPosition())
 	ml.lastCursorIn = ml.cursorIn
 	ml.cursorIn = ml.box.In(cp)
-	if ml.cursorIn {
-		wp := draws.NewXY(input.MouseWheelPosition())
-		ml.lastWheelPosition = wp
-	}
 }
 
 func (ml *MouseListener) updateButtons() {
@@ -64,13 +60,15 @@ func (ml *MouseListener) updateButtons() {
 }
 
 // Todo: tweening
+// Wheel position is accumulated, since the wheel reports
+// only the movement in the current tick.
 func (ml *MouseListener) updateWheel() {
+	ml.lastWheelPosition = ml.wheelPosition
 	if !ml.IsCursorEntered() {
 		return
 	}
-	ml.lastWheelPosition = ml.wheelPosition
 	wp := draws.NewXY(input.MouseWheelPosition())
-	ml.wheelPosition = wp
+	ml.wheelPosition = ml.wheelPosition.Add(wp)
 
 	// dx, dy := input.MouseWheelPosition()
 	// ml.box.AddPixelToX(dx * ml.scrollScale)